@@ -0,0 +1,130 @@
+package campaign
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format of an audience file
+type Format int
+
+const (
+	// CSV audience has a header row of column names
+	CSV Format = iota
+
+	// JSONL audience has a JSON object per line, values of any type are formatted as strings
+	JSONL
+)
+
+// DetectFormat returns the Format by the extension of path, ".jsonl" and ".ndjson" are JSONL, others are CSV
+func DetectFormat(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return JSONL
+	}
+	return CSV
+}
+
+// Row of an audience
+type Row struct {
+	// Index of the row starting from 1, the header of CSV is not counted
+	Index int
+
+	Fields map[string]string
+}
+
+// audienceReader reads rows of an audience, io.EOF is returned after the last row
+type audienceReader interface {
+	Next() (Row, error)
+}
+
+func newAudienceReader(r io.Reader, format Format) audienceReader {
+	if format == JSONL {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &jsonlReader{scanner: scanner}
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvReader{r: cr}
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+	index  int
+}
+
+func (r *csvReader) Next() (Row, error) {
+	if r.header == nil {
+		header, err := r.r.Read()
+		if err == io.EOF {
+			return Row{}, io.EOF
+		}
+		if err != nil {
+			return Row{}, err
+		}
+		for i := range header {
+			header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+		}
+		r.header = header
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		return Row{}, err
+	}
+	r.index++
+	if len(record) > len(r.header) {
+		return Row{}, fmt.Errorf("campaign: row %d has %d fields, more than the header", r.index, len(record))
+	}
+	row := Row{Index: r.index, Fields: make(map[string]string, len(record))}
+	for i, v := range record {
+		row.Fields[r.header[i]] = strings.TrimSpace(v)
+	}
+	return row, nil
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	index   int
+}
+
+func (r *jsonlReader) Next() (Row, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		r.index++
+
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err != nil {
+			return Row{}, fmt.Errorf("campaign: row %d: %v", r.index, err)
+		}
+		row := Row{Index: r.index, Fields: make(map[string]string, len(obj))}
+		for k, v := range obj {
+			switch v := v.(type) {
+			case nil:
+			case string:
+				row.Fields[k] = strings.TrimSpace(v)
+			case json.Number, bool:
+				row.Fields[k] = fmt.Sprint(v)
+			default:
+				return Row{}, fmt.Errorf("campaign: row %d: %s is not a string, number or bool", r.index, k)
+			}
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
@@ -0,0 +1,60 @@
+package campaign
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, r audienceReader) []Row {
+	var rows []Row
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	rows := readAll(t, newAudienceReader(strings.NewReader("\ufeffphone, name\n15300000001,Alice\n15300000002, Bob \n"), CSV))
+	want := []Row{
+		{Index: 1, Fields: map[string]string{"phone": "15300000001", "name": "Alice"}},
+		{Index: 2, Fields: map[string]string{"phone": "15300000002", "name": "Bob"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows: %+v", rows)
+	}
+
+	if _, err := newAudienceReader(strings.NewReader("phone\n15300000001,Alice\n"), CSV).Next(); err == nil {
+		t.Error("Next: more fields than the header")
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	rows := readAll(t, newAudienceReader(strings.NewReader(`{"phone":"15300000001","amount":12.50,"vip":true,"note":null}`+"\n\n"+`{"phone":15300000002}`), JSONL))
+	want := []Row{
+		{Index: 1, Fields: map[string]string{"phone": "15300000001", "amount": "12.50", "vip": "true"}},
+		{Index: 2, Fields: map[string]string{"phone": "15300000002"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows: %+v", rows)
+	}
+
+	if _, err := newAudienceReader(strings.NewReader(`{"phone":["15300000001"]}`), JSONL).Next(); err == nil {
+		t.Error("Next: array value")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for path, format := range map[string]Format{"a.csv": CSV, "a.JSONL": JSONL, "a.ndjson": JSONL, "a": CSV} {
+		if f := DetectFormat(path); f != format {
+			t.Errorf("DetectFormat(%s): %d != %d", path, f, format)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+// Package campaign sends a template to an audience file, e.g. a promotion to a CSV of customers
+//
+// results of rows are appended to a results file, which is the checkpoint of the campaign,
+// a crashed campaign is resumed by running it again with the same results file,
+// rows with results are skipped, so no row is sent twice
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// DefaultPhoneColumn is default column of phone numbers
+const DefaultPhoneColumn = "phone"
+
+// Config of Campaign
+type Config struct {
+	Client sms.Client
+
+	// Options are applied to every action "SendSms"
+	Options []sms.Option
+
+	SignName     string
+	TemplateCode string
+
+	// Format of the audience
+	Format Format
+
+	// PhoneColumn is the column of phone numbers, default DefaultPhoneColumn
+	PhoneColumn string
+
+	// OutIDColumn is the column of OutId, optional, rows with OutId are sent one by one
+	OutIDColumn string
+
+	// Params maps variables of the template to columns, e.g. {"name": "first_name"},
+	// all other columns are variables of the same names if it's nil
+	Params map[string]string
+
+	// BatchSize is upper limit of phone numbers in one action "SendSms",
+	// consecutive rows of the same TemplateParam are sent together,
+	// default and upper limit is sms.MaxPhoneNumbersPerSend
+	BatchSize int
+
+	// Rate is upper limit of actions "SendSms" per second, unlimited if it's 0
+	Rate float64
+
+	// DryRun validates rows as they are sent, including suppressions, but nothing is sent,
+	// the results file is not written
+	DryRun bool
+
+	// Results is the path of the results file, required unless DryRun
+	Results string
+
+	// OnResult is called with every result in order, optional
+	OnResult func(r Result)
+}
+
+// Summary of a run of a campaign
+type Summary struct {
+	// Rows is number of rows of the audience
+	Rows int
+
+	// Resumed is number of rows skipped since they have results of previous runs
+	Resumed int
+
+	// Unverified is number of rows left pending by previous runs, or by errs of requests
+	// of this run leaving it unknown whether they are sent, they may have been sent
+	Unverified int
+
+	// States counts rows by their results of this run
+	States map[State]int
+
+	// Codes counts Code of responses of this run
+	Codes map[string]int
+
+	// Requests is number of actions "SendSms"
+	Requests int
+
+	Started  time.Time
+	Finished time.Time
+}
+
+// WriteReport writes a human readable report of s
+func (s *Summary) WriteReport(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "rows: %d, resumed: %d, unverified: %d, requests: %d, duration: %s\n",
+		s.Rows, s.Resumed, s.Unverified, s.Requests, s.Finished.Sub(s.Started).Round(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	for _, state := range []State{Sent, Failed, Invalid, Suppressed, DryRun} {
+		if n := s.States[state]; n > 0 {
+			if _, err := fmt.Fprintf(w, "%s: %d\n", state, n); err != nil {
+				return err
+			}
+		}
+	}
+	codes := make([]string, 0, len(s.Codes))
+	for code := range s.Codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if _, err := fmt.Fprintf(w, "code %s: %d\n", code, s.Codes[code]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Campaign sends a template to an audience
+type Campaign struct {
+	conf Config
+}
+
+// New init a Campaign
+func New(conf Config) *Campaign {
+	if conf.PhoneColumn == "" {
+		conf.PhoneColumn = DefaultPhoneColumn
+	}
+	if conf.BatchSize <= 0 || conf.BatchSize > sms.MaxPhoneNumbersPerSend {
+		conf.BatchSize = sms.MaxPhoneNumbersPerSend
+	}
+	return &Campaign{conf: conf}
+}
+
+// batch is rows sent in one action "SendSms"
+type batch struct {
+	rows    []Row
+	numbers []phone.Number
+	params  sms.SendSmsParams
+	key     string
+}
+
+// Run the campaign of audience until all rows are sent or ctx is done,
+// rows with results in the results file are skipped
+// errs of the audience, the results file and ctx are returned, errs of rows are in results
+func (c *Campaign) Run(ctx context.Context, audience io.Reader) (*Summary, error) {
+	s := &Summary{States: make(map[State]int), Codes: make(map[string]int), Started: time.Now()}
+	defer func() {
+		s.Finished = time.Now()
+	}()
+
+	r := &run{c: c, s: s, ctx: ctx}
+	if !c.conf.DryRun {
+		if c.conf.Results == "" {
+			return s, errors.New("campaign: Results is required")
+		}
+		f, done, err := openResults(c.conf.Results)
+		if err != nil {
+			return s, err
+		}
+		defer f.Close()
+		r.results, r.done = f, done
+	}
+
+	rows := newAudienceReader(audience, c.conf.Format)
+	var b *batch
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return s, err
+		}
+		s.Rows++
+
+		if prev, ok := r.done[row.Index]; ok {
+			s.Resumed++
+			if prev.State == Pending {
+				s.Unverified++
+			}
+			continue
+		}
+
+		params, n, res := c.params(row)
+		if res != nil {
+			if err := r.record(*res); err != nil {
+				return s, err
+			}
+			continue
+		}
+		key := params.TemplateParam.String()
+		if b != nil && (params.OutID != "" || b.key != key || len(b.numbers) >= c.conf.BatchSize) {
+			if err := r.send(b); err != nil {
+				return s, err
+			}
+			b = nil
+		}
+		if b == nil {
+			b = &batch{params: params, key: key}
+		}
+		b.rows = append(b.rows, row)
+		b.numbers = append(b.numbers, n)
+		if params.OutID != "" {
+			if err := r.send(b); err != nil {
+				return s, err
+			}
+			b = nil
+		}
+	}
+	if b != nil {
+		if err := r.send(b); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// params of row, or the Result if the row is invalid
+func (c *Campaign) params(row Row) (sms.SendSmsParams, phone.Number, *Result) {
+	raw := row.Fields[c.conf.PhoneColumn]
+	invalid := func(msg string) *Result {
+		return &Result{Row: row.Index, Phone: raw, State: Invalid, Message: msg, At: time.Now()}
+	}
+	if raw == "" {
+		return sms.SendSmsParams{}, phone.Number{}, invalid("phone number is empty")
+	}
+	n, err := phone.Parse(raw)
+	if err != nil {
+		return sms.SendSmsParams{}, phone.Number{}, invalid(err.Error())
+	}
+
+	tp := sms.TemplateParam{}
+	if c.conf.Params != nil {
+		for name, column := range c.conf.Params {
+			v, ok := row.Fields[column]
+			if !ok {
+				return sms.SendSmsParams{}, phone.Number{}, invalid("column " + column + " is missing")
+			}
+			tp[name] = v
+		}
+	} else {
+		for k, v := range row.Fields {
+			if k != c.conf.PhoneColumn && k != c.conf.OutIDColumn {
+				tp[k] = v
+			}
+		}
+	}
+	if len(tp) == 0 {
+		tp = nil
+	}
+
+	params := sms.SendSmsParams{SignName: c.conf.SignName, TemplateCode: c.conf.TemplateCode, TemplateParam: tp}
+	if c.conf.OutIDColumn != "" {
+		params.OutID = row.Fields[c.conf.OutIDColumn]
+	}
+	return params, n, nil
+}
+
+// run is the state of a Run
+type run struct {
+	c   *Campaign
+	s   *Summary
+	ctx context.Context
+
+	results *resultsFile
+	done    map[int]Result
+
+	// next is the earliest time of the next request by Rate
+	next time.Time
+}
+
+// record a result of a row
+func (r *run) record(results ...Result) error {
+	if r.results != nil {
+		if err := r.results.append(results...); err != nil {
+			return err
+		}
+	}
+	for _, res := range results {
+		if res.State != Pending {
+			r.s.States[res.State]++
+			if res.Code != "" {
+				r.s.Codes[res.Code]++
+			}
+			if r.c.conf.OnResult != nil {
+				r.c.conf.OnResult(res)
+			}
+		}
+	}
+	return nil
+}
+
+// wait for the next request by Rate
+func (r *run) wait() error {
+	if r.c.conf.Rate <= 0 {
+		return r.ctx.Err()
+	}
+	now := time.Now()
+	if d := r.next.Sub(now); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		case <-t.C:
+		}
+		now = r.next
+	}
+	r.next = now.Add(time.Duration(float64(time.Second) / r.c.conf.Rate))
+	return r.ctx.Err()
+}
+
+func (r *run) send(b *batch) error {
+	params := b.params
+	params.Recipients = b.numbers
+
+	now := time.Now()
+	results := make([]Result, len(b.rows))
+	for i, row := range b.rows {
+		results[i] = Result{Row: row.Index, Phone: b.numbers[i].String(), At: now}
+	}
+
+	a := sms.NewSendAction(r.c.conf.Client, params)
+	if r.c.conf.DryRun {
+		_, err := a.Presign(r.c.conf.Options...)
+		return r.record(r.complete(results, nil, err)...)
+	}
+
+	if err := r.wait(); err != nil {
+		return err
+	}
+	pending := make([]Result, len(results))
+	for i, res := range results {
+		res.State = Pending
+		pending[i] = res
+	}
+	if err := r.record(pending...); err != nil {
+		return err
+	}
+
+	r.s.Requests++
+	extOpts := append(append([]sms.Option{}, r.c.conf.Options...), sms.ContextOption(r.ctx))
+	opts, err := a.Do(extOpts...)
+	results = r.complete(results, opts, err)
+	for _, res := range results {
+		if res.State == Pending {
+			r.s.Unverified++
+		}
+	}
+	return r.record(results...)
+}
+
+// complete results by the result of the action,
+// rows are left pending if it's unknown whether they are sent
+func (r *run) complete(results []Result, opts sms.SendSmsOptions, err error) []Result {
+	now := time.Now()
+	suppressed := make(map[string]bool)
+	if opts != nil {
+		for _, n := range opts.Suppressed() {
+			suppressed[n.String()] = true
+		}
+	}
+	if e, ok := err.(*sms.SuppressedError); ok {
+		for _, n := range e.Numbers {
+			suppressed[n.String()] = true
+		}
+	}
+
+	for i := range results {
+		res := &results[i]
+		res.At = now
+		switch _, invalid := err.(sms.ValidationError); {
+		case suppressed[res.Phone]:
+			res.State = Suppressed
+		case invalid:
+			res.State, res.Message = Invalid, err.Error()
+		case unknown(err):
+			res.State, res.Message = Pending, err.Error()
+		case err != nil:
+			res.State, res.Message = Failed, err.Error()
+		case r.c.conf.DryRun:
+			res.State = DryRun
+		default:
+			resp := opts.Response()
+			res.BizID, res.RequestID, res.Code, res.Message = resp.BizID, resp.RequestID, resp.Code, resp.Message
+			res.State = Sent
+			if resp.Err() != nil {
+				res.State = Failed
+			}
+		}
+	}
+	return results
+}
+
+// unknown reports whether err of action "SendSms" leaves it unknown whether the sms is sent,
+// e.g. ctx is done or the connection is lost during the request,
+// errs returned before the request is sent, or of a response rejecting it, are not
+func unknown(err error) bool {
+	switch err := err.(type) {
+	case nil, sms.ValidationError, *sms.SuppressedError:
+		return false
+	case *sms.HTTPError:
+		return err.StatusCode >= 500 || err.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
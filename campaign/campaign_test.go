@@ -0,0 +1,187 @@
+package campaign
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+const audience = `phone,name,order
+15300000001,Alice,1
+15300000002,Alice,2
+15300000003,Alice,3
+1530000000x,Bob,4
+15300000005,Carol,5
+`
+
+func testCampaign(t *testing.T, conf Config) (*smstest.Server, Config, func()) {
+	dir, err := ioutil.TempDir("", "campaign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := smstest.NewServer(smstest.Config{
+		FlowControl: &smstest.FlowControl{},
+		Templates:   map[string]string{"SMS_71390007": "${name}，您好"},
+	})
+	if conf.Client == (sms.Client{}) {
+		conf.Client = sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+	}
+	conf.SignName, conf.TemplateCode = "阿里云短信测试专用", "SMS_71390007"
+	conf.Params = map[string]string{"name": "name"}
+	conf.Results = filepath.Join(dir, "results.jsonl")
+	return srv, conf, func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCampaign_Run(t *testing.T) {
+	srv, conf, cleanup := testCampaign(t, Config{BatchSize: 2})
+	defer cleanup()
+
+	var results []Result
+	conf.OnResult = func(r Result) {
+		results = append(results, r)
+	}
+	s, err := New(conf).Run(context.Background(), strings.NewReader(audience))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice 1 and 2, Alice 3, Carol
+	if s.Rows != 5 || s.Requests != 3 || s.States[Sent] != 4 || s.States[Invalid] != 1 || s.Codes[sms.CodeOK] != 4 {
+		t.Errorf("Summary: %+v", s)
+	}
+	if n := len(srv.Messages()); n != 4 {
+		t.Errorf("Messages: %d", n)
+	}
+	if len(results) != 5 || results[0].BizID == "" || results[0].BizID != results[1].BizID || results[2].BizID == results[1].BizID || results[2].Row != 4 || results[2].State != Invalid {
+		t.Errorf("results: %+v", results)
+	}
+
+	var report bytes.Buffer
+	s.WriteReport(&report)
+	if !strings.Contains(report.String(), "sent: 4\ninvalid: 1\ncode OK: 4\n") {
+		t.Errorf("WriteReport: %s", report.String())
+	}
+
+	// all rows are done
+	srv.Reset()
+	s, err = New(conf).Run(context.Background(), strings.NewReader(audience))
+	if err != nil || s.Resumed != 5 || s.Requests != 0 || len(srv.Messages()) != 0 {
+		t.Errorf("Run: %+v %v", s, err)
+	}
+}
+
+func TestCampaign_Resume(t *testing.T) {
+	srv, conf, cleanup := testCampaign(t, Config{})
+	defer cleanup()
+
+	// the process crashed during the send of row 2
+	f, _, _ := openResults(conf.Results)
+	f.append(Result{Row: 1, State: Sent}, Result{Row: 2, State: Pending})
+	f.Close()
+
+	s, err := New(conf).Run(context.Background(), strings.NewReader(audience))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Resumed != 2 || s.Unverified != 1 || s.States[Sent] != 2 {
+		t.Errorf("Summary: %+v", s)
+	}
+	messages := srv.Messages()
+	if len(messages) != 2 || messages[0].PhoneNumber != "15300000003" {
+		t.Errorf("Messages: %+v", messages)
+	}
+}
+
+// cancelHandler cancels the run during the request
+type cancelHandler struct {
+	cancel func()
+}
+
+func (h cancelHandler) DoReq(opts sms.Options) (*sms.HTTPResponse, error) {
+	h.cancel()
+	return nil, opts.Context().Err()
+}
+
+func TestCampaign_Canceled(t *testing.T) {
+	srv, conf, cleanup := testCampaign(t, Config{})
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf.Options = []sms.Option{sms.ReqHandlerOption(cancelHandler{cancel})}
+	s, err := New(conf).Run(ctx, strings.NewReader(audience))
+	if err != context.Canceled {
+		t.Errorf("Run err: %v", err)
+	}
+	// rows of the request are left pending, they are not failed
+	if s.Requests != 1 || s.Unverified != 3 || s.States[Failed] != 0 {
+		t.Errorf("Summary: %+v", s)
+	}
+	last, _ := ReadResults(conf.Results)
+	for row := 1; row <= 3; row++ {
+		if last[row].State != Pending {
+			t.Errorf("result of row %d: %+v", row, last[row])
+		}
+	}
+	if len(srv.Messages()) != 0 {
+		t.Errorf("Messages: %+v", srv.Messages())
+	}
+}
+
+func TestCampaign_DryRun(t *testing.T) {
+	suppression := sms.NewSuppressionList(nil)
+	n, _ := phone.Parse("15300000005")
+	suppression.Add(n, sms.SuppressionScope{}, "")
+
+	srv, conf, cleanup := testCampaign(t, Config{DryRun: true})
+	defer cleanup()
+	conf.Client = sms.NewClient(sms.Config{AccessKeyID: "testId", AccessSecret: "testSecret", Endpoint: srv.Endpoint(), Suppression: suppression})
+
+	s, err := New(conf).Run(context.Background(), strings.NewReader(audience))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Requests != 0 || s.States[DryRun] != 3 || s.States[Invalid] != 1 || s.States[Suppressed] != 1 || len(srv.Messages()) != 0 {
+		t.Errorf("Summary: %+v", s)
+	}
+	if _, err := os.Stat(conf.Results); !os.IsNotExist(err) {
+		t.Errorf("results file is written: %v", err)
+	}
+}
+
+func TestCampaign_OutID(t *testing.T) {
+	srv, conf, cleanup := testCampaign(t, Config{OutIDColumn: "order", Rate: 50})
+	defer cleanup()
+
+	start := time.Now()
+	s, err := New(conf).Run(context.Background(), strings.NewReader(audience))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Requests != 4 {
+		t.Errorf("Requests: %d", s.Requests)
+	}
+	// 4 requests at 50/s
+	if d := time.Since(start); d < 60*time.Millisecond {
+		t.Errorf("Rate: %s", d)
+	}
+	if m := srv.Messages(); len(m) != 4 || m[3].OutID != "5" {
+		t.Errorf("Messages: %+v", m)
+	}
+
+	if _, err := New(Config{}).Run(context.Background(), strings.NewReader(audience)); err == nil {
+		t.Error("Run: Results is required")
+	}
+}
@@ -0,0 +1,131 @@
+package campaign
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// State of a row
+type State string
+
+const (
+	// Pending rows are being sent, a row left pending by a crash, or by an err leaving it
+	// unknown whether it's sent, e.g. ctx is done during the request, may have been sent,
+	// it's not sent again on resume
+	Pending State = "pending"
+
+	// Sent rows are accepted by aliyun sms api
+	Sent State = "sent"
+
+	// Failed rows are rejected by aliyun sms api
+	Failed State = "failed"
+
+	// Invalid rows are not sent since their params are invalid
+	Invalid State = "invalid"
+
+	// Suppressed rows are not sent since their numbers are suppressed by Config.Suppression of the client
+	Suppressed State = "suppressed"
+
+	// DryRun rows are validated but not sent
+	DryRun State = "dry-run"
+)
+
+// Result of a row, it's a line of the results file
+type Result struct {
+	Row   int    `json:"row"`
+	Phone string `json:"phone"`
+	State State  `json:"state"`
+
+	BizID     string `json:"biz_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+
+	At time.Time `json:"at"`
+}
+
+// resultsFile is the results file of a campaign, every result is appended and synced,
+// it's the checkpoint of the campaign
+type resultsFile struct {
+	f *os.File
+}
+
+// openResults opens the results file of path, it's created if not exists,
+// the last result of every row is returned
+// a partially written last line, e.g. the process crashed during a write, is ignored,
+// it's truncated so results appended don't continue it
+func openResults(path string) (*resultsFile, map[int]Result, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	last, err := parseResults(data)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if n := bytes.LastIndexByte(data, '\n') + 1; n < len(data) {
+		if err := f.Truncate(int64(n)); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+	return &resultsFile{f: f}, last, nil
+}
+
+// ReadResults reads the results file of path, the last result of every row is returned
+func ReadResults(path string) (map[int]Result, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[int]Result), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseResults(data)
+}
+
+// parseResults returns the last result of every row of lines of data
+func parseResults(data []byte) (map[int]Result, error) {
+	last := make(map[int]Result)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var r Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// partially written line
+			continue
+		}
+		last[r.Row] = r
+	}
+	return last, scanner.Err()
+}
+
+// append results and sync the file
+func (f *resultsFile) append(results ...Result) error {
+	var buf bytes.Buffer
+	for _, r := range results {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if _, err := f.f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return f.f.Sync()
+}
+
+func (f *resultsFile) Close() error {
+	return f.f.Close()
+}
@@ -0,0 +1,51 @@
+package campaign
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "campaign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "results.jsonl")
+
+	f, last, err := openResults(path)
+	if err != nil || len(last) != 0 {
+		t.Fatalf("openResults: %v %v", last, err)
+	}
+	now := time.Now()
+	f.append(Result{Row: 1, State: Pending, At: now}, Result{Row: 2, State: Pending, At: now})
+	f.append(Result{Row: 1, State: Sent, BizID: "1^0", At: now})
+	f.f.WriteString(`{"row":2,"state":"se`)
+	f.Close()
+
+	last, err = ReadResults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(last) != 2 || last[1].State != Sent || last[1].BizID != "1^0" || last[2].State != Pending {
+		t.Errorf("ReadResults: %+v", last)
+	}
+
+	// the partial line is truncated before results are appended
+	f, _, err = openResults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.append(Result{Row: 2, State: Sent, At: now})
+	f.Close()
+	if last, _ := ReadResults(path); last[2].State != Sent {
+		t.Errorf("ReadResults after resume: %+v", last)
+	}
+	if data, _ := ioutil.ReadFile(path); strings.Contains(string(data), `"se{`) {
+		t.Errorf("results file: %s", data)
+	}
+}
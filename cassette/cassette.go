@@ -0,0 +1,162 @@
+// Package cassette records requests and responses of aliyun sms api to cassette files,
+// and replays them as a sms.ReqHandler, e.g. to keep odd responses as regression fixtures
+//
+// credentials and signatures are scrubbed before they are recorded,
+// requests are matched by action, format and business params on replay
+package cassette
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// Request is the serializable envelope of a request
+type Request struct {
+	Action string `json:"action"`
+	Format string `json:"format"`
+
+	// Params are business params of the action, including "Version" and "RegionId",
+	// system params, e.g. "AccessKeyId" and "Signature", are excluded
+	Params map[string]string `json:"params"`
+}
+
+// Response is the serializable envelope of a response
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Body        string `json:"body"`
+}
+
+// httpResponse returns the sms.HTTPResponse replayed,
+// Status of cassettes recorded without it is 200
+func (r Response) httpResponse() *sms.HTTPResponse {
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := http.Header{}
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	if r.RequestID != "" {
+		header.Set(sms.RequestIDHeader, r.RequestID)
+	}
+	return &sms.HTTPResponse{StatusCode: status, Header: header, Body: []byte(r.Body)}
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Cassette is a list of interactions in order they are recorded
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// systemParams are scrubbed from Request
+var systemParams = map[string]bool{
+	"AccessKeyId":      true,
+	"Timestamp":        true,
+	"Format":           true,
+	"SignatureMethod":  true,
+	"SignatureVersion": true,
+	"SignatureNonce":   true,
+	"Signature":        true,
+	"Action":           true,
+}
+
+// NewRequest returns the envelope of a signed request url, e.g. sms.Options.URL()
+func NewRequest(rawURL string) (Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Request{}, err
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return Request{}, err
+	}
+
+	r := Request{Action: query.Get("Action"), Format: query.Get("Format"), Params: make(map[string]string)}
+	if r.Format == "" {
+		r.Format = "JSON"
+	}
+	for k := range query {
+		if !systemParams[k] {
+			r.Params[k] = query.Get(k)
+		}
+	}
+	return r, nil
+}
+
+// Match reports whether r matches o, params of ignored are not compared
+func (r Request) Match(o Request, ignored ...string) bool {
+	if r.Action != o.Action || r.Format != o.Format {
+		return false
+	}
+	skip := make(map[string]bool, len(ignored))
+	for _, k := range ignored {
+		skip[k] = true
+	}
+	for _, params := range []map[string]string{r.Params, o.Params} {
+		for k := range params {
+			if !skip[k] && r.Params[k] != o.Params[k] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the action and sorted params of r
+func (r Request) String() string {
+	keys := make([]string, 0, len(r.Params))
+	for k := range r.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := url.Values{}
+	for _, k := range keys {
+		values.Set(k, r.Params[k])
+	}
+	return r.Action + " (" + r.Format + ") " + values.Encode()
+}
+
+// Load the cassette file of path, an empty Cassette is returned if it does not exist
+func Load(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Cassette{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save the cassette to the file of path, it's replaced atomically
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
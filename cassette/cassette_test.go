@@ -0,0 +1,66 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewRequest(t *testing.T) {
+	r, err := NewRequest("https://dysmsapi.aliyuncs.com/?Signature=x&AccessKeyId=testId&Action=SendSms&Format=XML&" +
+		"PhoneNumbers=15300000001&SignName=%E9%98%BF&SignatureMethod=HMAC-SHA1&SignatureNonce=1&SignatureVersion=1.0&" +
+		"Timestamp=2018-04-09T15%3A27%3A02Z&Version=2017-05-25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Request{Action: "SendSms", Format: "XML", Params: map[string]string{"PhoneNumbers": "15300000001", "SignName": "阿", "Version": "2017-05-25"}}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("NewRequest: %+v", r)
+	}
+}
+
+func TestRequest_Match(t *testing.T) {
+	a := Request{Action: "SendSms", Format: "JSON", Params: map[string]string{"PhoneNumbers": "15300000001", "OutId": "1"}}
+	b := Request{Action: "SendSms", Format: "JSON", Params: map[string]string{"PhoneNumbers": "15300000001", "OutId": "2"}}
+	c := Request{Action: "SendSms", Format: "JSON", Params: map[string]string{"PhoneNumbers": "15300000001"}}
+
+	if !a.Match(a) || a.Match(b) || a.Match(c) || c.Match(a) {
+		t.Error("Match: params are not compared")
+	}
+	if !a.Match(b, "OutId") || !c.Match(a, "OutId") {
+		t.Error("Match: ignored params are compared")
+	}
+	if x := (Request{Action: "SendSms", Format: "XML", Params: a.Params}); a.Match(x) {
+		t.Error("Match: format is not compared")
+	}
+}
+
+func TestCassette_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "send.json")
+
+	c, err := Load(path)
+	if err != nil || len(c.Interactions) != 0 {
+		t.Fatalf("Load: %+v %v", c, err)
+	}
+	c.Interactions = append(c.Interactions, Interaction{
+		Request:    Request{Action: "SendSms", Format: "JSON", Params: map[string]string{"OutId": "1"}},
+		Response:   Response{Status: 400, Body: `{"Code":"isv.MOBILE_NUMBER_ILLEGAL"}`},
+		RecordedAt: time.Date(2018, 4, 9, 15, 27, 2, 0, time.UTC),
+	})
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil || !reflect.DeepEqual(loaded, c) {
+		t.Errorf("Load: %+v %v", loaded, err)
+	}
+}
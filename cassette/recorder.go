@@ -0,0 +1,181 @@
+package cassette
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// Mode of Recorder
+type Mode int
+
+const (
+	// Replay responds recorded interactions only, requests not recorded fail with *NotRecordedError
+	Replay Mode = iota
+
+	// Record sends all requests and records them
+	Record
+
+	// ReplayOrRecord replays recorded interactions, and records requests not recorded
+	ReplayOrRecord
+)
+
+// NotRecordedError is returned on replay if no interaction matches Request
+type NotRecordedError struct {
+	Request Request
+}
+
+func (e *NotRecordedError) Error() string {
+	return "cassette: request is not recorded: " + e.Request.String()
+}
+
+// Recorder is a sms.ReqHandler records and replays interactions of a cassette file
+// it's concurrent safe
+type Recorder struct {
+	path string
+	mode Mode
+
+	// Client sends requests to record, default http.DefaultClient
+	Client *http.Client
+
+	// MaxBodyBytes is upper limit of bytes of a recorded response body, default sms.DefaultMaxBodyBytes
+	MaxBodyBytes int64
+
+	// Ignored params are not compared on replay, e.g. "OutId" generated by each run
+	Ignored []string
+
+	// OnSaveError is called with errs of saving the cassette file, optional,
+	// the response recorded is returned anyway, e.g. func(err error) { t.Error(err) }
+	OnSaveError func(err error)
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+
+	// size of the cassette file saved by the Recorder, 0 if it's not saved yet
+	size int64
+}
+
+// New init a Recorder of the cassette file of path
+func New(path string, mode Mode) (*Recorder, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{path: path, mode: mode, cassette: c, used: make([]bool, len(c.Interactions))}, nil
+}
+
+// Cassette returns a copy of the interactions recorded and loaded
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// DoReq implements sms.ReqHandler
+// interactions matching the request are replayed in order they are recorded,
+// the last one is replayed again if all of them are replayed
+func (r *Recorder) DoReq(opts sms.Options) (*sms.HTTPResponse, error) {
+	req, err := NewRequest(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode != Record {
+		if res, ok := r.replay(req); ok {
+			return res.httpResponse(), nil
+		}
+		if r.mode == Replay {
+			return nil, &NotRecordedError{Request: req}
+		}
+	}
+
+	httpRes, err := sms.HTTPReqHandler{Client: r.Client, MaxBodyBytes: r.MaxBodyBytes}.DoReq(opts)
+	if err != nil {
+		return nil, err
+	}
+	res := Response{
+		Status:      httpRes.StatusCode,
+		ContentType: httpRes.Header.Get("Content-Type"),
+		RequestID:   httpRes.RequestID(),
+		Body:        string(httpRes.Body),
+	}
+	if err := r.record(Interaction{Request: req, Response: res, RecordedAt: time.Now().UTC()}); err != nil && r.OnSaveError != nil {
+		r.OnSaveError(err)
+	}
+	return httpRes, nil
+}
+
+func (r *Recorder) replay(req Request) (Response, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, it := range r.cassette.Interactions {
+		if !req.Match(it.Request, r.Ignored...) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return it.Response, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return Response{}, false
+	}
+	return r.cassette.Interactions[last].Response, true
+}
+
+// cassetteTail is the end of a cassette file of interactions written by Save
+const cassetteTail = "\n  ]\n}\n"
+
+// record it and save the cassette,
+// the cassette file is saved by Save first, then interactions are appended in place of its tail
+func (r *Recorder) record(it Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	r.used = append(r.used, true)
+	if r.size == 0 {
+		return r.save()
+	}
+
+	data, err := json.MarshalIndent(it, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	off := r.size - int64(len(cassetteTail))
+	n, err := f.WriteAt([]byte(",\n    "+string(data)+cassetteTail), off)
+	if err != nil {
+		// the cassette file is saved by Save again next time
+		r.size = 0
+		return err
+	}
+	r.size = off + int64(n)
+	return nil
+}
+
+// save the whole cassette by Save
+func (r *Recorder) save() error {
+	if err := r.cassette.Save(r.path); err != nil {
+		return err
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	r.size = info.Size()
+	return nil
+}
@@ -0,0 +1,158 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+var params = sms.SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: "1"}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "send.json")
+
+	srv := smstest.NewServer(smstest.Config{})
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+
+	rec, err := New(path, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := sms.NewSendAction(c, params).Do(sms.ReqHandlerOption(rec))
+	if err != nil || first.Response().Code != sms.CodeOK {
+		t.Fatalf("Do: %v", err)
+	}
+	// flow control of the fake
+	second, err := sms.NewSendAction(c, params).Do(sms.ReqHandlerOption(rec))
+	if err != nil || second.Response().Code != "isv.BUSINESS_LIMIT_CONTROL" {
+		t.Fatalf("Do: %v", err)
+	}
+	srv.Close()
+
+	data, _ := ioutil.ReadFile(path)
+	for _, secret := range []string{smstest.DefaultAccessKeyID, "Signature", "Timestamp"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %s: %s", secret, data)
+		}
+	}
+
+	rec, err = New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rec.Cassette().Interactions); n != 2 || rec.Cassette().Interactions[0].Response.Status != 200 {
+		t.Errorf("Cassette: %+v", rec.Cassette())
+	}
+	// credentials of the client are not matched
+	c = sms.NewClient(sms.Config{AccessKeyID: "otherId", AccessSecret: "otherSecret", Endpoint: srv.Endpoint()})
+	for _, code := range []string{sms.CodeOK, "isv.BUSINESS_LIMIT_CONTROL", "isv.BUSINESS_LIMIT_CONTROL"} {
+		opts, err := sms.NewSendAction(c, params).Do(sms.ReqHandlerOption(rec))
+		if err != nil || opts.Response().Code != code {
+			t.Fatalf("Do: %v", err)
+		}
+		if opts.Response().BizID != first.Response().BizID && code == sms.CodeOK {
+			t.Errorf("BizID: %s", opts.Response().BizID)
+		}
+		if res := opts.HTTPResponse(); res.StatusCode != 200 || res.ContentType() != "application/json" {
+			t.Errorf("HTTPResponse: %d %s", res.StatusCode, res.ContentType())
+		}
+	}
+
+	other := params
+	other.OutID = "2"
+	if _, err := sms.NewSendAction(c, other).Do(sms.ReqHandlerOption(rec)); err == nil || !strings.Contains(err.Error(), "OutId=2") {
+		t.Errorf("Do err: %v", err)
+	}
+	rec.Ignored = []string{"OutId"}
+	if _, err := sms.NewSendAction(c, other).Do(sms.ReqHandlerOption(rec)); err != nil {
+		t.Errorf("Do err: %v", err)
+	}
+	if _, err := sms.NewSendAction(c, other).Do(sms.ReqHandlerOption(rec), sms.XML); err == nil {
+		t.Error("Do: format is not matched")
+	}
+}
+
+func TestRecorder_ReplayOrRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "send.json")
+
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+
+	rec, _ := New(path, ReplayOrRecord)
+	for i := 0; i < 3; i++ {
+		opts, err := sms.NewSendAction(c, params).Do(sms.ReqHandlerOption(rec))
+		if err != nil || opts.Response().Code != sms.CodeOK {
+			t.Fatalf("Do: %v", err)
+		}
+	}
+	if n := len(srv.Messages()); n != 1 {
+		t.Errorf("Messages: %d", n)
+	}
+	if n := len(rec.Cassette().Interactions); n != 1 {
+		t.Errorf("Interactions: %d", n)
+	}
+}
+
+func TestRecorder_Append(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "send.json")
+
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+
+	rec, _ := New(path, Record)
+	rec.OnSaveError = func(err error) { t.Error(err) }
+	for _, outID := range []string{"1", "2", "3"} {
+		p := params
+		p.OutID = outID
+		sms.NewSendAction(c, p).Do(sms.ReqHandlerOption(rec))
+	}
+
+	// interactions appended are the same as the cassette saved
+	data, _ := ioutil.ReadFile(path)
+	saved := filepath.Join(dir, "saved.json")
+	cassette := rec.Cassette()
+	cassette.Save(saved)
+	if want, _ := ioutil.ReadFile(saved); string(data) != string(want) {
+		t.Errorf("cassette file:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestRecorder_SaveError(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+
+	rec, _ := New(filepath.Join(os.TempDir(), "no-such-dir", "send.json"), Record)
+	var errs []error
+	rec.OnSaveError = func(err error) { errs = append(errs, err) }
+	// the response is returned even if the cassette is not saved
+	opts, err := sms.NewSendAction(c, params).Do(sms.ReqHandlerOption(rec))
+	if err != nil || opts.Response().Code != sms.CodeOK {
+		t.Errorf("Do: %v", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("save errs: %v", errs)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/scistack/aliyun-sms-go/campaign"
+)
+
+func (e *cmdEnv) campaign(args []string) error {
+	fs := newFlagSet("campaign")
+	conf := campaign.Config{Client: e.c, Params: map[string]string{}}
+	audience := fs.String("audience", "", "path of the audience, CSV or JSONL by the extension")
+	fs.StringVar(&conf.Results, "results", "", "path of the results file, default the audience path with \".results.jsonl\"")
+	fs.StringVar(&conf.SignName, "sign", "", "sign name")
+	fs.StringVar(&conf.TemplateCode, "template", "", "template code, e.g. SMS_71390007")
+	fs.StringVar(&conf.PhoneColumn, "phone-column", campaign.DefaultPhoneColumn, "column of phone numbers")
+	fs.StringVar(&conf.OutIDColumn, "out-id-column", "", "column of OutId, optional")
+	fs.Var(paramFlag(conf.Params), "param", "template variable of name=column, repeatable, default all columns")
+	fs.Float64Var(&conf.Rate, "rate", 0, "upper limit of requests per second, 0 is unlimited")
+	fs.BoolVar(&conf.DryRun, "dry-run", false, "validate rows without sending")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *audience == "" {
+		return errors.New("campaign: -audience is required")
+	}
+	if conf.Results == "" {
+		conf.Results = *audience + ".results.jsonl"
+	}
+	if len(conf.Params) == 0 {
+		conf.Params = nil
+	}
+	conf.Format = campaign.DetectFormat(*audience)
+
+	f, err := os.Open(*audience)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// an interrupted campaign is resumed by running it again
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s, runErr := campaign.New(conf).Run(ctx, f)
+	var rows [][]string
+	for _, state := range []campaign.State{campaign.Sent, campaign.Failed, campaign.Invalid, campaign.Suppressed, campaign.DryRun} {
+		rows = append(rows, []string{string(state), strconv.Itoa(s.States[state])})
+	}
+	rows = append(rows, []string{"resumed", strconv.Itoa(s.Resumed)}, []string{"unverified", strconv.Itoa(s.Unverified)},
+		[]string{"requests", strconv.Itoa(s.Requests)})
+	if err := e.out.Print(s, []string{"ROWS", strconv.Itoa(s.Rows)}, rows); err != nil {
+		return err
+	}
+	return runErr
+}
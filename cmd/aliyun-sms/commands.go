@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// paramFlag is a repeatable flag of "k=v"
+type paramFlag sms.TemplateParam
+
+func (p paramFlag) String() string {
+	return sms.TemplateParam(p).String()
+}
+
+func (p paramFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return errors.New("param " + s + " is not of k=v")
+	}
+	p[s[:i]] = s[i+1:]
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("aliyun-sms "+name, flag.ContinueOnError)
+}
+
+func (e *cmdEnv) send(args []string) error {
+	fs := newFlagSet("send")
+	params := sms.SendSmsParams{RegionID: e.regionID, TemplateParam: sms.TemplateParam{}}
+	fs.StringVar(&params.PhoneNumbers, "phone", "", "comma separated phone numbers")
+	fs.StringVar(&params.SignName, "sign", "", "sign name")
+	fs.StringVar(&params.TemplateCode, "template", "", "template code, e.g. SMS_71390007")
+	fs.StringVar(&params.OutID, "out-id", "", "OutId of the send")
+	fs.Var(paramFlag(params.TemplateParam), "param", "template param of k=v, repeatable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(params.TemplateParam) == 0 {
+		params.TemplateParam = nil
+	}
+
+	opts, err := sms.NewSendAction(e.c, params).Do()
+	if err != nil {
+		return err
+	}
+	res := opts.Response()
+	if err := e.out.Print(res, []string{"REQUEST ID", "CODE", "MESSAGE", "BIZ ID"},
+		[][]string{{res.RequestID, res.Code, res.Message, res.BizID}}); err != nil {
+		return err
+	}
+	return res.Err()
+}
+
+func (e *cmdEnv) query(args []string) error {
+	fs := newFlagSet("query")
+	params := sms.QuerySendDetailsParams{RegionID: e.regionID}
+	fs.StringVar(&params.PhoneNumber, "phone", "", "phone number")
+	fs.StringVar(&params.BizID, "biz-id", "", "BizId of the send, optional")
+	date := fs.String("date", time.Now().In(sms.ChinaStandardTime).Format("20060102"), "send date of 20060102 in China Standard Time")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	d, err := time.Parse("20060102", *date)
+	if err != nil {
+		return errors.New("invalid date " + *date)
+	}
+	params.SendDate = sms.Date(d)
+
+	details, err := sms.QueryAllSendDetails(e.c, params)
+	if err != nil {
+		return err
+	}
+	if details == nil {
+		details = []sms.SendDetailDTO{}
+	}
+	rows := make([][]string, len(details))
+	for i, d := range details {
+		rows[i] = []string{d.PhoneNum, d.SendStatus.String(), d.ErrCode, d.TemplateCode, d.SendDate, d.ReceiveDate, d.OutID, d.Content}
+	}
+	return e.out.Print(details, []string{"PHONE", "STATUS", "ERR CODE", "TEMPLATE", "SEND DATE", "RECEIVE DATE", "OUT ID", "CONTENT"}, rows)
+}
+
+func (e *cmdEnv) stats(args []string) error {
+	fs := newFlagSet("stats")
+	params := sms.QuerySendStatisticsParams{RegionID: e.regionID}
+	today := time.Now().In(sms.ChinaStandardTime).Format("20060102")
+	start := fs.String("start", today, "start date of 20060102")
+	end := fs.String("end", today, "end date of 20060102")
+	intl := fs.Bool("intl", false, "international sms instead of domestic")
+	fs.StringVar(&params.SignName, "sign", "", "sign name, optional")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	for _, d := range []struct {
+		s    string
+		date *sms.Date
+	}{{*start, &params.StartDate}, {*end, &params.EndDate}} {
+		t, err := time.Parse("20060102", d.s)
+		if err != nil {
+			return errors.New("invalid date " + d.s)
+		}
+		*d.date = sms.Date(t)
+	}
+	if *intl {
+		params.IsGlobe = sms.International
+	}
+
+	stats, err := sms.QueryAllSendStatistics(e.c, params)
+	if err != nil {
+		return err
+	}
+	if stats == nil {
+		stats = []sms.SendStatistics{}
+	}
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		rows[i] = []string{s.SendDate, strconv.Itoa(s.TotalCount), strconv.Itoa(s.RespondedSuccessCount),
+			strconv.Itoa(s.RespondedFailCount), strconv.Itoa(s.NoRespondedCount)}
+	}
+	return e.out.Print(stats, []string{"DATE", "TOTAL", "SUCCESS", "FAILED", "NO RESPONSE"}, rows)
+}
+
+func (e *cmdEnv) templates(args []string) error {
+	if err := newFlagSet("templates list").Parse(args); err != nil {
+		return err
+	}
+	templates, err := sms.QueryAllSmsTemplates(e.c)
+	if err != nil {
+		return err
+	}
+	if templates == nil {
+		templates = []sms.SmsTemplate{}
+	}
+	rows := make([][]string, len(templates))
+	for i, t := range templates {
+		rows[i] = []string{t.TemplateCode, t.TemplateName, t.TemplateType.String(), string(t.AuditStatus), t.CreateDate, t.TemplateContent}
+	}
+	return e.out.Print(templates, []string{"CODE", "NAME", "TYPE", "AUDIT STATUS", "CREATE DATE", "CONTENT"}, rows)
+}
+
+func (e *cmdEnv) signs(args []string) error {
+	if err := newFlagSet("signs list").Parse(args); err != nil {
+		return err
+	}
+	signs, err := sms.QueryAllSmsSigns(e.c)
+	if err != nil {
+		return err
+	}
+	if signs == nil {
+		signs = []sms.SmsSign{}
+	}
+	rows := make([][]string, len(signs))
+	for i, s := range signs {
+		rows[i] = []string{s.SignName, string(s.AuditStatus), s.BusinessType, s.CreateDate}
+	}
+	return e.out.Print(signs, []string{"SIGN", "AUDIT STATUS", "BUSINESS TYPE", "CREATE DATE"}, rows)
+}
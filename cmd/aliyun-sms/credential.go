@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// credential of an account
+type credential struct {
+	AccessKeyID  string
+	AccessSecret string
+	RegionID     string
+}
+
+// aliyunConfig is the config file of aliyun cli
+type aliyunConfig struct {
+	Current  string `json:"current"`
+	Profiles []struct {
+		Name            string `json:"name"`
+		AccessKeyID     string `json:"access_key_id"`
+		AccessKeySecret string `json:"access_key_secret"`
+		RegionID        string `json:"region_id"`
+	} `json:"profiles"`
+}
+
+// loadCredential from env, or profile of the config file of path,
+// the current profile is used if profile is ""
+func loadCredential(getenv func(string) string, path, profile string) (credential, error) {
+	if id, secret := getenv("ALIBABA_CLOUD_ACCESS_KEY_ID"), getenv("ALIBABA_CLOUD_ACCESS_KEY_SECRET"); id != "" && profile == "" {
+		return credential{AccessKeyID: id, AccessSecret: secret, RegionID: getenv("ALIBABA_CLOUD_REGION_ID")}, nil
+	}
+
+	if path == "" {
+		home := getenv("HOME")
+		if home == "" {
+			return credential{}, errors.New("no credential, set env ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET")
+		}
+		path = filepath.Join(home, ".aliyun", "config.json")
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return credential{}, errors.New("no credential, set env ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET, or configure " + path)
+	}
+	if err != nil {
+		return credential{}, err
+	}
+	var conf aliyunConfig
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return credential{}, errors.New(path + ": " + err.Error())
+	}
+
+	if profile == "" {
+		profile = conf.Current
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	for _, p := range conf.Profiles {
+		if p.Name == profile {
+			return credential{AccessKeyID: p.AccessKeyID, AccessSecret: p.AccessKeySecret, RegionID: p.RegionID}, nil
+		}
+	}
+	return credential{}, errors.New(path + ": profile " + profile + " not found")
+}
@@ -0,0 +1,105 @@
+// Command aliyun-sms sends and queries sms by aliyun sms api
+//
+//	aliyun-sms [flags] send -phone 15300000001 -sign 阿里云短信测试专用 -template SMS_71390007 -param code=1234
+//	aliyun-sms [flags] query -phone 15300000001 -date 20180409
+//	aliyun-sms [flags] stats -start 20180401 -end 20180409
+//	aliyun-sms [flags] campaign -audience customers.csv -sign 阿里云短信测试专用 -template SMS_71390007 -rate 10
+//	aliyun-sms [flags] templates list
+//	aliyun-sms [flags] signs list
+//
+// credentials are read from env ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET,
+// or the profile of aliyun cli, "~/.aliyun/config.json"
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+const usage = `usage: aliyun-sms [flags] <command> [command flags]
+
+commands:
+  send            send sms to phone numbers
+  query           query send details of a phone number of a day, all pages
+  stats           query send statistics of days
+  campaign        send a template to a CSV or JSONL audience, resumable
+  templates list  list templates of the account
+  signs list      list signs of the account
+
+flags:
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Getenv); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "aliyun-sms:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run the command of args, output is written to w, env is read by getenv
+func run(args []string, w io.Writer, getenv func(string) string) error {
+	fs := flag.NewFlagSet("aliyun-sms", flag.ContinueOnError)
+	profile := fs.String("profile", "", "profile of aliyun cli config, default the current one")
+	config := fs.String("config", "", "path of aliyun cli config, default ~/.aliyun/config.json")
+	endpoint := fs.String("endpoint", sms.DefaultEndPoint, "endpoint of aliyun sms api")
+	format := fs.String("o", "table", "output format: table, json or csv")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	out, err := newPrinter(*format, w)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	cred, err := loadCredential(getenv, *config, *profile)
+	if err != nil {
+		return err
+	}
+	c := sms.NewClient(sms.Config{AccessKeyID: cred.AccessKeyID, AccessSecret: cred.AccessSecret, Endpoint: *endpoint})
+	env := &cmdEnv{c: c, regionID: cred.RegionID, out: out}
+
+	cmd, args := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "send":
+		return env.send(args)
+	case "query":
+		return env.query(args)
+	case "stats":
+		return env.stats(args)
+	case "campaign":
+		return env.campaign(args)
+	case "templates", "signs":
+		if len(args) == 0 || args[0] != "list" {
+			return errors.New(cmd + ": unknown command, use \"" + cmd + " list\"")
+		}
+		if cmd == "templates" {
+			return env.templates(args[1:])
+		}
+		return env.signs(args[1:])
+	}
+	return errors.New("unknown command " + strings.TrimSpace(cmd))
+}
+
+// cmdEnv is shared by commands
+type cmdEnv struct {
+	c        sms.Client
+	regionID string
+	out      printer
+}
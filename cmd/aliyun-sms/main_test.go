@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+func testEnv(env map[string]string) func(string) string {
+	return func(k string) string {
+		return env[k]
+	}
+}
+
+var testKeys = testEnv(map[string]string{
+	"ALIBABA_CLOUD_ACCESS_KEY_ID":     smstest.DefaultAccessKeyID,
+	"ALIBABA_CLOUD_ACCESS_KEY_SECRET": smstest.DefaultAccessSecret,
+})
+
+func TestRun_SendAndQuery(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{Templates: map[string]string{"SMS_71390007": "您的验证码为${code}"}})
+	defer srv.Close()
+
+	var out bytes.Buffer
+	err := run([]string{"-endpoint", srv.Endpoint(), "send", "-phone", "15300000001", "-sign", "阿里云短信测试专用",
+		"-template", "SMS_71390007", "-param", "code=1234", "-out-id", "1"}, &out, testKeys)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if m := srv.Messages(); len(m) != 1 || m[0].Content != "【阿里云短信测试专用】您的验证码为1234" || !strings.Contains(out.String(), m[0].BizID) {
+		t.Errorf("send: %s %+v", out.String(), m)
+	}
+
+	out.Reset()
+	if err := run([]string{"-endpoint", srv.Endpoint(), "-o", "json", "query", "-phone", "15300000001"}, &out, testKeys); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	var details []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &details); err != nil || len(details) != 1 || details[0]["OutId"] != "1" {
+		t.Errorf("query: %s", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"-endpoint", srv.Endpoint(), "-o", "csv", "query", "-phone", "15300000001"}, &out, testKeys); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "15300000001,") {
+		t.Errorf("query: %s", out.String())
+	}
+
+	// flow control of the fake
+	if err := run([]string{"-endpoint", srv.Endpoint(), "send", "-phone", "15300000001", "-sign", "阿里云短信测试专用",
+		"-template", "SMS_71390007", "-param", "code=1234"}, ioutil.Discard, testKeys); err == nil || !strings.Contains(err.Error(), "isv.BUSINESS_LIMIT_CONTROL") {
+		t.Errorf("send err: %v", err)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	cases := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"-o", "yaml", "send"}, "unknown output format"},
+		{[]string{"unknown"}, "unknown command"},
+		{[]string{"templates"}, "templates list"},
+		{[]string{"send", "-param", "code"}, "not of k=v"},
+		{[]string{"send"}, "sms: invalid params"},
+	}
+	for _, cs := range cases {
+		if err := run(cs.args, ioutil.Discard, testKeys); err == nil || !strings.Contains(err.Error(), cs.err) {
+			t.Errorf("%v: %v", cs.args, err)
+		}
+	}
+}
+
+func TestLoadCredential(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aliyun-sms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(path, []byte(`{"current":"prod","profiles":[
+		{"name":"prod","access_key_id":"prodId","access_key_secret":"prodSecret","region_id":"cn-hangzhou"},
+		{"name":"test","access_key_id":"testId","access_key_secret":"testSecret"}]}`), 0600)
+
+	cases := []struct {
+		env     func(string) string
+		profile string
+		id      string
+	}{
+		{testKeys, "", smstest.DefaultAccessKeyID},
+		{testEnv(nil), "", "prodId"},
+		{testEnv(nil), "test", "testId"},
+		// profile is preferred over env
+		{testKeys, "prod", "prodId"},
+	}
+	for _, cs := range cases {
+		cred, err := loadCredential(cs.env, path, cs.profile)
+		if err != nil || cred.AccessKeyID != cs.id {
+			t.Errorf("loadCredential(%s): %+v %v", cs.profile, cred, err)
+		}
+	}
+	if _, err := loadCredential(testEnv(nil), path, "unknown"); err == nil {
+		t.Error("loadCredential: unknown profile")
+	}
+	if _, err := loadCredential(testEnv(map[string]string{"HOME": dir}), "", ""); err == nil {
+		t.Error("loadCredential: config not exists")
+	}
+}
+
+func TestRun_Campaign(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{FlowControl: &smstest.FlowControl{}})
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "aliyun-sms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audience.jsonl")
+	ioutil.WriteFile(path, []byte(`{"phone":"15300000001","code":"1"}`+"\n"+`{"phone":"15300000002","code":"2"}`+"\n"), 0600)
+
+	args := []string{"-endpoint", srv.Endpoint(), "-o", "csv", "campaign", "-audience", path, "-sign", "阿里云短信测试专用", "-template", "SMS_71390007"}
+	var out bytes.Buffer
+	if err := run(args, &out, testKeys); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "ROWS,2\nsent,2\n") || len(srv.Messages()) != 2 {
+		t.Errorf("campaign: %s", out.String())
+	}
+	if _, err := os.Stat(path + ".results.jsonl"); err != nil {
+		t.Errorf("results: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes a result of a command
+type printer interface {
+	// Print v, header and rows are the table of v
+	Print(v interface{}, header []string, rows [][]string) error
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return tablePrinter{w}, nil
+	case "json":
+		return jsonPrinter{w}, nil
+	case "csv":
+		return csvPrinter{w}, nil
+	}
+	return nil, errors.New("unknown output format " + format)
+}
+
+type tablePrinter struct {
+	w io.Writer
+}
+
+func (p tablePrinter) Print(v interface{}, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		if _, err := io.WriteString(tw, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p jsonPrinter) Print(v interface{}, header []string, rows [][]string) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+type csvPrinter struct {
+	w io.Writer
+}
+
+func (p csvPrinter) Print(v interface{}, header []string, rows [][]string) error {
+	cw := csv.NewWriter(p.w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
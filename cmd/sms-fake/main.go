@@ -0,0 +1,50 @@
+// Command sms-fake serves a fake of aliyun sms api for integration tests,
+// e.g. in docker-compose, point Config.Endpoint of clients to it
+//
+//	sms-fake -addr :8080 -keys testId:testSecret -delay 2s
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	keys := flag.String("keys", smstest.DefaultAccessKeyID+":"+smstest.DefaultAccessSecret, "comma separated AccessKeyId:AccessSecret")
+	signNames := flag.String("sign-names", "", "comma separated sign names accepted, any sign if it's empty")
+	delay := flag.Duration("delay", 0, "delay before messages are delivered")
+	skew := flag.Duration("max-clock-skew", smstest.DefaultMaxClockSkew, "upper limit of the difference of Timestamp and now")
+	perMinute := flag.Int("per-minute", smstest.DefaultFlowControl.PerMinute, "messages per phone number per minute, 0 is unlimited")
+	perHour := flag.Int("per-hour", smstest.DefaultFlowControl.PerHour, "messages per phone number per hour, 0 is unlimited")
+	perDay := flag.Int("per-day", smstest.DefaultFlowControl.PerDay, "messages per phone number per day, 0 is unlimited")
+	flag.Parse()
+
+	conf := smstest.Config{
+		Keys:         make(map[string]string),
+		MaxClockSkew: *skew,
+		FlowControl:  &smstest.FlowControl{PerMinute: *perMinute, PerHour: *perHour, PerDay: *perDay},
+		Delivery: func(m smstest.Message) smstest.Outcome {
+			return smstest.Outcome{Delay: *delay}
+		},
+	}
+	for _, kv := range strings.Split(*keys, ",") {
+		i := strings.Index(kv, ":")
+		if i <= 0 {
+			log.Fatalf("sms-fake: invalid key %q", kv)
+		}
+		conf.Keys[kv[:i]] = kv[i+1:]
+	}
+	if *signNames != "" {
+		conf.SignNames = strings.Split(*signNames, ",")
+	}
+
+	s := &http.Server{Addr: *addr, Handler: smstest.NewFake(conf), ReadTimeout: 10 * time.Second}
+	log.Printf("sms-fake: listening on %s", *addr)
+	log.Fatal(s.ListenAndServe())
+}
@@ -0,0 +1,81 @@
+// Command sms-gateway serves package gateway, callers send sms by api keys
+// without holding aliyun credentials
+//
+//	sms-gateway -addr :8080 -callers callers.json
+//
+// credentials of aliyun are read from env ALIBABA_CLOUD_ACCESS_KEY_ID and
+// ALIBABA_CLOUD_ACCESS_KEY_SECRET, the key of message ids is read from env SMS_GATEWAY_SECRET,
+// callers.json is a list of gateway.Caller, e.g.
+//
+//	[{"name": "billing", "api_key": "...", "sign_names": ["阿里云短信测试专用"], "templates": ["SMS_71390007"]}]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/gateway"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	callersPath := flag.String("callers", "callers.json", "path of the list of callers")
+	endpoint := flag.String("endpoint", sms.DefaultEndPoint, "endpoint of aliyun sms api")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of requests to aliyun sms api")
+	idempotency := flag.Int("idempotency-capacity", 10000, "number of Idempotency-Key remembered, 0 disables it")
+	flag.Parse()
+
+	data, err := ioutil.ReadFile(*callersPath)
+	if err != nil {
+		log.Fatal("sms-gateway: ", err)
+	}
+	var callers []gateway.Caller
+	if err := json.Unmarshal(data, &callers); err != nil {
+		log.Fatal("sms-gateway: ", *callersPath, ": ", err)
+	}
+
+	id, secret := os.Getenv("ALIBABA_CLOUD_ACCESS_KEY_ID"), os.Getenv("ALIBABA_CLOUD_ACCESS_KEY_SECRET")
+	if id == "" || secret == "" {
+		log.Fatal("sms-gateway: env ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET are required")
+	}
+	c := sms.NewClient(sms.Config{AccessKeyID: id, AccessSecret: secret, Endpoint: *endpoint})
+
+	conf := gateway.Config{Actions: gateway.ClientActions(c), Callers: callers, Secret: []byte(os.Getenv("SMS_GATEWAY_SECRET"))}
+	if len(conf.Secret) == 0 {
+		log.Print("sms-gateway: env SMS_GATEWAY_SECRET is not set, message ids are not found after a restart")
+	}
+	if *idempotency > 0 {
+		store := sms.NewMemoryIdempotencyStore(*idempotency)
+		conf.Options = append(conf.Options, sms.ReqHandlerOption(sms.NewIdempotentReqHandler(store, sms.DefaultIdempotencyWindow, nil)))
+	}
+	g := gateway.New(conf)
+
+	s := &http.Server{
+		Addr:         *addr,
+		Handler:      http.TimeoutHandler(g, *timeout, `{"code":"GatewayTimeout","message":"timeout"}`),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: *timeout + 5*time.Second,
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		s.Shutdown(ctx)
+	}()
+
+	log.Printf("sms-gateway: listening on %s, %d callers", *addr, len(callers))
+	if err := s.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal("sms-gateway: ", err)
+	}
+}
@@ -0,0 +1,175 @@
+// Package gateway is an http/json service in front of aliyun sms api,
+// callers send and query sms by their api keys without holding aliyun credentials
+//
+// endpoints are described in openapi.yaml:
+//
+//	POST /v1/messages       send a template to phone numbers
+//	GET  /v1/messages/{id}  statuses of recipients of a message
+//	GET  /v1/details        send details of a phone number of a day
+package gateway
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// Any allows all sign names or templates in Caller
+const Any = "*"
+
+// Caller of the gateway
+type Caller struct {
+	Name   string `json:"name"`
+	APIKey string `json:"api_key"`
+
+	// SignNames the caller is allowed to send with, Any allows all of them
+	SignNames []string `json:"sign_names"`
+
+	// Templates the caller is allowed to send and query, Any allows all of them
+	Templates []string `json:"templates"`
+}
+
+func allowed(list []string, v string) bool {
+	for _, s := range list {
+		if s == Any || s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Actions init actions of aliyun sms api, e.g. *sms.ClientPool
+type Actions interface {
+	NewSendAction(params sms.SendSmsParams) sms.SendSmsAction
+	NewQuerySendDetailsAction(params sms.QuerySendDetailsParams) sms.QuerySendDetailsAction
+}
+
+// ClientActions returns Actions of c
+func ClientActions(c sms.Client) Actions {
+	return clientActions{c}
+}
+
+type clientActions struct {
+	c sms.Client
+}
+
+func (a clientActions) NewSendAction(params sms.SendSmsParams) sms.SendSmsAction {
+	return sms.NewSendAction(a.c, params)
+}
+
+func (a clientActions) NewQuerySendDetailsAction(params sms.QuerySendDetailsParams) sms.QuerySendDetailsAction {
+	return sms.NewQuerySendDetailsAction(a.c, params)
+}
+
+// Config of Gateway
+type Config struct {
+	Actions Actions
+	Callers []Caller
+
+	// Options are applied to every action
+	Options []sms.Option
+
+	// Secret is the key of encryption of message ids, a random one is generated if it's empty,
+	// it must be set and be the same for gateways behind a load balancer,
+	// or ids are not found after a restart
+	Secret []byte
+}
+
+// Gateway is an http.Handler of the gateway service
+type Gateway struct {
+	conf Config
+}
+
+// New init a Gateway
+func New(conf Config) *Gateway {
+	if len(conf.Secret) == 0 {
+		conf.Secret = make([]byte, 32)
+		if _, err := rand.Read(conf.Secret); err != nil {
+			panic(err)
+		}
+	}
+	return &Gateway{conf: conf}
+}
+
+// errorBody is the body of failed responses
+type errorBody struct {
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	RequestID string   `json:"request_id,omitempty"`
+	Fields    []string `json:"fields,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Code: code, Message: message})
+}
+
+// authenticate the caller by header "Authorization: Bearer <key>" or "X-API-Key: <key>"
+func (g *Gateway) authenticate(r *http.Request) *Caller {
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if key == "" {
+		return nil
+	}
+	var found *Caller
+	for i := range g.conf.Callers {
+		c := &g.conf.Callers[i]
+		// every key is compared to take the same time
+		if subtle.ConstantTimeCompare([]byte(c.APIKey), []byte(key)) == 1 && c.APIKey != "" {
+			found = c
+		}
+	}
+	return found
+}
+
+// ServeHTTP implements http.Handler
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	caller := g.authenticate(r)
+	if caller == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="sms-gateway"`)
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "api key is missing or invalid")
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/v1/messages":
+		if r.Method != http.MethodPost {
+			g.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		g.send(w, r, caller)
+	case strings.HasPrefix(path, "/v1/messages/"):
+		if r.Method != http.MethodGet {
+			g.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		g.message(w, r, caller, strings.TrimPrefix(path, "/v1/messages/"))
+	case path == "/v1/details":
+		if r.Method != http.MethodGet {
+			g.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		g.details(w, r, caller)
+	default:
+		writeError(w, http.StatusNotFound, "NotFound", "no endpoint "+r.URL.Path)
+	}
+}
+
+func (g *Gateway) methodNotAllowed(w http.ResponseWriter, method string) {
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method must be "+method)
+}
@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+var callers = []Caller{
+	{Name: "billing", APIKey: "billing-key", SignNames: []string{"阿里云短信测试专用"}, Templates: []string{"SMS_71390007"}},
+	{Name: "ops", APIKey: "ops-key", SignNames: []string{Any}, Templates: []string{Any}},
+}
+
+func testGateway(conf smstest.Config) (*Gateway, *smstest.Server) {
+	srv := smstest.NewServer(conf)
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+	return New(Config{Actions: ClientActions(c), Callers: callers}), srv
+}
+
+func do(g *Gateway, method, target, key string, body io.Reader, v interface{}) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, body)
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, r)
+	if v != nil {
+		json.Unmarshal(w.Body.Bytes(), v)
+	}
+	return w
+}
+
+func TestGateway_Auth(t *testing.T) {
+	g, srv := testGateway(smstest.Config{})
+	defer srv.Close()
+
+	var e errorBody
+	if w := do(g, "GET", "/v1/details", "", nil, &e); w.Code != http.StatusUnauthorized || e.Code != "Unauthorized" || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("no key: %d %+v", w.Code, e)
+	}
+	if w := do(g, "GET", "/v1/details", "wrong", nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong key: %d", w.Code)
+	}
+
+	r := httptest.NewRequest("GET", "/v1/unknown", nil)
+	r.Header.Set("X-API-Key", "ops-key")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("X-API-Key: %d", w.Code)
+	}
+}
+
+func TestGateway_Routes(t *testing.T) {
+	g, srv := testGateway(smstest.Config{})
+	defer srv.Close()
+
+	cases := []struct {
+		method, target string
+		status         int
+		allow          string
+	}{
+		{"GET", "/v1/messages", http.StatusMethodNotAllowed, "POST"},
+		{"POST", "/v1/messages/x", http.StatusMethodNotAllowed, "GET"},
+		{"POST", "/v1/details", http.StatusMethodNotAllowed, "GET"},
+		{"GET", "/v2/messages", http.StatusNotFound, ""},
+		{"GET", "/v1/messages/x", http.StatusNotFound, ""},
+	}
+	for _, cs := range cases {
+		w := do(g, cs.method, cs.target, "ops-key", strings.NewReader("{}"), nil)
+		if w.Code != cs.status || w.Header().Get("Allow") != cs.allow {
+			t.Errorf("%s %s: %d %s", cs.method, cs.target, w.Code, w.Header().Get("Allow"))
+		}
+	}
+}
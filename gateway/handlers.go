@@ -0,0 +1,299 @@
+package gateway
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// maxBodyBytes is upper limit of request bodies
+const maxBodyBytes = 64 << 10
+
+// SendRequest is the body of POST /v1/messages
+type SendRequest struct {
+	PhoneNumbers  []string          `json:"phone_numbers"`
+	SignName      string            `json:"sign_name"`
+	TemplateCode  string            `json:"template_code"`
+	TemplateParam map[string]string `json:"template_param,omitempty"`
+	OutID         string            `json:"out_id,omitempty"`
+}
+
+// SendResponse is the body of a successful POST /v1/messages
+type SendResponse struct {
+	ID        string `json:"id"`
+	BizID     string `json:"biz_id"`
+	RequestID string `json:"request_id"`
+
+	// Suppressed are phone numbers filtered out by suppressions
+	Suppressed []string `json:"suppressed,omitempty"`
+}
+
+// Recipient is the status of a phone number of a message
+type Recipient struct {
+	PhoneNumber string `json:"phone_number"`
+
+	// Status is "waiting", "failed", "delivered", or "unknown" if it's not queryable yet
+	Status       string `json:"status"`
+	ErrCode      string `json:"err_code,omitempty"`
+	TemplateCode string `json:"template_code,omitempty"`
+	Content      string `json:"content,omitempty"`
+	SendDate     string `json:"send_date,omitempty"`
+	ReceiveDate  string `json:"receive_date,omitempty"`
+	OutID        string `json:"out_id,omitempty"`
+}
+
+// MessageResponse is the body of GET /v1/messages/{id}
+type MessageResponse struct {
+	ID         string      `json:"id"`
+	BizID      string      `json:"biz_id"`
+	Recipients []Recipient `json:"recipients"`
+}
+
+// DetailsResponse is the body of GET /v1/details
+type DetailsResponse struct {
+	Details []Recipient `json:"details"`
+}
+
+// MaxPhoneNumbers is upper limit of phone numbers of POST /v1/messages,
+// they are carried in the id of the message, so it's kept short
+const MaxPhoneNumbers = 100
+
+// maxQueries is upper limit of concurrent queries of GET /v1/messages/{id}
+const maxQueries = 8
+
+// messageID is what the id of a message refers to, the gateway keeps no state
+// the id is encrypted by Config.Secret with the caller as additional data,
+// so callers can not read phone numbers of ids, or forge ids of sms of others
+type messageID struct {
+	Caller       string   `json:"-"`
+	BizID        string   `json:"b"`
+	SendDate     string   `json:"d"`
+	PhoneNumbers []string `json:"p"`
+}
+
+// idAEAD returns AES-256-GCM of the key derived from Config.Secret
+func (g *Gateway) idAEAD() cipher.AEAD {
+	mac := hmac.New(sha256.New, g.conf.Secret)
+	mac.Write([]byte("gateway message id"))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// encodeID returns the id of the nonce and the sealed JSON of id in base64 of url
+func (g *Gateway) encodeID(id messageID) string {
+	data, _ := json.Marshal(id)
+	aead := g.idAEAD()
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, data, []byte(id.Caller)))
+}
+
+// decodeID opens s of caller, ids of other callers are not opened
+func (g *Gateway) decodeID(s, caller string) (messageID, bool) {
+	sealed, err := base64.RawURLEncoding.DecodeString(s)
+	aead := g.idAEAD()
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return messageID{}, false
+	}
+	data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(caller))
+	var id messageID
+	if err != nil || json.Unmarshal(data, &id) != nil || id.BizID == "" {
+		return messageID{}, false
+	}
+	id.Caller = caller
+	return id, true
+}
+
+func recipient(d sms.SendDetailDTO) Recipient {
+	return Recipient{
+		PhoneNumber:  d.PhoneNum,
+		Status:       d.SendStatus.String(),
+		ErrCode:      d.ErrCode,
+		TemplateCode: d.TemplateCode,
+		Content:      d.Content,
+		SendDate:     d.SendDate,
+		ReceiveDate:  d.ReceiveDate,
+		OutID:        d.OutID,
+	}
+}
+
+// options of an action of caller, header "Idempotency-Key" is the key of an sms.IdempotentReqHandler,
+// which falls back to OutId, both are scoped by the caller so keys of callers never collide
+func (g *Gateway) options(r *http.Request, caller *Caller) []sms.Option {
+	ctx := sms.WithIdempotencyScope(r.Context(), caller.Name)
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		ctx = sms.WithIdempotencyKey(ctx, key)
+	}
+	return append(append([]sms.Option{}, g.conf.Options...), sms.ContextOption(ctx))
+}
+
+func (g *Gateway) send(w http.ResponseWriter, r *http.Request, caller *Caller) {
+	var req SendRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidBody", err.Error())
+		return
+	}
+	if !allowed(caller.SignNames, req.SignName) {
+		writeError(w, http.StatusForbidden, "SignNameNotAllowed", "sign name "+req.SignName+" is not allowed")
+		return
+	}
+	if !allowed(caller.Templates, req.TemplateCode) {
+		writeError(w, http.StatusForbidden, "TemplateNotAllowed", "template "+req.TemplateCode+" is not allowed")
+		return
+	}
+	if len(req.PhoneNumbers) > MaxPhoneNumbers {
+		writeJSON(w, http.StatusBadRequest, errorBody{
+			Code:    "InvalidParams",
+			Message: "phone numbers are more than " + strconv.Itoa(MaxPhoneNumbers),
+			Fields:  []string{"PhoneNumbers"},
+		})
+		return
+	}
+
+	params := sms.SendSmsParams{
+		PhoneNumbers:  strings.Join(req.PhoneNumbers, ","),
+		SignName:      req.SignName,
+		TemplateCode:  req.TemplateCode,
+		TemplateParam: req.TemplateParam,
+		OutID:         req.OutID,
+	}
+	sendDate := time.Now().In(sms.ChinaStandardTime).Format("20060102")
+	opts, err := g.conf.Actions.NewSendAction(params).Do(g.options(r, caller)...)
+	if err == nil {
+		err = opts.Response().Err()
+	}
+	if err != nil {
+		writeActionError(w, err)
+		return
+	}
+
+	res := opts.Response()
+	id := g.encodeID(messageID{Caller: caller.Name, BizID: res.BizID, SendDate: sendDate, PhoneNumbers: strings.Split(opts.PhoneNumbers(), ",")})
+	body := SendResponse{ID: id, BizID: res.BizID, RequestID: res.RequestID}
+	for _, n := range opts.Suppressed() {
+		body.Suppressed = append(body.Suppressed, n.String())
+	}
+	w.Header().Set("Location", "/v1/messages/"+id)
+	writeJSON(w, http.StatusCreated, body)
+}
+
+func (g *Gateway) message(w http.ResponseWriter, r *http.Request, caller *Caller, rawID string) {
+	// messages of other callers are not found
+	id, ok := g.decodeID(rawID, caller.Name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "MessageNotFound", "message "+rawID+" is not found")
+		return
+	}
+	date, err := time.Parse("20060102", id.SendDate)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "MessageNotFound", "message "+rawID+" is not found")
+		return
+	}
+
+	// numbers are queried concurrently, up to maxQueries at a time
+	recipients := make([]Recipient, len(id.PhoneNumbers))
+	errs := make([]error, len(id.PhoneNumbers))
+	extOpts := g.options(r, caller)
+	sem := make(chan struct{}, maxQueries)
+	var wg sync.WaitGroup
+	for i, n := range id.PhoneNumbers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, n string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			recipients[i], errs[i] = g.recipient(caller, n, id.BizID, date, extOpts)
+		}(i, n)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			writeActionError(w, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, MessageResponse{ID: rawID, BizID: id.BizID, Recipients: recipients})
+}
+
+// recipient queries the status of n of the sms of bizID
+func (g *Gateway) recipient(caller *Caller, n, bizID string, date time.Time, extOpts []sms.Option) (Recipient, error) {
+	params := sms.QuerySendDetailsParams{PhoneNumber: n, BizID: bizID, SendDate: sms.Date(date)}
+	opts, err := g.conf.Actions.NewQuerySendDetailsAction(params).Do(extOpts...)
+	if err == nil {
+		err = opts.Response().Err()
+	}
+	if err != nil {
+		return Recipient{}, err
+	}
+	for _, d := range opts.Response().SmsSendDetailDTOs.SmsSendDetailDTO {
+		// sms of templates not allowed are hidden as details does
+		if allowed(caller.Templates, d.TemplateCode) {
+			return recipient(d), nil
+		}
+	}
+	return Recipient{PhoneNumber: n, Status: "unknown"}, nil
+}
+
+func (g *Gateway) details(w http.ResponseWriter, r *http.Request, caller *Caller) {
+	q := r.URL.Query()
+	params := sms.QuerySendDetailsParams{PhoneNumber: q.Get("phone_number"), BizID: q.Get("biz_id"), PageSize: sms.QueryMaxPageSize}
+	date := q.Get("date")
+	if date == "" {
+		date = time.Now().In(sms.ChinaStandardTime).Format("20060102")
+	}
+	d, err := time.Parse("20060102", date)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidParams", "date "+date+" is not of 20060102")
+		return
+	}
+	params.SendDate = sms.Date(d)
+
+	res := DetailsResponse{Details: []Recipient{}}
+	extOpts := g.options(r, caller)
+	for page := 1; ; page++ {
+		params.CurrentPage = page
+		opts, err := g.conf.Actions.NewQuerySendDetailsAction(params).Do(extOpts...)
+		if err == nil {
+			err = opts.Response().Err()
+		}
+		if err != nil {
+			writeActionError(w, err)
+			return
+		}
+		dtos := opts.Response().SmsSendDetailDTOs.SmsSendDetailDTO
+		for _, d := range dtos {
+			// sms of templates not allowed are of other callers
+			if allowed(caller.Templates, d.TemplateCode) {
+				res.Details = append(res.Details, recipient(d))
+			}
+		}
+		if len(dtos) == 0 || page*params.PageSize >= opts.Response().TotalCount {
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
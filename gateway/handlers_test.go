@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+const sendBody = `{"phone_numbers":["15300000001","15300000002"],"sign_name":"阿里云短信测试专用","template_code":"SMS_71390007","template_param":{"code":"1234"}}`
+
+func TestGateway_Send(t *testing.T) {
+	g, srv := testGateway(smstest.Config{
+		Delivery: func(m smstest.Message) smstest.Outcome {
+			if m.PhoneNumber == "15300000002" {
+				return smstest.Outcome{Delay: time.Hour}
+			}
+			return smstest.Outcome{}
+		},
+	})
+	defer srv.Close()
+
+	var res SendResponse
+	w := do(g, "POST", "/v1/messages", "billing-key", strings.NewReader(sendBody), &res)
+	if w.Code != http.StatusCreated || res.BizID == "" || w.Header().Get("Location") != "/v1/messages/"+res.ID {
+		t.Fatalf("send: %d %s", w.Code, w.Body.String())
+	}
+	if m := srv.Messages(); len(m) != 2 || m[0].TemplateParam["code"] != "1234" {
+		t.Errorf("Messages: %+v", m)
+	}
+
+	var msg MessageResponse
+	if w := do(g, "GET", "/v1/messages/"+res.ID, "billing-key", nil, &msg); w.Code != http.StatusOK {
+		t.Fatalf("message: %d %s", w.Code, w.Body.String())
+	}
+	if len(msg.Recipients) != 2 || msg.Recipients[0].Status != "delivered" || msg.Recipients[1].Status != "waiting" {
+		t.Errorf("message: %+v", msg)
+	}
+
+	// messages of other callers are not found
+	if w := do(g, "GET", "/v1/messages/"+res.ID, "ops-key", nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("message of billing: %d", w.Code)
+	}
+	// ids not encrypted by the gateway are not found
+	tampered := []byte(res.ID)
+	tampered[len(tampered)/2] ^= 1
+	for _, id := range []string{res.ID[:len(res.ID)-1], string(tampered), "AAAA", New(Config{Callers: callers}).encodeID(messageID{Caller: "billing", BizID: res.BizID})} {
+		if w := do(g, "GET", "/v1/messages/"+id, "billing-key", nil, nil); w.Code != http.StatusNotFound {
+			t.Errorf("message of forged id %s: %d", id, w.Code)
+		}
+	}
+
+	// phone numbers are not readable in ids
+	if strings.Contains(res.ID, "MTUzMDAwMDAwMD") {
+		t.Errorf("phone numbers in id: %s", res.ID)
+	}
+
+	var details DetailsResponse
+	if w := do(g, "GET", "/v1/details?phone_number=15300000001", "billing-key", nil, &details); w.Code != http.StatusOK || len(details.Details) != 1 {
+		t.Errorf("details: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_MessageTemplates(t *testing.T) {
+	g, srv := testGateway(smstest.Config{})
+	defer srv.Close()
+
+	var res SendResponse
+	other := strings.Replace(sendBody, "SMS_71390007", "SMS_1", 1)
+	if w := do(g, "POST", "/v1/messages", "ops-key", strings.NewReader(other), &res); w.Code != http.StatusCreated {
+		t.Fatalf("send: %d %s", w.Code, w.Body.String())
+	}
+	// sms of templates not allowed are hidden, even if the id refers to them
+	date := time.Now().In(sms.ChinaStandardTime).Format("20060102")
+	id := g.encodeID(messageID{Caller: "billing", BizID: res.BizID, SendDate: date, PhoneNumbers: []string{"15300000001"}})
+	var msg MessageResponse
+	if w := do(g, "GET", "/v1/messages/"+id, "billing-key", nil, &msg); w.Code != http.StatusOK {
+		t.Fatalf("message: %d %s", w.Code, w.Body.String())
+	}
+	if len(msg.Recipients) != 1 || msg.Recipients[0].Status != "unknown" || msg.Recipients[0].Content != "" {
+		t.Errorf("message: %+v", msg)
+	}
+}
+
+func TestGateway_SendErrors(t *testing.T) {
+	g, srv := testGateway(smstest.Config{})
+	defer srv.Close()
+
+	other := strings.Replace(sendBody, "SMS_71390007", "SMS_1", 1)
+	many := strings.Repeat(`"15300000001",`, MaxPhoneNumbers) + `"15300000002"`
+	cases := []struct {
+		key, body string
+		status    int
+		code      string
+	}{
+		{"billing-key", `{"phone":"15300000001"}`, http.StatusBadRequest, "InvalidBody"},
+		{"billing-key", strings.Replace(sendBody, "阿里云短信测试专用", "其他", 1), http.StatusForbidden, "SignNameNotAllowed"},
+		{"billing-key", other, http.StatusForbidden, "TemplateNotAllowed"},
+		{"ops-key", strings.Replace(sendBody, "15300000001", "1", 1), http.StatusBadRequest, "InvalidParams"},
+		{"ops-key", strings.Replace(sendBody, `"15300000001","15300000002"`, many, 1), http.StatusBadRequest, "InvalidParams"},
+		{"ops-key", other, http.StatusCreated, ""},
+		// flow control of the fake
+		{"ops-key", other, http.StatusTooManyRequests, "isv.BUSINESS_LIMIT_CONTROL"},
+	}
+	for _, cs := range cases {
+		var e errorBody
+		w := do(g, "POST", "/v1/messages", cs.key, strings.NewReader(cs.body), &e)
+		if w.Code != cs.status || e.Code != cs.code {
+			t.Errorf("%s: %d %s", cs.body, w.Code, w.Body.String())
+		}
+	}
+
+	// details of templates not allowed are excluded
+	var details DetailsResponse
+	if w := do(g, "GET", "/v1/details?phone_number=15300000001", "billing-key", nil, &details); w.Code != http.StatusOK || len(details.Details) != 0 {
+		t.Errorf("details: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(g, "GET", "/v1/details?phone_number=15300000001&date=0409", "ops-key", nil, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("details: %d", w.Code)
+	}
+}
+
+func TestGateway_Idempotency(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{FlowControl: &smstest.FlowControl{}})
+	defer srv.Close()
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+	h := sms.NewIdempotentReqHandler(sms.NewMemoryIdempotencyStore(10), 0, nil)
+	g := New(Config{Actions: ClientActions(c), Callers: callers, Options: []sms.Option{sms.ReqHandlerOption(h)}})
+
+	body := strings.Replace(sendBody, `"template_param"`, `"out_id":"1","template_param"`, 1)
+	for _, key := range []string{"billing-key", "billing-key", "ops-key"} {
+		if w := do(g, "POST", "/v1/messages", key, strings.NewReader(body), nil); w.Code != http.StatusCreated {
+			t.Fatalf("send: %d %s", w.Code, w.Body.String())
+		}
+	}
+	// the retry of billing is not sent, OutId of ops is not shared with billing
+	if n := len(srv.Messages()); n != 4 {
+		t.Errorf("Messages: %d", n)
+	}
+
+	// OutId reused by another message is sent
+	other := strings.Replace(body, `"15300000001"`, `"15300000003"`, 1)
+	if w := do(g, "POST", "/v1/messages", "billing-key", strings.NewReader(other), nil); w.Code != http.StatusCreated {
+		t.Fatalf("send: %d %s", w.Code, w.Body.String())
+	}
+	if n := len(srv.Messages()); n != 6 {
+		t.Errorf("Messages: %d", n)
+	}
+
+	// Idempotency-Key reused by another message is a conflict
+	for i, b := range []string{body, other} {
+		req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(b))
+		req.Header.Set("Authorization", "Bearer billing-key")
+		req.Header.Set("Idempotency-Key", "k")
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		if want := []int{http.StatusCreated, http.StatusConflict}[i]; w.Code != want {
+			t.Errorf("send with key: %d != %d %s", w.Code, want, w.Body.String())
+		}
+	}
+}
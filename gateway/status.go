@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// StatusCode returns the http status code of err of an action,
+// errs of the caller are 4xx, errs of the gateway or aliyun sms api are 5xx
+func StatusCode(err error) int {
+	switch err := err.(type) {
+	case nil:
+		return http.StatusOK
+	case sms.ValidationError:
+		return http.StatusBadRequest
+	case *sms.SuppressedError:
+		return http.StatusUnprocessableEntity
+	case *sms.Error:
+		switch err.Kind() {
+		case sms.KindThrottling:
+			return http.StatusTooManyRequests
+		case sms.KindInvalidParams:
+			return http.StatusBadRequest
+		case sms.KindQuota:
+			// the account of the gateway is out of balance, it's not a problem of the caller
+			return http.StatusServiceUnavailable
+		}
+		// auth, system and unknown errs are problems of the gateway or aliyun sms api
+		return http.StatusBadGateway
+	case net.Error:
+		if err.Timeout() {
+			return http.StatusGatewayTimeout
+		}
+	}
+	switch err {
+	case context.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case sms.ErrIdempotencyKeyReused:
+		return http.StatusConflict
+	}
+	return http.StatusBadGateway
+}
+
+// writeActionError writes err of an action
+func writeActionError(w http.ResponseWriter, err error) {
+	body := errorBody{Code: "GatewayError", Message: err.Error()}
+	switch e := err.(type) {
+	case sms.ValidationError:
+		body.Code, body.Fields = "InvalidParams", e.Fields()
+	case *sms.SuppressedError:
+		body.Code = "Suppressed"
+	case *sms.Error:
+		body.Code, body.Message, body.RequestID = e.Code, e.Message, e.RequestID
+	}
+	if err == sms.ErrIdempotencyKeyReused {
+		body.Code = "IdempotencyKeyReused"
+	}
+	status := StatusCode(err)
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "60")
+	}
+	writeJSON(w, status, body)
+}
@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestStatusCode(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{sms.ValidationError{{Field: "PhoneNumbers", Err: sms.ErrRequired}}, http.StatusBadRequest},
+		{&sms.SuppressedError{}, http.StatusUnprocessableEntity},
+		{&sms.Error{Code: "isv.BUSINESS_LIMIT_CONTROL"}, http.StatusTooManyRequests},
+		{&sms.Error{Code: "isv.MOBILE_NUMBER_ILLEGAL"}, http.StatusBadRequest},
+		{&sms.Error{Code: "isv.AMOUNT_NOT_ENOUGH"}, http.StatusServiceUnavailable},
+		{&sms.Error{Code: "SignatureDoesNotMatch"}, http.StatusBadGateway},
+		{&sms.Error{Code: "isp.SYSTEM_ERROR"}, http.StatusBadGateway},
+		{timeoutError{}, http.StatusGatewayTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{sms.ErrIdempotencyKeyReused, http.StatusConflict},
+		{errors.New("connection refused"), http.StatusBadGateway},
+	}
+	for _, cs := range cases {
+		if s := StatusCode(cs.err); s != cs.status {
+			t.Errorf("StatusCode(%v): %d != %d", cs.err, s, cs.status)
+		}
+	}
+}
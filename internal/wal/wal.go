@@ -0,0 +1,204 @@
+// Package wal is a write-ahead log file of JSON records, it's shared by the file stores
+//
+// every record is appended to the file and synced before it returns, and applied to a State
+// kept in memory, the log is compacted to the values of the State when it's opened
+// and when it grows too large
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Record is a line of the log
+type Record struct {
+	// Value is the JSON of a value put, its key is a field of it
+	Value json.RawMessage `json:",omitempty"`
+
+	// Deleted is the key of a value deleted
+	Deleted string `json:",omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler,
+// Value is also read from "Message" of logs written by the stores before they shared the log
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Value   json.RawMessage
+		Message json.RawMessage
+		Deleted string
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	r.Value, r.Deleted = v.Value, v.Deleted
+	if r.Value == nil {
+		r.Value = v.Message
+	}
+	return nil
+}
+
+// State is the in-memory state of a Log
+type State interface {
+	// Apply a record appended or read from the file,
+	// a record which fails to be applied on open is ignored
+	Apply(r Record) error
+
+	// Len returns number of values
+	Len() int
+
+	// Values returns all values, the log is rewritten with them by compaction
+	Values() []interface{}
+}
+
+// Log is a write-ahead log file
+// it's not concurrent safe
+type Log struct {
+	path    string
+	f       *os.File
+	state   State
+	records int
+
+	// dirty is true if the file may end with a partially written line,
+	// it's compacted before the next record is appended
+	dirty bool
+}
+
+// Open the log file of path and apply its records to state, it's created if not exists
+// a partially written last line, e.g. the process crashed during a write, is ignored
+func Open(path string, state State) (*Log, error) {
+	l := &Log{path: path, state: state}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// partially written line
+			continue
+		}
+		state.Apply(r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// compact rewrites the log with only values of the state
+func (l *Log) compact() error {
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	values := l.state.Values()
+	w := bufio.NewWriter(f)
+	for _, v := range values {
+		value, err := json.Marshal(v)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		data, err := json.Marshal(Record{Value: value})
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if l.f != nil {
+		l.f.Close()
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+	l.f, err = os.OpenFile(l.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	l.records = len(values)
+	l.dirty = false
+	return nil
+}
+
+func (l *Log) append(r Record) error {
+	if l.dirty {
+		if err := l.compact(); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = l.f.Write(append(data, '\n'))
+	if err == nil {
+		err = l.f.Sync()
+	}
+	if err != nil {
+		// rewrite the log to drop the partially written line
+		l.dirty = true
+		if cerr := l.compact(); cerr != nil {
+			return fmt.Errorf("wal: %v, compaction after it failed: %v", err, cerr)
+		}
+		return err
+	}
+	if err := l.state.Apply(r); err != nil {
+		return err
+	}
+	l.records++
+
+	if l.records > 1024 && l.records > 2*l.state.Len() {
+		return l.compact()
+	}
+	return nil
+}
+
+// Put appends a record of value v
+func (l *Log) Put(v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return l.append(Record{Value: value})
+}
+
+// Delete appends a record of the value of key deleted
+func (l *Log) Delete(key string) error {
+	return l.append(Record{Deleted: key})
+}
+
+// Records returns number of records in the file
+func (l *Log) Records() int {
+	return l.records
+}
+
+// Close the log file
+func (l *Log) Close() error {
+	return l.f.Close()
+}
@@ -0,0 +1,134 @@
+package wal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testValue struct {
+	ID string
+	N  int
+}
+
+type testState map[string]testValue
+
+func (s testState) Apply(r Record) error {
+	if r.Value != nil {
+		var v testValue
+		if err := json.Unmarshal(r.Value, &v); err != nil {
+			return err
+		}
+		s[v.ID] = v
+	}
+	if r.Deleted != "" {
+		delete(s, r.Deleted)
+	}
+	return nil
+}
+
+func (s testState) Len() int {
+	return len(s)
+}
+
+func (s testState) Values() []interface{} {
+	var values []interface{}
+	for _, v := range s {
+		values = append(values, v)
+	}
+	return values
+}
+
+func tempPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "test.log")
+}
+
+func TestLog_Reopen(t *testing.T) {
+	path := tempPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	l, err := Open(path, testState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []testValue{{ID: "a"}, {ID: "b"}, {ID: "a", N: 1}} {
+		if err := l.Put(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Delete("b")
+	l.Close()
+
+	// partially written line, and a value of another type
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"Value":"c"}` + "\n" + `{"Value":{"ID":"d"`)
+	f.Close()
+
+	state := testState{}
+	l, err = Open(path, state)
+	if err != nil {
+		t.Fatalf("Open err: %v", err)
+	}
+	defer l.Close()
+	if len(state) != 1 || state["a"].N != 1 || l.Records() != 1 {
+		t.Errorf("state: %v, records: %d", state, l.Records())
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != `{"Value":{"ID":"a","N":1}}`+"\n" {
+		t.Errorf("compacted: %s", data)
+	}
+}
+
+func TestLog_OpenMessageRecords(t *testing.T) {
+	path := tempPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// records of the stores before they shared the log
+	data := `{"Message":{"ID":"a"}}` + "\n" + `{"Message":{"ID":"b","N":1}}` + "\n" + `{"Deleted":"a"}` + "\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	state := testState{}
+	l, err := Open(path, state)
+	if err != nil {
+		t.Fatalf("Open err: %v", err)
+	}
+	defer l.Close()
+	if len(state) != 1 || state["b"].N != 1 {
+		t.Errorf("state: %v", state)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != `{"Value":{"ID":"b","N":1}}`+"\n" {
+		t.Errorf("compacted: %s", data)
+	}
+}
+
+func TestLog_Compact(t *testing.T) {
+	path := tempPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	state := testState{}
+	l, err := Open(path, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 2000; i++ {
+		if err := l.Put(testValue{ID: "a", N: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.Records() > 1024 {
+		t.Errorf("records: %d", l.Records())
+	}
+	data, _ := ioutil.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != l.Records() || state["a"].N != 1999 {
+		t.Errorf("lines: %d, records: %d, state: %v", n, l.Records(), state)
+	}
+}
@@ -0,0 +1,295 @@
+// Package otp sends one-time verification codes by a verification template,
+// and verifies codes entered by users
+//
+// codes are stored as HMAC in a Store with TTL, a code can be verified
+// at most MaxAttempts times, and it's invalidated once it's verified
+package otp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+const (
+	// DefaultLength is default number of digits of a code
+	DefaultLength = 6
+
+	// MinLength is lower limit of digits of a code
+	MinLength = 4
+
+	// MaxLength is upper limit of digits of a code
+	MaxLength = 10
+
+	// DefaultTTL is default time a code is valid after it's sent
+	DefaultTTL = 5 * time.Minute
+
+	// DefaultMaxAttempts is default upper limit of verifications of a code
+	DefaultMaxAttempts = 5
+
+	// DefaultCooldown is default interval between two codes sent to a phone number
+	DefaultCooldown = time.Minute
+
+	// DefaultCodeParam is default key of the code in TemplateParam
+	DefaultCodeParam = "code"
+)
+
+var (
+	// ErrExpired is returned from Verify if the code is expired
+	ErrExpired = errors.New("otp: code expired")
+
+	// ErrTooManyAttempts is returned from Verify if the code is verified more than MaxAttempts times
+	ErrTooManyAttempts = errors.New("otp: too many attempts")
+
+	// ErrMismatch is returned from Verify if the code is wrong
+	ErrMismatch = errors.New("otp: code mismatch")
+
+	// ErrNoSecret is returned from New if Secret is empty but Store is not a MemoryStore
+	ErrNoSecret = errors.New("otp: Secret is required by a Store other than MemoryStore")
+)
+
+// CooldownError is returned from Send if the previous code is sent less than Cooldown ago
+type CooldownError struct {
+	// RetryAfter is the time until a new code can be sent
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return "otp: a code is sent recently, retry after " + e.RetryAfter.String()
+}
+
+// Config of OTP
+type Config struct {
+	Client sms.Client
+
+	// Options are applied to every action "SendSms"
+	Options []sms.Option
+
+	SignName string
+
+	// TemplateCode of a verification template, e.g. "您的验证码为：${code}"
+	TemplateCode string
+
+	// CodeParam is the key of the code in TemplateParam, default DefaultCodeParam
+	CodeParam string
+
+	// TTLParam is optional, if it's set, minutes of TTL are sent as
+	// the value of the key in TemplateParam
+	TTLParam string
+
+	// Length is number of digits of a code, in [MinLength, MaxLength], default DefaultLength
+	Length int
+
+	// TTL of a code, default DefaultTTL
+	TTL time.Duration
+
+	// MaxAttempts is upper limit of verifications of a code, default DefaultMaxAttempts
+	MaxAttempts int
+
+	// Cooldown is the interval between two codes sent to a phone number, default DefaultCooldown
+	Cooldown time.Duration
+
+	// Scope is the prefix of keys of Store, e.g. "login",
+	// codes of OTPs of different scopes are independent in a shared Store
+	Scope string
+
+	// Store of codes, default a MemoryStore
+	Store Store
+
+	// Secret is the key of HMAC of codes, it must be the same for processes sharing a Store,
+	// it's required unless Store is a MemoryStore, for which a random one is generated if it's empty
+	Secret []byte
+
+	// Now returns the current time, default time.Now
+	Now func() time.Time
+}
+
+// OTP sends and verifies codes
+// it's concurrent safe
+type OTP struct {
+	conf Config
+}
+
+// New init an OTP
+// ErrNoSecret is returned if Secret is empty but Store is not a MemoryStore
+func New(conf Config) (*OTP, error) {
+	if conf.CodeParam == "" {
+		conf.CodeParam = DefaultCodeParam
+	}
+	if conf.Length == 0 {
+		conf.Length = DefaultLength
+	}
+	if conf.Length < MinLength {
+		conf.Length = MinLength
+	}
+	if conf.Length > MaxLength {
+		conf.Length = MaxLength
+	}
+	if conf.TTL <= 0 {
+		conf.TTL = DefaultTTL
+	}
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = DefaultMaxAttempts
+	}
+	if conf.Cooldown <= 0 {
+		conf.Cooldown = DefaultCooldown
+	}
+	if conf.Store == nil {
+		conf.Store = NewMemoryStore()
+	}
+	if _, ok := conf.Store.(*MemoryStore); !ok && len(conf.Secret) == 0 {
+		return nil, ErrNoSecret
+	}
+	if len(conf.Secret) == 0 {
+		conf.Secret = make([]byte, 32)
+		if _, err := rand.Read(conf.Secret); err != nil {
+			panic(err)
+		}
+	}
+	if conf.Now == nil {
+		conf.Now = time.Now
+	}
+	return &OTP{conf: conf}, nil
+}
+
+// Sent is the result of Send
+type Sent struct {
+	PhoneNumber phone.Number
+	BizID       string
+	RequestID   string
+	ExpiresAt   time.Time
+
+	// ResendAt is the time after which a new code can be sent
+	ResendAt time.Time
+}
+
+// key returns the key of Store of n
+func (o *OTP) key(n phone.Number) string {
+	return o.conf.Scope + ":" + n.String()
+}
+
+// hash returns the HMAC of code of key
+func (o *OTP) hash(key, code string) string {
+	mac := hmac.New(sha256.New, o.conf.Secret)
+	mac.Write([]byte(key))
+	mac.Write([]byte{0})
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generate a code of random digits
+func (o *OTP) generate() (string, error) {
+	code := make([]byte, o.conf.Length)
+	ten := big.NewInt(10)
+	for i := range code {
+		d, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + d.Int64())
+	}
+	return string(code), nil
+}
+
+// Send a new code to phoneNumber, the previous code of phoneNumber is replaced
+// a *CooldownError is returned if the previous code is sent less than Cooldown ago,
+// the code is invalidated if it fails to be sent, so that it can be sent again at once
+func (o *OTP) Send(ctx context.Context, phoneNumber string) (*Sent, error) {
+	n, err := phone.Parse(phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	code, err := o.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	key := o.key(n)
+	now := o.conf.Now()
+	c := Code{Hash: o.hash(key, code), SentAt: now, ExpiresAt: now.Add(o.conf.TTL)}
+	prev, err := o.conf.Store.Issue(key, c, now.Add(-o.conf.Cooldown))
+	if err == ErrCooldown {
+		return nil, &CooldownError{RetryAfter: prev.SentAt.Add(o.conf.Cooldown).Sub(now)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sms.TemplateParam{o.conf.CodeParam: code}
+	if o.conf.TTLParam != "" {
+		tp[o.conf.TTLParam] = strconv.Itoa(int(o.conf.TTL / time.Minute))
+	}
+	extOpts := append(append([]sms.Option(nil), o.conf.Options...), sms.ContextOption(ctx))
+	opts, err := sms.NewSendAction(o.conf.Client, sms.SendSmsParams{
+		Recipients:    []phone.Number{n},
+		SignName:      o.conf.SignName,
+		TemplateCode:  o.conf.TemplateCode,
+		TemplateParam: tp,
+	}).Do(extOpts...)
+	if err == nil {
+		err = opts.Response().Err()
+	}
+	if err != nil {
+		o.conf.Store.Invalidate(key, c.Hash)
+		return nil, err
+	}
+
+	res := opts.Response()
+	return &Sent{
+		PhoneNumber: n,
+		BizID:       res.BizID,
+		RequestID:   res.RequestID,
+		ExpiresAt:   c.ExpiresAt,
+		ResendAt:    now.Add(o.conf.Cooldown),
+	}, nil
+}
+
+// Verify code entered for phoneNumber, nil is returned if it matches, and the code is invalidated
+// ErrNotFound is returned if no code is sent or it's verified already,
+// ErrExpired, ErrTooManyAttempts or ErrMismatch is returned otherwise,
+// every call counts as an attempt, whether the code matches or not
+func (o *OTP) Verify(phoneNumber, code string) error {
+	n, err := phone.Parse(phoneNumber)
+	if err != nil {
+		return err
+	}
+	key := o.key(n)
+	c, err := o.conf.Store.Attempt(key)
+	if err != nil {
+		return err
+	}
+
+	if !o.conf.Now().Before(c.ExpiresAt) {
+		if _, err := o.conf.Store.Invalidate(key, c.Hash); err != nil {
+			return err
+		}
+		return ErrExpired
+	}
+	if c.Attempts > o.conf.MaxAttempts {
+		return ErrTooManyAttempts
+	}
+	// hashes are compared instead of codes, both have the same length
+	if subtle.ConstantTimeCompare([]byte(o.hash(key, code)), []byte(c.Hash)) != 1 {
+		return ErrMismatch
+	}
+
+	// only one of concurrent verifications of the same code succeeds
+	ok, err := o.conf.Store.Invalidate(key, c.Hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
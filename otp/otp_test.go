@@ -0,0 +1,233 @@
+package otp
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+// clock is a manual clock of Config.Now
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestOTP(t *testing.T, conf Config) (*OTP, *smstest.Server, *clock) {
+	srv := smstest.NewServer(smstest.Config{FlowControl: &smstest.FlowControl{}, SignNames: []string{"阿里云短信测试专用"}})
+	conf.Client = sms.NewClient(sms.Config{
+		AccessKeyID:  smstest.DefaultAccessKeyID,
+		AccessSecret: smstest.DefaultAccessSecret,
+		Endpoint:     srv.Endpoint(),
+	})
+	if conf.SignName == "" {
+		conf.SignName = "阿里云短信测试专用"
+	}
+	conf.TemplateCode = "SMS_71390007"
+	clk := &clock{now: time.Now()}
+	conf.Now = clk.Now
+	o, err := New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o, srv, clk
+}
+
+// lastCode returns the code of the last message sent to the fake
+func lastCode(t *testing.T, srv *smstest.Server) string {
+	messages := srv.Messages()
+	if len(messages) == 0 {
+		t.Fatal("no message is sent")
+	}
+	return messages[len(messages)-1].TemplateParam[DefaultCodeParam]
+}
+
+func TestOTP_SendVerify(t *testing.T) {
+	o, srv, _ := newTestOTP(t, Config{TTLParam: "minutes"})
+	defer srv.Close()
+
+	sent, err := o.Send(context.Background(), "+86 153 0000 0001")
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	m := srv.Messages()[0]
+	if m.PhoneNumber != "15300000001" || m.BizID != sent.BizID || m.TemplateParam["minutes"] != "5" {
+		t.Errorf("Message: %+v", m)
+	}
+	code := lastCode(t, srv)
+	if len(code) != DefaultLength {
+		t.Errorf("code: %q", code)
+	}
+
+	if err := o.Verify("15300000001", code); err != nil {
+		t.Errorf("Verify err: %v", err)
+	}
+	// the code is invalidated after success
+	if err := o.Verify("15300000001", code); err != ErrNotFound {
+		t.Errorf("Verify again err: %v", err)
+	}
+}
+
+func TestOTP_MaxAttempts(t *testing.T) {
+	o, srv, _ := newTestOTP(t, Config{MaxAttempts: 2, Length: 8})
+	defer srv.Close()
+
+	if _, err := o.Send(context.Background(), "15300000001"); err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	code := lastCode(t, srv)
+	if len(code) != 8 {
+		t.Errorf("code: %q", code)
+	}
+	for i := 0; i < 2; i++ {
+		if err := o.Verify("15300000001", "wrong"); err != ErrMismatch {
+			t.Errorf("Verify err: %v", err)
+		}
+	}
+	if err := o.Verify("15300000001", code); err != ErrTooManyAttempts {
+		t.Errorf("Verify err: %v", err)
+	}
+}
+
+func TestOTP_Expired(t *testing.T) {
+	o, srv, clk := newTestOTP(t, Config{TTL: time.Minute})
+	defer srv.Close()
+
+	if _, err := o.Send(context.Background(), "15300000001"); err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	clk.Add(time.Minute)
+	if err := o.Verify("15300000001", lastCode(t, srv)); err != ErrExpired {
+		t.Errorf("Verify err: %v", err)
+	}
+	if err := o.Verify("15300000001", lastCode(t, srv)); err != ErrNotFound {
+		t.Errorf("Verify err: %v", err)
+	}
+}
+
+func TestOTP_Cooldown(t *testing.T) {
+	o, srv, clk := newTestOTP(t, Config{Cooldown: time.Minute})
+	defer srv.Close()
+
+	if _, err := o.Send(context.Background(), "15300000001"); err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	first := lastCode(t, srv)
+
+	clk.Add(20 * time.Second)
+	_, err := o.Send(context.Background(), "15300000001")
+	if e, ok := err.(*CooldownError); !ok || e.RetryAfter != 40*time.Second {
+		t.Fatalf("Send err: %v", err)
+	}
+	// other numbers are not affected
+	if _, err := o.Send(context.Background(), "15300000002"); err != nil {
+		t.Errorf("Send err: %v", err)
+	}
+
+	clk.Add(40 * time.Second)
+	if _, err := o.Send(context.Background(), "15300000001"); err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	second := lastCode(t, srv)
+	// the previous code is replaced
+	if first != second {
+		if err := o.Verify("15300000001", first); err != ErrMismatch {
+			t.Errorf("Verify err: %v", err)
+		}
+	}
+	if err := o.Verify("15300000001", second); err != nil {
+		t.Errorf("Verify err: %v", err)
+	}
+}
+
+func TestOTP_SendFailed(t *testing.T) {
+	o, srv, _ := newTestOTP(t, Config{SignName: "unknown"})
+	defer srv.Close()
+
+	_, err := o.Send(context.Background(), "15300000001")
+	if e, ok := err.(*sms.Error); !ok || e.Code != "isv.SMS_SIGNATURE_ILLEGAL" {
+		t.Fatalf("Send err: %v", err)
+	}
+	// the code is invalidated, so that it's not in cooldown
+	if err := o.Verify("15300000001", "000000"); err != ErrNotFound {
+		t.Errorf("Verify err: %v", err)
+	}
+	if _, err := o.Send(context.Background(), "15300000001"); err == nil {
+		t.Errorf("Send err: %v", err)
+	} else if _, ok := err.(*CooldownError); ok {
+		t.Errorf("Send err: %v", err)
+	}
+}
+
+func TestOTP_Scope(t *testing.T) {
+	store := NewMemoryStore()
+	login, srv, _ := newTestOTP(t, Config{Scope: "login", Store: store, Secret: []byte("secret")})
+	defer srv.Close()
+	reset, _, _ := newTestOTP(t, Config{Scope: "reset", Store: store, Secret: []byte("secret")})
+
+	if _, err := login.Send(context.Background(), "15300000001"); err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	if err := reset.Verify("15300000001", lastCode(t, srv)); err != ErrNotFound {
+		t.Errorf("Verify of another scope err: %v", err)
+	}
+	if err := login.Verify("15300000001", lastCode(t, srv)); err != nil {
+		t.Errorf("Verify err: %v", err)
+	}
+}
+
+func TestNew_Secret(t *testing.T) {
+	if _, err := New(Config{Store: NewSQLStore(nil, "otp", nil)}); err != ErrNoSecret {
+		t.Errorf("New of a shared Store without Secret: %v", err)
+	}
+	if _, err := New(Config{Store: NewSQLStore(nil, "otp", nil), Secret: []byte("secret")}); err != nil {
+		t.Errorf("New err: %v", err)
+	}
+	if _, err := New(Config{}); err != nil {
+		t.Errorf("New of the default MemoryStore: %v", err)
+	}
+}
+
+func TestOTP_ConcurrentVerify(t *testing.T) {
+	o, srv, _ := newTestOTP(t, Config{MaxAttempts: 100})
+	defer srv.Close()
+
+	if _, err := o.Send(context.Background(), "15300000001"); err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	code := lastCode(t, srv)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if o.Verify("15300000001", code) == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		t.Errorf("%d verifications succeeded", succeeded)
+	}
+}
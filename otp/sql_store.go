@@ -0,0 +1,137 @@
+package otp
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+)
+
+// QuestionPlaceholder returns "?" as bind parameter, e.g. MySQL and SQLite
+func QuestionPlaceholder(int) string {
+	return "?"
+}
+
+// DollarPlaceholder returns "$n" as bind parameter, e.g. PostgreSQL
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// SQLStore is a Store of database/sql, it's shared by processes of the same database
+// times are stored as unix nanoseconds
+type SQLStore struct {
+	db    *sql.DB
+	table string
+
+	// placeholder returns the bind parameter of the n-th argument, starting from 1
+	placeholder func(n int) string
+}
+
+// NewSQLStore init a SQLStore of table,
+// placeholder returns the bind parameter of the n-th argument, starting from 1,
+// QuestionPlaceholder is used if it's nil
+func NewSQLStore(db *sql.DB, table string, placeholder func(n int) string) *SQLStore {
+	if placeholder == nil {
+		placeholder = QuestionPlaceholder
+	}
+	return &SQLStore{db: db, table: table, placeholder: placeholder}
+}
+
+// CreateTableSQL returns the statement to create the table of SQLStore
+func (s *SQLStore) CreateTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS ` + s.table + ` (
+	id VARCHAR(128) NOT NULL PRIMARY KEY,
+	hash VARCHAR(64) NOT NULL,
+	sent_at BIGINT NOT NULL,
+	expires_at BIGINT NOT NULL,
+	attempts INTEGER NOT NULL
+)`
+}
+
+// DeleteExpiredSQL returns the statement to delete codes expired before the first argument
+// and sent before the second one, both in unix nanoseconds, e.g. in a periodic job
+func (s *SQLStore) DeleteExpiredSQL() string {
+	return "DELETE FROM " + s.table + " WHERE expires_at <= " + s.placeholder(1) + " AND sent_at <= " + s.placeholder(2)
+}
+
+// Issue implements Store
+// the previous code is replaced in one conditional UPDATE, so that concurrent
+// Issue of the same key from processes can not both pass the cooldown
+func (s *SQLStore) Issue(key string, c Code, since time.Time) (Code, error) {
+	res, err := s.db.Exec("UPDATE "+s.table+" SET hash = "+s.placeholder(1)+", sent_at = "+s.placeholder(2)+
+		", expires_at = "+s.placeholder(3)+", attempts = 0 WHERE id = "+s.placeholder(4)+" AND sent_at <= "+s.placeholder(5),
+		c.Hash, c.SentAt.UnixNano(), c.ExpiresAt.UnixNano(), key, since.UnixNano())
+	if err != nil {
+		return Code{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return Code{}, err
+	} else if n > 0 {
+		return Code{}, nil
+	}
+
+	_, insertErr := s.db.Exec("INSERT INTO "+s.table+" (id, hash, sent_at, expires_at, attempts) VALUES ("+
+		s.placeholder(1)+", "+s.placeholder(2)+", "+s.placeholder(3)+", "+s.placeholder(4)+", 0)",
+		key, c.Hash, c.SentAt.UnixNano(), c.ExpiresAt.UnixNano())
+	if insertErr == nil {
+		return Code{}, nil
+	}
+	// the insert fails on the primary key if the code exists and it's in cooldown,
+	// or it's inserted concurrently
+	prev, err := s.get(s.db, key)
+	if err == ErrNotFound {
+		return Code{}, insertErr
+	}
+	if err != nil {
+		return Code{}, err
+	}
+	return prev, ErrCooldown
+}
+
+// Attempt implements Store
+func (s *SQLStore) Attempt(key string) (Code, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Code{}, err
+	}
+	defer tx.Rollback()
+
+	// the row is locked by the update until commit, so the code read is the one attempted
+	if _, err := tx.Exec("UPDATE "+s.table+" SET attempts = attempts + 1 WHERE id = "+s.placeholder(1), key); err != nil {
+		return Code{}, err
+	}
+	c, err := s.get(tx, key)
+	if err != nil {
+		return Code{}, err
+	}
+	return c, tx.Commit()
+}
+
+// Invalidate implements Store
+func (s *SQLStore) Invalidate(key, hash string) (bool, error) {
+	res, err := s.db.Exec("DELETE FROM "+s.table+" WHERE id = "+s.placeholder(1)+" AND hash = "+s.placeholder(2), key, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (s *SQLStore) get(q queryer, key string) (Code, error) {
+	var c Code
+	var sentAt, expiresAt int64
+	err := q.QueryRow("SELECT hash, sent_at, expires_at, attempts FROM "+s.table+" WHERE id = "+s.placeholder(1), key).
+		Scan(&c.Hash, &sentAt, &expiresAt, &c.Attempts)
+	if err == sql.ErrNoRows {
+		return Code{}, ErrNotFound
+	}
+	if err != nil {
+		return Code{}, err
+	}
+	c.SentAt = time.Unix(0, sentAt)
+	c.ExpiresAt = time.Unix(0, expiresAt)
+	return c, nil
+}
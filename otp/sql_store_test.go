@@ -0,0 +1,155 @@
+//go:build sqlite
+// +build sqlite
+
+package otp
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// tempSQLStores returns n SQLStores of the same database, like processes sharing it
+func tempSQLStores(t *testing.T, n int) ([]*SQLStore, func()) {
+	dir, err := ioutil.TempDir("", "otp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dbs []*sql.DB
+	closeAll := func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+		os.RemoveAll(dir)
+	}
+	var stores []*SQLStore
+	for i := 0; i < n; i++ {
+		db, err := sql.Open("sqlite3", "file:"+filepath.Join(dir, "otp.db")+"?_busy_timeout=5000")
+		if err != nil {
+			closeAll()
+			t.Fatal(err)
+		}
+		dbs = append(dbs, db)
+		stores = append(stores, NewSQLStore(db, "otp", DollarPlaceholder))
+	}
+	if _, err := dbs[0].Exec(stores[0].CreateTableSQL()); err != nil {
+		closeAll()
+		t.Fatal(err)
+	}
+	return stores, closeAll
+}
+
+func TestSQLStore(t *testing.T) {
+	stores, closeAll := tempSQLStores(t, 1)
+	defer closeAll()
+	s := stores[0]
+	now := time.Unix(0, time.Now().UnixNano())
+
+	if _, err := s.Attempt("k"); err != ErrNotFound {
+		t.Errorf("Attempt err: %v", err)
+	}
+	c := Code{Hash: "a", SentAt: now, ExpiresAt: now.Add(time.Minute)}
+	if _, err := s.Issue("k", c, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Issue err: %v", err)
+	}
+	prev, err := s.Issue("k", Code{Hash: "b", SentAt: now.Add(time.Second)}, now.Add(-59*time.Second))
+	if err != ErrCooldown || prev.Hash != "a" || !prev.SentAt.Equal(now) {
+		t.Errorf("Issue in cooldown: %+v %v", prev, err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if c, err := s.Attempt("k"); err != nil || c.Attempts != i || c.Hash != "a" || !c.ExpiresAt.Equal(now.Add(time.Minute)) {
+			t.Errorf("Attempt: %+v %v", c, err)
+		}
+	}
+	// the code after cooldown replaces the previous one
+	later := now.Add(time.Minute)
+	if _, err := s.Issue("k", Code{Hash: "b", SentAt: later, ExpiresAt: later.Add(time.Minute)}, now); err != nil {
+		t.Fatalf("Issue err: %v", err)
+	}
+	if c, err := s.Attempt("k"); err != nil || c.Attempts != 1 || c.Hash != "b" {
+		t.Errorf("Attempt: %+v %v", c, err)
+	}
+
+	if ok, err := s.Invalidate("k", "a"); ok || err != nil {
+		t.Errorf("Invalidate of another hash: %v %v", ok, err)
+	}
+	if ok, err := s.Invalidate("k", "b"); !ok || err != nil {
+		t.Errorf("Invalidate: %v %v", ok, err)
+	}
+	if _, err := s.Attempt("k"); err != ErrNotFound {
+		t.Errorf("Attempt err: %v", err)
+	}
+
+	if _, err := s.db.Exec(s.DeleteExpiredSQL(), later.UnixNano(), later.UnixNano()); err != nil {
+		t.Errorf("DeleteExpiredSQL err: %v", err)
+	}
+}
+
+func TestSQLStore_Concurrent(t *testing.T) {
+	const n = 8
+	stores, closeAll := tempSQLStores(t, 2)
+	defer closeAll()
+	now := time.Unix(0, time.Now().UnixNano())
+
+	// concurrent Issue of processes, only one of them passes the cooldown,
+	// both of the first code inserted and the next one updated
+	for round, sentAt := range []time.Time{now, now.Add(time.Minute)} {
+		since := sentAt.Add(-time.Minute)
+		var wg sync.WaitGroup
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(s *SQLStore) {
+				defer wg.Done()
+				_, err := s.Issue("k", Code{Hash: "a", SentAt: sentAt, ExpiresAt: sentAt.Add(time.Minute)}, since)
+				errs <- err
+			}(stores[i%len(stores)])
+		}
+		wg.Wait()
+		close(errs)
+
+		issued := 0
+		for err := range errs {
+			if err == nil {
+				issued++
+			} else if err != ErrCooldown {
+				t.Errorf("Issue err: %v", err)
+			}
+		}
+		if issued != 1 {
+			t.Errorf("round %d: %d codes issued", round, issued)
+		}
+	}
+
+	// every attempt reads the attempts it increments
+	var wg sync.WaitGroup
+	attempts := make(chan int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(s *SQLStore) {
+			defer wg.Done()
+			c, err := s.Attempt("k")
+			if err != nil {
+				t.Errorf("Attempt err: %v", err)
+			}
+			attempts <- c.Attempts
+		}(stores[i%len(stores)])
+	}
+	wg.Wait()
+	close(attempts)
+
+	seen := make(map[int]bool)
+	for a := range attempts {
+		if seen[a] || a < 1 || a > n {
+			t.Errorf("attempts %d is read twice or out of range", a)
+		}
+		seen[a] = true
+	}
+}
@@ -0,0 +1,125 @@
+package otp
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned if there is no code of the phone number,
+	// e.g. it's never sent, or it's already verified
+	ErrNotFound = errors.New("otp: code not found")
+
+	// ErrCooldown is returned from Store.Issue if the previous code is sent too recently
+	ErrCooldown = errors.New("otp: cooldown")
+)
+
+// Code is an issued code of a phone number
+type Code struct {
+	// Hash of the code, the code itself is never stored
+	Hash string
+
+	SentAt    time.Time
+	ExpiresAt time.Time
+
+	// Attempts is number of verifications of the code
+	Attempts int
+}
+
+// Store persists issued codes
+// implementations must be concurrent safe, and every method must be atomic
+// if the Store is shared by processes
+type Store interface {
+	// Issue saves c as the code of key, the previous code is replaced,
+	// unless it's sent after since, then it's returned with ErrCooldown and c is not saved
+	Issue(key string, c Code, since time.Time) (Code, error)
+
+	// Attempt increases Attempts of the code of key and returns the code,
+	// ErrNotFound is returned if there is no code of key
+	Attempt(key string) (Code, error)
+
+	// Invalidate deletes the code of key if its Hash is hash,
+	// ok is false if the code is replaced or deleted already
+	Invalidate(key, hash string) (ok bool, err error)
+}
+
+// MemoryStore is a Store in memory, codes are lost if the process exits,
+// and it's not shared by processes
+// expired codes are swept when the number of codes doubles
+type MemoryStore struct {
+	mu        sync.Mutex
+	codes     map[string]Code
+	sweepSize int
+}
+
+// minSweepSize is lower limit of number of codes before the first sweep
+const minSweepSize = 1024
+
+// NewMemoryStore init an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{codes: make(map[string]Code), sweepSize: minSweepSize}
+}
+
+// Issue implements Store
+func (s *MemoryStore) Issue(key string, c Code, since time.Time) (Code, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.codes[key]; ok && prev.SentAt.After(since) {
+		return prev, ErrCooldown
+	}
+	s.codes[key] = c
+
+	if len(s.codes) >= s.sweepSize {
+		s.sweep(c.SentAt, since)
+		s.sweepSize = 2 * len(s.codes)
+		if s.sweepSize < minSweepSize {
+			s.sweepSize = minSweepSize
+		}
+	}
+	return Code{}, nil
+}
+
+// sweep deletes codes expired before now and out of cooldown since
+func (s *MemoryStore) sweep(now, since time.Time) {
+	for key, c := range s.codes {
+		if !now.Before(c.ExpiresAt) && !c.SentAt.After(since) {
+			delete(s.codes, key)
+		}
+	}
+}
+
+// Attempt implements Store
+func (s *MemoryStore) Attempt(key string) (Code, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.codes[key]
+	if !ok {
+		return Code{}, ErrNotFound
+	}
+	c.Attempts++
+	s.codes[key] = c
+	return c, nil
+}
+
+// Invalidate implements Store
+func (s *MemoryStore) Invalidate(key, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.codes[key]; !ok || c.Hash != hash {
+		return false, nil
+	}
+	delete(s.codes, key)
+	return true, nil
+}
+
+// Len returns number of codes, including expired ones not swept yet
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.codes)
+}
@@ -0,0 +1,53 @@
+package otp
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+
+	if _, err := s.Attempt("k"); err != ErrNotFound {
+		t.Errorf("Attempt err: %v", err)
+	}
+	c := Code{Hash: "a", SentAt: now, ExpiresAt: now.Add(time.Minute)}
+	if _, err := s.Issue("k", c, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Issue err: %v", err)
+	}
+	prev, err := s.Issue("k", Code{Hash: "b", SentAt: now.Add(time.Second)}, now.Add(-59*time.Second))
+	if err != ErrCooldown || prev.Hash != "a" {
+		t.Errorf("Issue in cooldown: %+v %v", prev, err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if c, err := s.Attempt("k"); err != nil || c.Attempts != i || c.Hash != "a" {
+			t.Errorf("Attempt: %+v %v", c, err)
+		}
+	}
+	if ok, err := s.Invalidate("k", "b"); ok || err != nil {
+		t.Errorf("Invalidate of another hash: %v %v", ok, err)
+	}
+	if ok, err := s.Invalidate("k", "a"); !ok || err != nil {
+		t.Errorf("Invalidate: %v %v", ok, err)
+	}
+	if _, err := s.Attempt("k"); err != ErrNotFound {
+		t.Errorf("Attempt err: %v", err)
+	}
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	for i := 0; i < minSweepSize-1; i++ {
+		s.Issue(strconv.Itoa(i), Code{SentAt: now, ExpiresAt: now.Add(time.Minute)}, now.Add(-time.Minute))
+	}
+
+	later := now.Add(time.Hour)
+	s.Issue("last", Code{SentAt: later, ExpiresAt: later.Add(time.Minute)}, later.Add(-time.Minute))
+	if n := s.Len(); n != 1 {
+		t.Errorf("Len after sweep: %d", n)
+	}
+}
@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/internal/wal"
+)
+
+// messageState is the wal.State of messages by ID
+type messageState map[string]Message
+
+// Apply implements wal.State
+func (s messageState) Apply(r wal.Record) error {
+	if r.Value != nil {
+		var m Message
+		if err := json.Unmarshal(r.Value, &m); err != nil {
+			return err
+		}
+		s[m.ID] = m
+	}
+	if r.Deleted != "" {
+		delete(s, r.Deleted)
+	}
+	return nil
+}
+
+// Len implements wal.State
+func (s messageState) Len() int {
+	return len(s)
+}
+
+// Values implements wal.State
+func (s messageState) Values() []interface{} {
+	values := make([]interface{}, 0, len(s))
+	for _, m := range s {
+		values = append(values, m)
+	}
+	return values
+}
+
+// FileStore is a Store of a write-ahead log file,
+// every change is appended to the file and synced before it returns,
+// all messages are kept in memory, the log is compacted when it's opened
+// and when it grows too large
+type FileStore struct {
+	mu       sync.Mutex
+	log      *wal.Log
+	messages messageState
+}
+
+// OpenFileStore opens the log file of path, it's created if not exists
+// a partially written last line, e.g. the process crashed during a write, is ignored
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{messages: make(messageState)}
+	log, err := wal.Open(path, s.messages)
+	if err != nil {
+		return nil, err
+	}
+	s.log = log
+	return s, nil
+}
+
+// Put implements Store
+func (s *FileStore) Put(m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.log.Put(m)
+}
+
+// Get implements Store
+func (s *FileStore) Get(id string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.messages[id]
+	if !ok {
+		return Message{}, ErrNotFound
+	}
+	return m, nil
+}
+
+// Delete implements Store
+func (s *FileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.messages[id]; !ok {
+		return nil
+	}
+	return s.log.Delete(id)
+}
+
+// Claim implements Store
+func (s *FileStore) Claim(now, until time.Time, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Message
+	for _, m := range s.messages {
+		if m.State == Pending && !m.NextAttempt.After(now) {
+			due = append(due, m)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].NextAttempt = until
+		if err := s.log.Put(due[i]); err != nil {
+			return due[:i], err
+		}
+	}
+	return due, nil
+}
+
+// List implements Store
+func (s *FileStore) List(state State) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []Message
+	for _, m := range s.messages {
+		if m.State == state {
+			messages = append(messages, m)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, nil
+}
+
+// Close the log file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.log.Close()
+}
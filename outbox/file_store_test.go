@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+func tempStore(t *testing.T) (*FileStore, string) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "outbox.log")
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	s, path := tempStore(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	now := time.Now()
+	params := sms.SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+	for _, m := range []Message{
+		{ID: "a", Params: params, State: Pending, NextAttempt: now, CreatedAt: now},
+		{ID: "b", Params: params, State: Pending, NextAttempt: now.Add(time.Hour), CreatedAt: now.Add(time.Second)},
+		{ID: "c", Params: params, State: Dead, CreatedAt: now},
+	} {
+		if err := s.Put(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, _ := s.Get("a")
+	m.State = Sent
+	m.BizID = "B"
+	s.Put(m)
+	s.Delete("c")
+	s.Close()
+
+	// partially written line
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"Value":{"ID":"d"`)
+	f.Close()
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore err: %v", err)
+	}
+	defer s.Close()
+
+	if m, err := s.Get("a"); err != nil || m.State != Sent || m.BizID != "B" || m.Params.PhoneNumbers != "15300000001" {
+		t.Errorf("Get: %+v, %v", m, err)
+	}
+	if _, err := s.Get("c"); err != ErrNotFound {
+		t.Errorf("Get deleted: %v", err)
+	}
+	if _, err := s.Get("d"); err != ErrNotFound {
+		t.Errorf("Get partial: %v", err)
+	}
+	if due, _ := s.Claim(now, now.Add(time.Minute), 10); len(due) != 0 {
+		t.Errorf("Claim: %+v", due)
+	}
+	later := now.Add(time.Hour)
+	if due, _ := s.Claim(later, later.Add(time.Minute), 10); len(due) != 1 || due[0].ID != "b" || !due[0].NextAttempt.Equal(later.Add(time.Minute)) {
+		t.Errorf("Claim: %+v", due)
+	}
+	// b is claimed until the lease expires
+	if due, _ := s.Claim(later, later.Add(time.Minute), 10); len(due) != 0 {
+		t.Errorf("Claim claimed: %+v", due)
+	}
+	if due, _ := s.Claim(later.Add(time.Minute), later.Add(2*time.Minute), 10); len(due) != 1 {
+		t.Errorf("Claim expired: %+v", due)
+	}
+}
+
+func TestFileStore_Compact(t *testing.T) {
+	s, path := tempStore(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer s.Close()
+
+	m := Message{ID: "a"}
+	for i := 0; i < 2000; i++ {
+		m.Attempts = i
+		if err := s.Put(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.log.Records() > 1024 {
+		t.Errorf("records: %d", s.log.Records())
+	}
+	if m, _ := s.Get("a"); m.Attempts != 1999 {
+		t.Errorf("Attempts: %d", m.Attempts)
+	}
+}
@@ -0,0 +1,366 @@
+// Package outbox persists sms before they are sent, and sends them
+// asynchronously with at-least-once delivery
+//
+// a message is marked as sent only after action "SendSms" succeeds,
+// so it may be sent again if the process exits right after the request
+//
+// due messages are claimed with a lease before they are sent, so that Outboxes
+// of processes can share a Store, e.g. a SQLStore
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// State of a message
+type State int
+
+const (
+	// Pending messages are waiting to be sent or retried
+	Pending State = iota
+
+	// Sent messages are accepted by aliyun sms
+	Sent
+
+	// Dead messages failed permanently or exceeded MaxAttempts
+	Dead
+)
+
+// String returns the name of State
+func (s State) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Sent:
+		return "sent"
+	case Dead:
+		return "dead"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ErrNotFound is returned if the message does not exist
+var ErrNotFound = errors.New("outbox: message not found")
+
+// Message in outbox
+type Message struct {
+	ID     string
+	Params sms.SendSmsParams
+	State  State
+
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+
+	BizID     string
+	RequestID string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Store persists messages
+// implementations must be concurrent safe
+type Store interface {
+	// Put inserts or replaces the message of the same ID
+	Put(m Message) error
+
+	// Get the message of id, ErrNotFound is returned if it does not exist
+	Get(id string) (Message, error)
+
+	// Delete the message of id
+	Delete(id string) error
+
+	// Claim returns at most limit Pending messages whose NextAttempt is not after now,
+	// ordered by NextAttempt, and sets their NextAttempt to until atomically,
+	// so that a message is claimed once by concurrent Dispatch or processes sharing the Store,
+	// until the lease expires
+	Claim(now, until time.Time, limit int) ([]Message, error)
+
+	// List returns all messages of state, ordered by CreatedAt
+	List(state State) ([]Message, error)
+}
+
+const (
+	// DefaultMaxAttempts is default upper limit of attempts of a message
+	DefaultMaxAttempts = 8
+
+	// DefaultPollInterval is default interval of polling due messages
+	DefaultPollInterval = time.Second
+
+	// DefaultBatchSize is default number of messages sent in one round
+	DefaultBatchSize = 100
+
+	// DefaultLease is default duration a message claimed by Dispatch is not claimed again
+	DefaultLease = time.Minute
+)
+
+// DefaultBackoff returns the delay before the next attempt,
+// it's 2^attempts seconds, and 5 minutes at most
+func DefaultBackoff(attempts int) time.Duration {
+	if attempts > 8 {
+		return 5 * time.Minute
+	}
+	d := time.Duration(1<<uint(attempts)) * time.Second
+	if d > 5*time.Minute {
+		d = 5 * time.Minute
+	}
+	return d
+}
+
+// Config of Outbox
+type Config struct {
+	Client sms.Client
+	Store  Store
+
+	// Options are applied to every action "SendSms"
+	Options []sms.Option
+
+	// MaxAttempts is upper limit of attempts of a message, default DefaultMaxAttempts
+	MaxAttempts int
+
+	// Backoff returns the delay before the next attempt, default DefaultBackoff
+	Backoff func(attempts int) time.Duration
+
+	// PollInterval is the interval of polling due messages in Run, default DefaultPollInterval
+	PollInterval time.Duration
+
+	// BatchSize is upper limit of messages sent in one round, default DefaultBatchSize
+	BatchSize int
+
+	// Lease is the duration a message claimed by Dispatch is not claimed again, default DefaultLease,
+	// it must be longer than sending a batch, a message claimed by a process exited during
+	// the send is sent again after the lease expires
+	Lease time.Duration
+}
+
+// Outbox persists sms and sends them in background
+// it's concurrent safe
+type Outbox struct {
+	conf Config
+	wake chan struct{}
+
+	// mu serializes read-modify-write of messages
+	mu sync.Mutex
+}
+
+// New init an Outbox
+func New(conf Config) *Outbox {
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = DefaultMaxAttempts
+	}
+	if conf.Backoff == nil {
+		conf.Backoff = DefaultBackoff
+	}
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = DefaultPollInterval
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = DefaultBatchSize
+	}
+	if conf.Lease <= 0 {
+		conf.Lease = DefaultLease
+	}
+	return &Outbox{conf: conf, wake: make(chan struct{}, 1)}
+}
+
+// Enqueue persists params, it's sent by Run later
+// params are validated, TemplateData is encoded into TemplateParam before it's persisted
+func (o *Outbox) Enqueue(params sms.SendSmsParams) (string, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	if params.TemplateData != nil {
+		tp, err := sms.EncodeTemplateParam(params.TemplateData)
+		if err != nil {
+			return "", err
+		}
+		params.TemplateParam = tp
+		params.TemplateData = nil
+	}
+
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	m := Message{ID: u4.String(), Params: params, State: Pending, NextAttempt: now, CreatedAt: now, UpdatedAt: now}
+	if err := o.conf.Store.Put(m); err != nil {
+		return "", err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return m.ID, nil
+}
+
+// Get the message of id
+func (o *Outbox) Get(id string) (Message, error) {
+	return o.conf.Store.Get(id)
+}
+
+// DeadLetters returns all Dead messages
+func (o *Outbox) DeadLetters() ([]Message, error) {
+	return o.conf.Store.List(Dead)
+}
+
+// Requeue a Dead message, it's sent again as a new Pending message
+func (o *Outbox) Requeue(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	m, err := o.conf.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if m.State != Dead {
+		return errors.New("outbox: message " + id + " is " + m.State.String() + ", only dead messages can be requeued")
+	}
+	now := time.Now()
+	m.State = Pending
+	m.Attempts = 0
+	m.NextAttempt = now
+	m.UpdatedAt = now
+	if err := o.conf.Store.Put(m); err != nil {
+		return err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Purge deletes Sent messages updated before the time, returns number of deleted messages
+func (o *Outbox) Purge(before time.Time) (int, error) {
+	messages, err := o.conf.Store.List(Sent)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, m := range messages {
+		if m.UpdatedAt.Before(before) {
+			if err := o.conf.Store.Delete(m.ID); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Run sends due messages until ctx is done, err of ctx is returned
+func (o *Outbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := o.Dispatch(ctx)
+			if err != nil || n < o.conf.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// Dispatch claims one batch of due messages and sends them, returns number of messages attempted
+// messages not sent since ctx is done are released without an attempt counted
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	messages, err := o.conf.Store.Claim(now, now.Add(o.conf.Lease), o.conf.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, m := range messages {
+		if ctx.Err() != nil {
+			return i, o.release(messages[i:], ctx.Err())
+		}
+		if err := o.send(ctx, m); err != nil {
+			return i, o.release(messages[i+1:], err)
+		}
+	}
+	return len(messages), nil
+}
+
+// release claimed messages still Pending, so they are due again, err is returned
+// unless it fails to release them
+func (o *Outbox) release(messages []Message, err error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, m := range messages {
+		if releaseErr := o.put(m, func(m *Message) {
+			m.NextAttempt = time.Now()
+		}); releaseErr != nil {
+			return releaseErr
+		}
+	}
+	return err
+}
+
+// put m updated by update if it's still Pending, o.mu must be held
+func (o *Outbox) put(m Message, update func(m *Message)) error {
+	// the message may be deleted or changed during the request
+	cur, err := o.conf.Store.Get(m.ID)
+	if err == ErrNotFound || err == nil && cur.State != Pending {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	update(&m)
+	m.UpdatedAt = time.Now()
+	return o.conf.Store.Put(m)
+}
+
+// send m and persists the result, only errs of Store are returned
+// m is released if the request fails since ctx is done, e.g. at shutdown
+func (o *Outbox) send(ctx context.Context, m Message) error {
+	extOpts := append(append([]sms.Option{}, o.conf.Options...), sms.ContextOption(ctx))
+	opts, err := sms.NewSendAction(o.conf.Client, m.Params).Do(extOpts...)
+	if err == nil {
+		res := opts.Response()
+		m.RequestID = res.RequestID
+		m.BizID = res.BizID
+		err = res.Err()
+	} else if ctx.Err() != nil {
+		return o.release([]Message{m}, nil)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.put(m, func(m *Message) {
+		now := time.Now()
+		m.Attempts++
+		switch {
+		case err == nil:
+			m.State = Sent
+			m.LastError = ""
+		case !sms.IsTemporary(err) || m.Attempts >= o.conf.MaxAttempts:
+			m.State = Dead
+			m.LastError = err.Error()
+		default:
+			m.NextAttempt = now.Add(o.conf.Backoff(m.Attempts))
+			m.LastError = err.Error()
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+var c = sms.NewClient(sms.Config{AccessKeyID: "testId", AccessSecret: "testSecret"})
+
+// testHandler returns responses in order, the last one is repeated,
+// it waits until the context is done if block is set
+type testHandler struct {
+	responses []string
+	numbers   []string
+	block     bool
+}
+
+func (h *testHandler) DoReq(opts sms.Options) (*sms.HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	h.numbers = append(h.numbers, u.Query().Get("PhoneNumbers"))
+	if h.block {
+		<-opts.Context().Done()
+		return nil, opts.Context().Err()
+	}
+
+	res := h.responses[0]
+	if len(h.responses) > 1 {
+		h.responses = h.responses[1:]
+	}
+	if res == "" {
+		return nil, errors.New("connection reset")
+	}
+	return &sms.HTTPResponse{StatusCode: http.StatusOK, Body: []byte(res)}, nil
+}
+
+const (
+	okResponse        = `{"Message":"OK","RequestId":"R","BizId":"B^0","Code":"OK"}`
+	throttledResponse = `{"Message":"触发分钟级流控Permits:1","RequestId":"R","Code":"isv.BUSINESS_LIMIT_CONTROL"}`
+	illegalResponse   = `{"Message":"非法手机号","RequestId":"R","Code":"isv.MOBILE_NUMBER_ILLEGAL"}`
+)
+
+var testParams = sms.SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+
+func newTestOutbox(t *testing.T, h *testHandler) (*Outbox, func()) {
+	s, path := tempStore(t)
+	o := New(Config{
+		Client:  c,
+		Store:   s,
+		Options: []sms.Option{sms.ReqHandlerOption(h)},
+		Backoff: func(int) time.Duration { return 0 },
+	})
+	return o, func() {
+		s.Close()
+		os.RemoveAll(filepath.Dir(path))
+	}
+}
+
+func TestOutbox_Dispatch(t *testing.T) {
+	h := &testHandler{responses: []string{"", throttledResponse, okResponse}}
+	o, cleanup := newTestOutbox(t, h)
+	defer cleanup()
+
+	id, err := o.Enqueue(testParams)
+	if err != nil {
+		t.Fatalf("Enqueue err: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if n, err := o.Dispatch(context.Background()); n != 1 || err != nil {
+			t.Fatalf("Dispatch: %d, %v", n, err)
+		}
+	}
+	if n, _ := o.Dispatch(context.Background()); n != 0 {
+		t.Errorf("Dispatch sent messages: %d", n)
+	}
+
+	m, err := o.Get(id)
+	if err != nil || m.State != Sent || m.Attempts != 3 || m.BizID != "B^0" || m.RequestID != "R" {
+		t.Errorf("Get: %+v, %v", m, err)
+	}
+	if len(h.numbers) != 3 || h.numbers[2] != "15300000001" {
+		t.Errorf("requests: %v", h.numbers)
+	}
+
+	if n, err := o.Purge(time.Now().Add(time.Second)); n != 1 || err != nil {
+		t.Errorf("Purge: %d, %v", n, err)
+	}
+	if _, err := o.Get(id); err != ErrNotFound {
+		t.Errorf("Get purged: %v", err)
+	}
+}
+
+func TestOutbox_DeadLetters(t *testing.T) {
+	h := &testHandler{responses: []string{illegalResponse, okResponse}}
+	o, cleanup := newTestOutbox(t, h)
+	defer cleanup()
+
+	id, _ := o.Enqueue(testParams)
+	o.Dispatch(context.Background())
+
+	dead, err := o.DeadLetters()
+	if err != nil || len(dead) != 1 || dead[0].ID != id || dead[0].Attempts != 1 || dead[0].LastError == "" {
+		t.Fatalf("DeadLetters: %+v, %v", dead, err)
+	}
+	if err := o.Requeue(id); err != nil {
+		t.Fatalf("Requeue err: %v", err)
+	}
+	if err := o.Requeue(id); err == nil {
+		t.Errorf("Requeue pending message")
+	}
+	o.Dispatch(context.Background())
+	if m, _ := o.Get(id); m.State != Sent {
+		t.Errorf("State: %v", m.State)
+	}
+}
+
+func TestOutbox_MaxAttempts(t *testing.T) {
+	h := &testHandler{responses: []string{throttledResponse}}
+	o, cleanup := newTestOutbox(t, h)
+	defer cleanup()
+	o.conf.MaxAttempts = 2
+
+	id, _ := o.Enqueue(testParams)
+	o.Dispatch(context.Background())
+	o.Dispatch(context.Background())
+	if m, _ := o.Get(id); m.State != Dead || m.Attempts != 2 {
+		t.Errorf("Get: %+v", m)
+	}
+}
+
+func TestOutbox_DispatchClaimed(t *testing.T) {
+	h := &testHandler{responses: []string{okResponse}}
+	o, cleanup := newTestOutbox(t, h)
+	defer cleanup()
+
+	id, _ := o.Enqueue(testParams)
+	// the message is claimed by another Dispatch
+	now := time.Now()
+	if claimed, err := o.conf.Store.Claim(now, now.Add(time.Minute), 10); err != nil || len(claimed) != 1 {
+		t.Fatalf("Claim: %+v, %v", claimed, err)
+	}
+	if n, err := o.Dispatch(context.Background()); n != 0 || err != nil || len(h.numbers) != 0 {
+		t.Errorf("Dispatch: %d, %v, requests: %v", n, err, h.numbers)
+	}
+	if m, _ := o.Get(id); m.State != Pending {
+		t.Errorf("State: %v", m.State)
+	}
+}
+
+func TestOutbox_DispatchCanceled(t *testing.T) {
+	h := &testHandler{responses: []string{okResponse}, block: true}
+	o, cleanup := newTestOutbox(t, h)
+	defer cleanup()
+
+	ids := make([]string, 2)
+	for i := range ids {
+		ids[i], _ = o.Enqueue(testParams)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := o.Dispatch(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Dispatch err: %v", err)
+	}
+	// messages are released without attempts, they are due again
+	for _, id := range ids {
+		if m, _ := o.Get(id); m.State != Pending || m.Attempts != 0 || m.NextAttempt.After(time.Now()) {
+			t.Errorf("Get: %+v", m)
+		}
+	}
+
+	h.block = false
+	if n, err := o.Dispatch(context.Background()); n != 2 || err != nil {
+		t.Errorf("Dispatch: %d, %v", n, err)
+	}
+}
+
+func TestOutbox_Enqueue(t *testing.T) {
+	o, cleanup := newTestOutbox(t, &testHandler{responses: []string{okResponse}})
+	defer cleanup()
+
+	if _, err := o.Enqueue(sms.SendSmsParams{}); err == nil {
+		t.Errorf("Enqueue invalid params")
+	}
+
+	params := testParams
+	params.TemplateData = map[string]string{"code": "1234"}
+	id, err := o.Enqueue(params)
+	if err != nil {
+		t.Fatalf("Enqueue err: %v", err)
+	}
+	if m, _ := o.Get(id); m.Params.TemplateData != nil || m.Params.TemplateParam["code"] != "1234" {
+		t.Errorf("Params: %+v", m.Params)
+	}
+}
+
+func TestOutbox_Run(t *testing.T) {
+	h := &testHandler{responses: []string{okResponse}}
+	o, cleanup := newTestOutbox(t, h)
+	defer cleanup()
+	o.conf.PollInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- o.Run(ctx) }()
+
+	id, _ := o.Enqueue(testParams)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if m, _ := o.Get(id); m.State == Sent {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("message is not sent")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run err: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// QuestionPlaceholder returns "?" as bind parameter, e.g. MySQL and SQLite
+func QuestionPlaceholder(int) string {
+	return "?"
+}
+
+// DollarPlaceholder returns "$n" as bind parameter, e.g. PostgreSQL
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// SQLStore is a Store of database/sql
+// times are stored as unix nanoseconds, Params is stored as JSON
+type SQLStore struct {
+	db    *sql.DB
+	table string
+
+	// placeholder returns the bind parameter of the n-th argument, starting from 1
+	placeholder func(n int) string
+}
+
+// NewSQLStore init a SQLStore of table,
+// placeholder returns the bind parameter of the n-th argument, starting from 1,
+// QuestionPlaceholder is used if it's nil
+func NewSQLStore(db *sql.DB, table string, placeholder func(n int) string) *SQLStore {
+	if placeholder == nil {
+		placeholder = QuestionPlaceholder
+	}
+	return &SQLStore{db: db, table: table, placeholder: placeholder}
+}
+
+// CreateTableSQL returns the statement to create the table of SQLStore
+func (s *SQLStore) CreateTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS ` + s.table + ` (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	params TEXT NOT NULL,
+	state INTEGER NOT NULL,
+	attempts INTEGER NOT NULL,
+	next_attempt BIGINT NOT NULL,
+	last_error TEXT NOT NULL,
+	biz_id VARCHAR(64) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+)`
+}
+
+const sqlColumns = "id, params, state, attempts, next_attempt, last_error, biz_id, request_id, created_at, updated_at"
+
+// bind returns placeholders of n arguments starting from the start-th, joined by sep
+func (s *SQLStore) bind(start, n int, format func(i int, p string) string, sep string) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = format(i, s.placeholder(start+i))
+	}
+	return strings.Join(parts, sep)
+}
+
+// Put implements Store
+// the row is updated if it exists or inserted in a transaction,
+// ids of new messages are created by Outbox, so they are never Put concurrently
+func (s *SQLStore) Put(m Message) error {
+	params, err := json.Marshal(m.Params)
+	if err != nil {
+		return err
+	}
+	values := []interface{}{
+		string(params), int(m.State), m.Attempts, m.NextAttempt.UnixNano(), m.LastError,
+		m.BizID, m.RequestID, m.CreatedAt.UnixNano(), m.UpdatedAt.UnixNano(),
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// RowsAffected of an UPDATE can't tell whether the row exists,
+	// MySQL reports 0 for a row not changed by it
+	var n int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM "+s.table+" WHERE id = "+s.placeholder(1), m.ID).Scan(&n); err != nil {
+		return err
+	}
+	columns := strings.Split(sqlColumns, ", ")[1:]
+	if n > 0 {
+		set := s.bind(1, len(columns), func(i int, p string) string {
+			return columns[i] + " = " + p
+		}, ", ")
+		_, err = tx.Exec("UPDATE "+s.table+" SET "+set+" WHERE id = "+s.placeholder(len(columns)+1),
+			append(values, m.ID)...)
+	} else {
+		insert := s.bind(1, len(columns)+1, func(i int, p string) string {
+			return p
+		}, ", ")
+		_, err = tx.Exec("INSERT INTO "+s.table+" ("+sqlColumns+") VALUES ("+insert+")",
+			append([]interface{}{m.ID}, values...)...)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get implements Store
+func (s *SQLStore) Get(id string) (Message, error) {
+	messages, err := s.query("SELECT "+sqlColumns+" FROM "+s.table+" WHERE id = "+s.placeholder(1), id)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(messages) == 0 {
+		return Message{}, ErrNotFound
+	}
+	return messages[0], nil
+}
+
+// Delete implements Store
+func (s *SQLStore) Delete(id string) error {
+	_, err := s.db.Exec("DELETE FROM "+s.table+" WHERE id = "+s.placeholder(1), id)
+	return err
+}
+
+// Claim implements Store
+// every due message is claimed by a conditional UPDATE of its next_attempt,
+// messages claimed by others since they are selected are skipped
+func (s *SQLStore) Claim(now, until time.Time, limit int) ([]Message, error) {
+	due, err := s.query("SELECT "+sqlColumns+" FROM "+s.table+
+		" WHERE state = "+s.placeholder(1)+" AND next_attempt <= "+s.placeholder(2)+
+		" ORDER BY next_attempt LIMIT "+strconv.Itoa(limit),
+		int(Pending), now.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	var claimed []Message
+	for _, m := range due {
+		res, err := s.db.Exec("UPDATE "+s.table+" SET next_attempt = "+s.placeholder(1)+
+			" WHERE id = "+s.placeholder(2)+" AND state = "+s.placeholder(3)+" AND next_attempt = "+s.placeholder(4),
+			until.UnixNano(), m.ID, int(Pending), m.NextAttempt.UnixNano())
+		if err != nil {
+			return claimed, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return claimed, err
+		} else if n > 0 {
+			m.NextAttempt = until
+			claimed = append(claimed, m)
+		}
+	}
+	return claimed, nil
+}
+
+// List implements Store
+func (s *SQLStore) List(state State) ([]Message, error) {
+	return s.query("SELECT "+sqlColumns+" FROM "+s.table+" WHERE state = "+s.placeholder(1)+" ORDER BY created_at",
+		int(state))
+}
+
+func (s *SQLStore) query(query string, args ...interface{}) ([]Message, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var m Message
+		var params string
+		var state int
+		var nextAttempt, createdAt, updatedAt int64
+		if err := rows.Scan(&m.ID, &params, &state, &m.Attempts, &nextAttempt, &m.LastError,
+			&m.BizID, &m.RequestID, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		var p sms.SendSmsParams
+		if err := json.Unmarshal([]byte(params), &p); err != nil {
+			return nil, err
+		}
+		m.Params = p
+		m.State = State(state)
+		m.NextAttempt = time.Unix(0, nextAttempt)
+		m.CreatedAt = time.Unix(0, createdAt)
+		m.UpdatedAt = time.Unix(0, updatedAt)
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
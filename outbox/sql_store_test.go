@@ -0,0 +1,210 @@
+//go:build sqlite
+// +build sqlite
+
+package outbox
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// mysqlRowsDriver is a sqlite driver reports no affected rows of UPDATEs,
+// like MySQL does for rows not changed by them
+type mysqlRowsDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *mysqlRowsDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return mysqlRowsConn{conn}, nil
+}
+
+type mysqlRowsConn struct {
+	driver.Conn
+}
+
+func (c mysqlRowsConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return mysqlRowsStmt{Stmt: stmt, update: strings.HasPrefix(query, "UPDATE")}, nil
+}
+
+type mysqlRowsStmt struct {
+	driver.Stmt
+	update bool
+}
+
+func (s mysqlRowsStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := s.Stmt.Exec(args)
+	if err != nil || !s.update {
+		return res, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func init() {
+	sql.Register("sqlite3_mysql_rows", &mysqlRowsDriver{})
+}
+
+// memorySQLStore returns a SQLStore of an in-memory database of driverName
+func memorySQLStore(t *testing.T, driverName string, placeholder func(int) string) *SQLStore {
+	db, err := sql.Open(driverName, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection of ":memory:" is a different database
+	db.SetMaxOpenConns(1)
+	s := NewSQLStore(db, "outbox", placeholder)
+	if _, err := db.Exec(s.CreateTableSQL()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSQLStore_PutUnchanged(t *testing.T) {
+	s := memorySQLStore(t, "sqlite3_mysql_rows", nil)
+	defer s.db.Close()
+
+	m := Message{ID: "a", State: Pending, CreatedAt: time.Now()}
+	for i := 0; i < 2; i++ {
+		// Put of a message not changed is not inserted again
+		if err := s.Put(m); err != nil {
+			t.Fatalf("Put err: %v", err)
+		}
+	}
+	m.State = Sent
+	if err := s.Put(m); err != nil {
+		t.Fatalf("Put err: %v", err)
+	}
+	if m, err := s.Get("a"); err != nil || m.State != Sent {
+		t.Errorf("Get: %+v, %v", m, err)
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	for _, placeholder := range []func(int) string{QuestionPlaceholder, DollarPlaceholder} {
+		s := memorySQLStore(t, "sqlite3", placeholder)
+
+		now := time.Unix(0, time.Now().UnixNano())
+		params := sms.SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+		for _, m := range []Message{
+			{ID: "a", Params: params, State: Pending, NextAttempt: now, CreatedAt: now},
+			{ID: "b", Params: params, State: Pending, NextAttempt: now.Add(time.Hour), CreatedAt: now.Add(time.Second)},
+			{ID: "c", Params: params, State: Dead, CreatedAt: now},
+		} {
+			if err := s.Put(m); err != nil {
+				t.Fatalf("Put err: %v", err)
+			}
+		}
+
+		m, _ := s.Get("a")
+		m.State = Sent
+		m.BizID = "B"
+		if err := s.Put(m); err != nil {
+			t.Fatalf("Put err: %v", err)
+		}
+		if m, err := s.Get("a"); err != nil || m.State != Sent || m.BizID != "B" || m.Params.PhoneNumbers != "15300000001" || !m.CreatedAt.Equal(now) {
+			t.Errorf("Get: %+v, %v", m, err)
+		}
+
+		if due, err := s.Claim(now, now.Add(time.Minute), 10); err != nil || len(due) != 0 {
+			t.Errorf("Claim: %+v, %v", due, err)
+		}
+		later := now.Add(time.Hour)
+		if due, err := s.Claim(later, later.Add(time.Minute), 10); err != nil || len(due) != 1 || due[0].ID != "b" {
+			t.Errorf("Claim: %+v, %v", due, err)
+		}
+		// b is claimed until the lease expires
+		if due, err := s.Claim(later, later.Add(time.Minute), 10); err != nil || len(due) != 0 {
+			t.Errorf("Claim claimed: %+v, %v", due, err)
+		}
+		if m, _ := s.Get("b"); !m.NextAttempt.Equal(later.Add(time.Minute)) {
+			t.Errorf("NextAttempt: %v", m.NextAttempt)
+		}
+		if dead, err := s.List(Dead); err != nil || len(dead) != 1 || dead[0].ID != "c" {
+			t.Errorf("List: %+v, %v", dead, err)
+		}
+
+		if err := s.Delete("c"); err != nil {
+			t.Fatalf("Delete err: %v", err)
+		}
+		if _, err := s.Get("c"); err != ErrNotFound {
+			t.Errorf("Get deleted: %v", err)
+		}
+		s.db.Close()
+	}
+}
+
+func TestSQLStore_ClaimConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// stores of processes sharing the database
+	var stores []*SQLStore
+	for i := 0; i < 2; i++ {
+		db, err := sql.Open("sqlite3", "file:"+filepath.Join(dir, "outbox.db")+"?_busy_timeout=5000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		stores = append(stores, NewSQLStore(db, "outbox", nil))
+	}
+	if _, err := stores[0].db.Exec(stores[0].CreateTableSQL()); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	const n = 50
+	for i := 0; i < n; i++ {
+		if err := stores[0].Put(Message{ID: strconv.Itoa(i), State: Pending, NextAttempt: now, CreatedAt: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	claimed := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(s *SQLStore) {
+			defer wg.Done()
+			messages, err := s.Claim(now, now.Add(time.Minute), n)
+			if err != nil {
+				t.Errorf("Claim err: %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, m := range messages {
+				claimed[m.ID]++
+			}
+		}(stores[i%len(stores)])
+	}
+	wg.Wait()
+
+	if len(claimed) != n {
+		t.Errorf("%d messages claimed", len(claimed))
+	}
+	for id, times := range claimed {
+		if times != 1 {
+			t.Errorf("message %s is claimed %d times", id, times)
+		}
+	}
+}
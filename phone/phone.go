@@ -0,0 +1,271 @@
+// Package phone parses, validates and normalizes phone numbers
+// to the format aliyun sms api expects
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// MainlandCountryCode is country code of mainland China
+const MainlandCountryCode = "86"
+
+const (
+	// MinInternationalLength is lower limit length of national number of international phone numbers
+	MinInternationalLength = 4
+
+	// MaxE164Length is upper limit length of country code and national number, see ITU-T E.164
+	MaxE164Length = 15
+)
+
+var (
+	// ErrEmpty is returned if the phone number is empty
+	ErrEmpty = errors.New("empty phone number")
+
+	// ErrInvalidChar is returned if the phone number contains characters other than digits and separators
+	ErrInvalidChar = errors.New("invalid character")
+
+	// ErrInvalidMainland is returned if the phone number is not a valid 11 digits mainland mobile number
+	ErrInvalidMainland = errors.New("invalid mainland mobile number")
+
+	// ErrUnknownCountryCode is returned if the country code is unknown
+	ErrUnknownCountryCode = errors.New("unknown country code")
+
+	// ErrInvalidLength is returned if the length of an international phone number is invalid
+	ErrInvalidLength = errors.New("invalid length")
+
+	// ErrDuplicate is returned if a phone number appears more than once in a list
+	ErrDuplicate = errors.New("duplicate phone number")
+)
+
+// Error of parsing a phone number
+type Error struct {
+	Number string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return "phone: " + e.Err.Error() + ": " + `"` + e.Number + `"`
+}
+
+// ListError have all errors of parsing a list of phone numbers
+type ListError []*Error
+
+func (e ListError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Number is a parsed phone number
+type Number struct {
+	// CountryCode without leading "+" or "00", e.g. "86"
+	CountryCode string
+
+	// National is the phone number without country code
+	National string
+}
+
+// IsMainland reports whether n is a mainland China number
+func (n Number) IsMainland() bool {
+	return n.CountryCode == MainlandCountryCode
+}
+
+// String returns n in the format aliyun sms api expects,
+// 11 digits for mainland numbers, country code and national number for others,
+// e.g. "15300000001", "85261234567"
+func (n Number) String() string {
+	if n.IsMainland() {
+		return n.National
+	}
+	return n.CountryCode + n.National
+}
+
+// E164 returns n in E.164 format, e.g. "+8615300000001"
+func (n Number) E164() string {
+	return "+" + n.CountryCode + n.National
+}
+
+// Parse a phone number, accepted formats are
+// mainland 11 digits "15300000001",
+// with prefix "+8615300000001", "008615300000001", "8615300000001",
+// international with country code "+85261234567", "0085261234567", "85261234567"
+// numbers start with "1" and without prefix are always parsed as mainland numbers,
+// so country code "1" requires prefix "+" or "00"
+// spaces, "-", "(" and ")" are ignored, the trunk prefix "(0)" after country code
+// is dropped, e.g. "+44 (0)20 7946 0958"
+func Parse(s string) (Number, error) {
+	n, err := parse(s)
+	if err != nil {
+		return Number{}, &Error{Number: s, Err: err}
+	}
+	return n, nil
+}
+
+// MustParse is like Parse but panics if s cannot be parsed
+func MustParse(s string) Number {
+	n, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func parse(s string) (Number, error) {
+	digits := make([]byte, 0, len(s))
+	international := false
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch >= '0' && ch <= '9':
+			digits = append(digits, ch)
+		case ch == '(' && len(digits) > 0 && strings.HasPrefix(s[i:], "(0)"):
+			// trunk prefix, it's not dialed with country code
+			i += 2
+		case ch == ' ' || ch == '-' || ch == '(' || ch == ')':
+		case ch == '+' && len(digits) == 0 && !international:
+			international = true
+		default:
+			return Number{}, ErrInvalidChar
+		}
+	}
+	d := string(digits)
+	if d == "" {
+		return Number{}, ErrEmpty
+	}
+
+	if !international {
+		switch {
+		case strings.HasPrefix(d, "00"):
+			d = d[2:]
+		case d[0] == '1':
+			// without prefix, it's a mainland number rather than country code "1"
+			return parseMainland(d)
+		case d[0] == '0':
+			return Number{}, ErrUnknownCountryCode
+		}
+	}
+
+	cc := countryCode(d)
+	if cc == "" {
+		return Number{}, ErrUnknownCountryCode
+	}
+	if cc == MainlandCountryCode {
+		return parseMainland(d[len(cc):])
+	}
+	national := d[len(cc):]
+	if len(national) < MinInternationalLength || len(d) > MaxE164Length {
+		return Number{}, ErrInvalidLength
+	}
+	return Number{CountryCode: cc, National: national}, nil
+}
+
+func parseMainland(d string) (Number, error) {
+	if len(d) != 11 || d[0] != '1' || d[1] < '3' {
+		return Number{}, ErrInvalidMainland
+	}
+	return Number{CountryCode: MainlandCountryCode, National: d}, nil
+}
+
+// ParseList parses a comma-separated list of phone numbers,
+// empty segments, invalid numbers and duplicates are rejected,
+// all problems are returned at once as ListError
+func ParseList(s string) ([]Number, error) {
+	segments := strings.Split(s, ",")
+	numbers := make([]Number, 0, len(segments))
+	var errs ListError
+	for _, seg := range segments {
+		n, err := Parse(seg)
+		if err != nil {
+			errs = append(errs, err.(*Error))
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	if err := checkDuplicates(numbers); err != nil {
+		errs = append(errs, err...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return numbers, nil
+}
+
+// Join returns the comma-separated numbers in the format aliyun sms api expects,
+// ListError is returned if there are duplicates
+func Join(numbers []Number) (string, error) {
+	if err := checkDuplicates(numbers); err != nil {
+		return "", err
+	}
+	s := make([]string, len(numbers))
+	for i, n := range numbers {
+		s[i] = n.String()
+	}
+	return strings.Join(s, ","), nil
+}
+
+func checkDuplicates(numbers []Number) ListError {
+	var errs ListError
+	seen := make(map[Number]bool, len(numbers))
+	for _, n := range numbers {
+		if seen[n] {
+			errs = append(errs, &Error{Number: n.String(), Err: ErrDuplicate})
+			continue
+		}
+		seen[n] = true
+	}
+	return errs
+}
+
+// countryCode returns the country code prefix of d, or "" if unknown
+func countryCode(d string) string {
+	for l := 1; l <= 3 && l <= len(d); l++ {
+		if countryCodes[d[:l]] {
+			return d[:l]
+		}
+	}
+	return ""
+}
+
+// countryCodes are assigned country codes of ITU-T E.164,
+// no code is a prefix of another one
+var countryCodes = map[string]bool{
+	"1": true, "7": true,
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true, "34": true,
+	"36": true, "39": true, "40": true, "41": true, "43": true, "44": true, "45": true,
+	"46": true, "47": true, "48": true, "49": true, "51": true, "52": true, "53": true,
+	"54": true, "55": true, "56": true, "57": true, "58": true, "60": true, "61": true,
+	"62": true, "63": true, "64": true, "65": true, "66": true, "81": true, "82": true,
+	"84": true, "86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+	"211": true, "212": true, "213": true, "216": true, "218": true, "220": true,
+	"221": true, "222": true, "223": true, "224": true, "225": true, "226": true,
+	"227": true, "228": true, "229": true, "230": true, "231": true, "232": true,
+	"233": true, "234": true, "235": true, "236": true, "237": true, "238": true,
+	"239": true, "240": true, "241": true, "242": true, "243": true, "244": true,
+	"245": true, "246": true, "248": true, "249": true, "250": true, "251": true,
+	"252": true, "253": true, "254": true, "255": true, "256": true, "257": true,
+	"258": true, "260": true, "261": true, "262": true, "263": true, "264": true,
+	"265": true, "266": true, "267": true, "268": true, "269": true, "290": true,
+	"291": true, "297": true, "298": true, "299": true,
+	"350": true, "351": true, "352": true, "353": true, "354": true, "355": true,
+	"356": true, "357": true, "358": true, "359": true, "370": true, "371": true,
+	"372": true, "373": true, "374": true, "375": true, "376": true, "377": true,
+	"378": true, "380": true, "381": true, "382": true, "383": true, "385": true,
+	"386": true, "387": true, "389": true, "420": true, "421": true, "423": true,
+	"500": true, "501": true, "502": true, "503": true, "504": true, "505": true,
+	"506": true, "507": true, "508": true, "509": true, "590": true, "591": true,
+	"592": true, "593": true, "594": true, "595": true, "596": true, "597": true,
+	"598": true, "599": true,
+	"670": true, "672": true, "673": true, "674": true, "675": true, "676": true,
+	"677": true, "678": true, "679": true, "680": true, "681": true, "682": true,
+	"683": true, "685": true, "686": true, "687": true, "688": true, "689": true,
+	"690": true, "691": true, "692": true,
+	"850": true, "852": true, "853": true, "855": true, "856": true, "880": true,
+	"886": true,
+	"960": true, "961": true, "962": true, "963": true, "964": true, "965": true,
+	"966": true, "967": true, "968": true, "970": true, "971": true, "972": true,
+	"973": true, "974": true, "975": true, "976": true, "977": true, "992": true,
+	"993": true, "994": true, "995": true, "996": true, "998": true,
+}
@@ -0,0 +1,89 @@
+package phone
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		"15300000001":         "15300000001",
+		" 153-0000-0001 ":     "15300000001",
+		"+8615300000001":      "15300000001",
+		"008615300000001":     "15300000001",
+		"8615300000001":       "15300000001",
+		"+86 153 0000 0001":   "15300000001",
+		"+85261234567":        "85261234567",
+		"0085261234567":       "85261234567",
+		"85261234567":         "85261234567",
+		"+1 (415) 555-2671":   "14155552671",
+		"+44 20 7946 0958":    "442079460958",
+		"+886 912 345 678":    "886912345678",
+		"0065 9123 4567":      "6591234567",
+		"+7 912 345 67 89":    "79123456789",
+		"+81 90-1234-5678":    "819012345678",
+		"+852 6123 4567 ":     "85261234567",
+		"+61 (0) 412345678 ":  "61412345678",
+		"+44 (0)20 7946 0958": "442079460958",
+	}
+	for s, want := range cases {
+		n, err := Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q) err: %v", s, err)
+			continue
+		}
+		if n.String() != want {
+			t.Errorf("Parse(%q): %s != %s", s, n.String(), want)
+		}
+	}
+
+	n := MustParse("+8615300000001")
+	if !n.IsMainland() || n.E164() != "+8615300000001" {
+		t.Errorf("Number: %+v", n)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	cases := map[string]error{
+		"":                  ErrEmpty,
+		" - ":               ErrEmpty,
+		"1530000000a":       ErrInvalidChar,
+		"153+00000001":      ErrInvalidChar,
+		"1530000001":        ErrInvalidMainland,
+		"12300000001":       ErrInvalidMainland,
+		"+861530000000":     ErrInvalidMainland,
+		"02012345678":       ErrUnknownCountryCode,
+		"+80012345678":      ErrUnknownCountryCode,
+		"+852123":           ErrInvalidLength,
+		"+8521234567890123": ErrInvalidLength,
+	}
+	for s, want := range cases {
+		_, err := Parse(s)
+		e, ok := err.(*Error)
+		if !ok || e.Err != want {
+			t.Errorf("Parse(%q) err: %v != %v", s, err, want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	numbers, err := ParseList("15300000001, +8615300000002,0085261234567")
+	if err != nil {
+		t.Fatalf("ParseList err: %v", err)
+	}
+	s, err := Join(numbers)
+	if err != nil {
+		t.Fatalf("Join err: %v", err)
+	}
+	if want := "15300000001,15300000002,85261234567"; s != want {
+		t.Errorf("Join: %s != %s", s, want)
+	}
+
+	_, err = ParseList("15300000001,,+8615300000001,123")
+	errs, ok := err.(ListError)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("ParseList err: %v", err)
+	}
+	if errs[0].Err != ErrEmpty || errs[1].Err != ErrInvalidMainland || errs[2].Err != ErrDuplicate {
+		t.Errorf("ParseList errs: %v", errs)
+	}
+}
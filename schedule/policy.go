@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// Policy decides when a message is allowed to be sent
+type Policy interface {
+	// Next returns the earliest time not before t that m is allowed to be sent at
+	Next(m Message, t time.Time) time.Time
+}
+
+// Policies is a Policy allows the time allowed by all of them
+type Policies []Policy
+
+// maxPolicyRounds is upper limit of rounds to find a time allowed by all policies
+const maxPolicyRounds = 8
+
+// Next implements Policy
+func (ps Policies) Next(m Message, t time.Time) time.Time {
+	for i := 0; i < maxPolicyRounds; i++ {
+		next := t
+		for _, p := range ps {
+			next = p.Next(m, next)
+		}
+		if next.Equal(t) {
+			break
+		}
+		t = next
+	}
+	return t
+}
+
+// Clock is a time of day
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// ParseClock parses "15:04" into Clock
+func ParseClock(s string) (Clock, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return Clock{}, fmt.Errorf("schedule: invalid clock %q", s)
+	}
+	return Clock{Hour: t.Hour(), Minute: t.Minute()}, nil
+}
+
+// MustParseClock is like ParseClock but panics if s is invalid
+func MustParseClock(s string) Clock {
+	c, err := ParseClock(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String returns the Clock of type "15:04"
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+func (c Clock) minutes() int {
+	return c.Hour*60 + c.Minute
+}
+
+// on returns the time of c on the day of t, in the location of t
+func (c Clock) on(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, c.Hour, c.Minute, 0, 0, t.Location())
+}
+
+// QuietHours is a Policy defers messages inside the window from Start to End
+// of every day, e.g. 21:00 to 08:00, to End
+type QuietHours struct {
+	Start Clock
+	End   Clock
+
+	// Location of Start and End, default sms.ChinaStandardTime
+	Location *time.Location
+
+	// RecipientLocation returns the time zone of a recipient, Start and End are in it
+	// if it's set, Location is used if it returns nil
+	// messages to several recipients are deferred until it's allowed for all of them
+	RecipientLocation func(n phone.Number) *time.Location
+
+	// Types of templates the quiet hours apply to, all templates if it's empty
+	Types []sms.TemplateType
+
+	// Templates looks up the type of TemplateCode, required if Types is set,
+	// templates not registered are not deferred
+	Templates *sms.TemplateRegistry
+}
+
+// Next implements Policy
+func (q QuietHours) Next(m Message, t time.Time) time.Time {
+	if !q.applies(m) {
+		return t
+	}
+
+	locations := []*time.Location{q.location(nil)}
+	if q.RecipientLocation != nil {
+		if numbers := recipients(m.Params); len(numbers) > 0 {
+			locations = locations[:0]
+			for _, n := range numbers {
+				locations = append(locations, q.location(q.RecipientLocation(n)))
+			}
+		}
+	}
+
+	for i := 0; i < maxPolicyRounds; i++ {
+		next := t
+		for _, loc := range locations {
+			if end := q.end(next.In(loc)); end.After(next) {
+				next = end
+			}
+		}
+		if next.Equal(t) {
+			break
+		}
+		t = next
+	}
+	return t
+}
+
+func (q QuietHours) applies(m Message) bool {
+	if len(q.Types) == 0 {
+		return true
+	}
+	if q.Templates == nil {
+		return false
+	}
+	tpl, ok := q.Templates.Lookup(m.Params.TemplateCode)
+	if !ok {
+		return false
+	}
+	for _, typ := range q.Types {
+		if tpl.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (q QuietHours) location(loc *time.Location) *time.Location {
+	if loc != nil {
+		return loc
+	}
+	if q.Location != nil {
+		return q.Location
+	}
+	return sms.ChinaStandardTime
+}
+
+// end returns the end of the quiet window t is in, or t if it's not in the window
+func (q QuietHours) end(t time.Time) time.Time {
+	start, end := q.Start.minutes(), q.End.minutes()
+	now := t.Hour()*60 + t.Minute()
+	switch {
+	case start < end && now >= start && now < end:
+		return q.End.on(t)
+	case start > end && now >= start:
+		return q.End.on(t.AddDate(0, 0, 1))
+	case start > end && now < end:
+		return q.End.on(t)
+	}
+	return t
+}
+
+// recipients returns parsed phone numbers of params, invalid numbers are ignored
+func recipients(params sms.SendSmsParams) []phone.Number {
+	numbers, _ := phone.ParseList(params.PhoneNumbers)
+	return append(numbers, params.Recipients...)
+}
+
+// LocationByCountry returns a RecipientLocation of QuietHours looks up
+// the time zone by CountryCode of the number, e.g. {"852": hongKong},
+// nil is returned for countries not in locations
+func LocationByCountry(locations map[string]*time.Location) func(n phone.Number) *time.Location {
+	return func(n phone.Number) *time.Location {
+		return locations[n.CountryCode]
+	}
+}
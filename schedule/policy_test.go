@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+var night = QuietHours{Start: MustParseClock("21:00"), End: MustParseClock("08:00")}
+
+func beijing(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, sms.ChinaStandardTime)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestQuietHours_Next(t *testing.T) {
+	m := Message{Params: sms.SendSmsParams{PhoneNumbers: "15300000001", TemplateCode: "SMS_2"}}
+	cases := []struct {
+		at, want string
+	}{
+		{"2018-04-27 12:00", "2018-04-27 12:00"},
+		{"2018-04-27 21:00", "2018-04-28 08:00"},
+		{"2018-04-27 23:59", "2018-04-28 08:00"},
+		{"2018-04-28 07:59", "2018-04-28 08:00"},
+		{"2018-04-28 08:00", "2018-04-28 08:00"},
+	}
+	for _, cs := range cases {
+		if next := night.Next(m, beijing(cs.at)); !next.Equal(beijing(cs.want)) {
+			t.Errorf("Next(%s): %v != %s", cs.at, next, cs.want)
+		}
+	}
+
+	// daytime window
+	lunch := QuietHours{Start: MustParseClock("12:00"), End: MustParseClock("13:30")}
+	if next := lunch.Next(m, beijing("2018-04-27 12:10")); !next.Equal(beijing("2018-04-27 13:30")) {
+		t.Errorf("Next: %v", next)
+	}
+}
+
+func TestQuietHours_Types(t *testing.T) {
+	q := night
+	q.Types = []sms.TemplateType{sms.TemplatePromotion}
+	q.Templates = sms.NewTemplateRegistry(sms.PriceTable{},
+		sms.Template{Code: "SMS_1", Type: sms.TemplateVerification},
+		sms.Template{Code: "SMS_2", Type: sms.TemplatePromotion},
+	)
+
+	at := beijing("2018-04-27 22:00")
+	for code, want := range map[string]time.Time{"SMS_1": at, "SMS_2": beijing("2018-04-28 08:00"), "SMS_3": at} {
+		m := Message{Params: sms.SendSmsParams{PhoneNumbers: "15300000001", TemplateCode: code}}
+		if next := q.Next(m, at); !next.Equal(want) {
+			t.Errorf("Next(%s): %v != %v", code, next, want)
+		}
+	}
+}
+
+func TestQuietHours_RecipientLocation(t *testing.T) {
+	london := time.FixedZone("GMT", 0)
+	q := night
+	q.RecipientLocation = LocationByCountry(map[string]*time.Location{"44": london})
+
+	// 15:00 in London
+	at := beijing("2018-04-27 23:00")
+	m := Message{Params: sms.SendSmsParams{Recipients: []phone.Number{phone.MustParse("+447700900123")}}}
+	if next := q.Next(m, at); !next.Equal(at) {
+		t.Errorf("Next: %v", next)
+	}
+
+	// deferred until it's 08:00 in Beijing, which is 00:00 in London, then 08:00 in London
+	m.Params.PhoneNumbers = "15300000001"
+	if next := q.Next(m, at); !next.Equal(time.Date(2018, 4, 28, 8, 0, 0, 0, london)) {
+		t.Errorf("Next: %v", next)
+	}
+}
@@ -0,0 +1,342 @@
+// Package schedule holds sms until their send time, and defers them
+// by policies such as quiet hours
+//
+// schedules are persisted in a Store, so they survive restarts,
+// a due message is delivered once, use an outbox as Deliver for retries,
+// a message whose Deliver is interrupted by ctx, e.g. on shutdown, is kept Scheduled
+// and delivered again by the next Run
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// State of a message
+type State int
+
+const (
+	// Scheduled messages are waiting for their send time
+	Scheduled State = iota
+
+	// Delivered messages are passed to Deliver successfully
+	Delivered
+
+	// Canceled messages are canceled before their send time
+	Canceled
+
+	// Failed messages are rejected by Deliver, not by err of its ctx
+	Failed
+)
+
+// String returns the name of State
+func (s State) String() string {
+	switch s {
+	case Scheduled:
+		return "scheduled"
+	case Delivered:
+		return "delivered"
+	case Canceled:
+		return "canceled"
+	case Failed:
+		return "failed"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+var (
+	// ErrNotFound is returned if the message does not exist
+	ErrNotFound = errors.New("schedule: message not found")
+
+	// ErrNotScheduled is returned by Cancel if the message is delivered, failed, canceled
+	// or being delivered
+	ErrNotScheduled = errors.New("schedule: message is not scheduled")
+)
+
+// Message is a scheduled sms
+type Message struct {
+	ID     string
+	Params sms.SendSmsParams
+	State  State
+
+	// At is the time requested by Schedule
+	At time.Time
+
+	// SendAt is the time it's sent at, it's later than At if it's deferred by Policy
+	SendAt time.Time
+
+	LastError string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Store persists messages
+// implementations must be concurrent safe
+type Store interface {
+	// Put inserts or replaces the message of the same ID
+	Put(m Message) error
+
+	// Get the message of id, ErrNotFound is returned if it does not exist
+	Get(id string) (Message, error)
+
+	// Delete the message of id
+	Delete(id string) error
+
+	// Due returns at most limit Scheduled messages whose SendAt is not after now,
+	// ordered by SendAt
+	Due(now time.Time, limit int) ([]Message, error)
+
+	// List returns all messages of state, ordered by CreatedAt
+	List(state State) ([]Message, error)
+}
+
+const (
+	// DefaultPollInterval is default interval of polling due messages
+	DefaultPollInterval = time.Second
+
+	// DefaultBatchSize is default number of messages delivered in one round
+	DefaultBatchSize = 100
+)
+
+// Config of Scheduler
+type Config struct {
+	Store Store
+
+	// Policy defers messages, e.g. QuietHours, optional
+	Policy Policy
+
+	// Deliver a due message, e.g. enqueue it to an outbox,
+	// default sends it by action "SendSms" of Client
+	Deliver func(ctx context.Context, params sms.SendSmsParams) error
+
+	// Client and Options are used by the default Deliver
+	Client  sms.Client
+	Options []sms.Option
+
+	// PollInterval is the interval of polling due messages in Run, default DefaultPollInterval
+	PollInterval time.Duration
+
+	// BatchSize is upper limit of messages delivered in one round, default DefaultBatchSize
+	BatchSize int
+}
+
+// Scheduler holds messages until their send time
+// it's concurrent safe
+type Scheduler struct {
+	conf Config
+	wake chan struct{}
+
+	// mu serializes read-modify-write of messages, it's not held during Deliver,
+	// messages being delivered are in delivering instead
+	mu         sync.Mutex
+	delivering map[string]struct{}
+}
+
+// New init a Scheduler
+func New(conf Config) *Scheduler {
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = DefaultPollInterval
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = DefaultBatchSize
+	}
+	s := &Scheduler{conf: conf, wake: make(chan struct{}, 1), delivering: make(map[string]struct{})}
+	if s.conf.Deliver == nil {
+		s.conf.Deliver = s.send
+	}
+	return s
+}
+
+// Schedule params to be sent at the time, or later if it's deferred by Policy
+// params are validated, TemplateData is encoded into TemplateParam before it's persisted
+func (s *Scheduler) Schedule(params sms.SendSmsParams, at time.Time) (Message, error) {
+	if err := params.Validate(); err != nil {
+		return Message{}, err
+	}
+	if params.TemplateData != nil {
+		tp, err := sms.EncodeTemplateParam(params.TemplateData)
+		if err != nil {
+			return Message{}, err
+		}
+		params.TemplateParam = tp
+		params.TemplateData = nil
+	}
+
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return Message{}, err
+	}
+	now := time.Now()
+	m := Message{ID: u4.String(), Params: params, State: Scheduled, At: at, CreatedAt: now, UpdatedAt: now}
+	m.SendAt = s.next(m, at)
+	if err := s.conf.Store.Put(m); err != nil {
+		return Message{}, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return m, nil
+}
+
+// Get the message of id
+func (s *Scheduler) Get(id string) (Message, error) {
+	return s.conf.Store.Get(id)
+}
+
+// Cancel a Scheduled message, ErrNotScheduled is returned if it's not Scheduled
+func (s *Scheduler) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.conf.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.delivering[id]; ok || m.State != Scheduled {
+		return ErrNotScheduled
+	}
+	m.State = Canceled
+	m.UpdatedAt = time.Now()
+	return s.conf.Store.Put(m)
+}
+
+// Purge deletes Delivered, Canceled and Failed messages updated before the time,
+// returns number of deleted messages
+func (s *Scheduler) Purge(before time.Time) (int, error) {
+	n := 0
+	for _, state := range []State{Delivered, Canceled, Failed} {
+		messages, err := s.conf.Store.List(state)
+		if err != nil {
+			return n, err
+		}
+		for _, m := range messages {
+			if m.UpdatedAt.Before(before) {
+				if err := s.conf.Store.Delete(m.ID); err != nil {
+					return n, err
+				}
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// Run delivers due messages until ctx is done, err of ctx is returned
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := s.Dispatch(ctx)
+			if err != nil || n < s.conf.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Dispatch delivers one batch of due messages, returns number of messages handled
+// messages deferred by Policy at the time are rescheduled instead of delivered
+func (s *Scheduler) Dispatch(ctx context.Context) (int, error) {
+	messages, err := s.conf.Store.Due(time.Now(), s.conf.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, m := range messages {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		if err := s.dispatch(ctx, m); err != nil {
+			return i, err
+		}
+	}
+	return len(messages), nil
+}
+
+// dispatch m, only errs of Store are returned
+func (s *Scheduler) dispatch(ctx context.Context, m Message) error {
+	m, ok, err := s.claim(m.ID)
+	if !ok || err != nil {
+		return err
+	}
+
+	err = s.conf.Deliver(ctx, m.Params)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.delivering, m.ID)
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// interrupted, e.g. on shutdown, it's delivered again by the next Run
+		return nil
+	case err != nil:
+		m.State = Failed
+		m.LastError = err.Error()
+	default:
+		m.State = Delivered
+	}
+	m.UpdatedAt = time.Now()
+	return s.conf.Store.Put(m)
+}
+
+// claim the message of id for Deliver, ok is false if it's not Scheduled,
+// being delivered, or deferred by Policy
+func (s *Scheduler) claim(id string) (m Message, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the message may be canceled after it's loaded
+	m, err = s.conf.Store.Get(id)
+	if err == ErrNotFound || err == nil && m.State != Scheduled {
+		return m, false, nil
+	}
+	if err != nil {
+		return m, false, err
+	}
+	if _, ok := s.delivering[id]; ok {
+		return m, false, nil
+	}
+
+	now := time.Now()
+	if next := s.next(m, now); next.After(now) {
+		m.SendAt = next
+		m.UpdatedAt = now
+		return m, false, s.conf.Store.Put(m)
+	}
+	s.delivering[id] = struct{}{}
+	return m, true, nil
+}
+
+func (s *Scheduler) next(m Message, t time.Time) time.Time {
+	if s.conf.Policy == nil {
+		return t
+	}
+	return s.conf.Policy.Next(m, t)
+}
+
+func (s *Scheduler) send(ctx context.Context, params sms.SendSmsParams) error {
+	extOpts := append(append([]sms.Option{}, s.conf.Options...), sms.ContextOption(ctx))
+	opts, err := sms.NewSendAction(s.conf.Client, params).Do(extOpts...)
+	if err != nil {
+		return err
+	}
+	return opts.Response().Err()
+}
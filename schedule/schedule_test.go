@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+var testParams = sms.SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+
+type testDeliver struct {
+	mu   sync.Mutex
+	sent []sms.SendSmsParams
+	err  error
+}
+
+func (d *testDeliver) deliver(ctx context.Context, params sms.SendSmsParams) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	d.sent = append(d.sent, params)
+	return nil
+}
+
+func tempStore(t *testing.T) (*FileStore, string) {
+	dir, err := ioutil.TempDir("", "schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "schedule.log")
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestScheduler_Dispatch(t *testing.T) {
+	store, path := tempStore(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	d := &testDeliver{}
+	s := New(Config{Store: store, Deliver: d.deliver})
+
+	now := time.Now()
+	due, err := s.Schedule(testParams, now.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("Schedule err: %v", err)
+	}
+	later, _ := s.Schedule(testParams, now.Add(time.Hour))
+	canceled, _ := s.Schedule(testParams, now.Add(-time.Second))
+	if err := s.Cancel(canceled.ID); err != nil {
+		t.Errorf("Cancel err: %v", err)
+	}
+
+	if n, err := s.Dispatch(context.Background()); n != 1 || err != nil {
+		t.Errorf("Dispatch: %d, %v", n, err)
+	}
+	if len(d.sent) != 1 {
+		t.Errorf("sent: %v", d.sent)
+	}
+	if err := s.Cancel(due.ID); err != ErrNotScheduled {
+		t.Errorf("Cancel delivered: %v", err)
+	}
+
+	// schedules survive restarts
+	store.Close()
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	s = New(Config{Store: store, Deliver: d.deliver})
+	for id, want := range map[string]State{due.ID: Delivered, later.ID: Scheduled, canceled.ID: Canceled} {
+		if m, err := s.Get(id); err != nil || m.State != want {
+			t.Errorf("Get(%s): %v, %v != %v", id, m.State, err, want)
+		}
+	}
+
+	if n, err := s.Purge(time.Now().Add(time.Second)); n != 2 || err != nil {
+		t.Errorf("Purge: %d, %v", n, err)
+	}
+	if _, err := s.Get(due.ID); err != ErrNotFound {
+		t.Errorf("Get purged: %v", err)
+	}
+}
+
+func TestScheduler_Policy(t *testing.T) {
+	store, path := tempStore(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer store.Close()
+	d := &testDeliver{}
+
+	// quiet from a minute ago to five minutes later
+	now := time.Now().In(sms.ChinaStandardTime)
+	start, end := now.Add(-time.Minute), now.Add(5*time.Minute)
+	q := QuietHours{
+		Start: Clock{Hour: start.Hour(), Minute: start.Minute()},
+		End:   Clock{Hour: end.Hour(), Minute: end.Minute()},
+	}
+	s := New(Config{Store: store, Deliver: d.deliver})
+	m, _ := s.Schedule(testParams, now)
+
+	// the policy is enforced when the message is due
+	s.conf.Policy = q
+	s.Dispatch(context.Background())
+	if m, _ := s.Get(m.ID); m.State != Scheduled || !m.SendAt.After(now) || len(d.sent) != 0 {
+		t.Errorf("Get: %+v", m)
+	}
+
+	m, _ = s.Schedule(testParams, now.Add(2*time.Minute))
+	if !m.SendAt.After(now.Add(2 * time.Minute)) {
+		t.Errorf("Schedule: %+v", m)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	store, path := tempStore(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer store.Close()
+	d := &testDeliver{err: errors.New("rejected")}
+	s := New(Config{Store: store, Deliver: d.deliver, PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	m, _ := s.Schedule(testParams, time.Now().Add(20*time.Millisecond))
+	deadline := time.Now().Add(time.Second)
+	for {
+		if m, _ := s.Get(m.ID); m.State == Failed {
+			if m.LastError != "rejected" || time.Now().Before(m.SendAt) {
+				t.Errorf("Get: %+v", m)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("message is not delivered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run err: %v", err)
+	}
+}
+
+func TestScheduler_DispatchCanceled(t *testing.T) {
+	store, path := tempStore(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	delivering := make(chan struct{})
+	deliver := func(ctx context.Context, params sms.SendSmsParams) error {
+		close(delivering)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	s := New(Config{Store: store, Deliver: deliver})
+	m, _ := s.Schedule(testParams, time.Now().Add(-time.Second))
+
+	done := make(chan struct{})
+	go func() {
+		s.Dispatch(ctx)
+		close(done)
+	}()
+	<-delivering
+	// the scheduler is not locked during Deliver, and the message being delivered is not canceled
+	if err := s.Cancel(m.ID); err != ErrNotScheduled {
+		t.Errorf("Cancel during Deliver: %v", err)
+	}
+	cancel()
+	<-done
+
+	// the message interrupted by shutdown is delivered again
+	if m, _ := s.Get(m.ID); m.State != Scheduled {
+		t.Errorf("Get: %+v", m)
+	}
+	d := &testDeliver{}
+	s = New(Config{Store: store, Deliver: d.deliver})
+	if n, err := s.Dispatch(context.Background()); n != 1 || err != nil || len(d.sent) != 1 {
+		t.Errorf("Dispatch: %d, %v, %v", n, err, d.sent)
+	}
+}
@@ -0,0 +1,96 @@
+package sender
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// Aliyun is a SenderQuerier of aliyun sms
+type Aliyun struct {
+	c       sms.Client
+	options []sms.Option
+}
+
+// NewAliyun init an Aliyun adapter of c, extOpts are applied to every action
+func NewAliyun(c sms.Client, extOpts ...sms.Option) *Aliyun {
+	return &Aliyun{c: c, options: extOpts}
+}
+
+func (a *Aliyun) opts(ctx context.Context) []sms.Option {
+	return append(append([]sms.Option{}, a.options...), sms.ContextOption(ctx))
+}
+
+// Send implements Sender, an *sms.Error is returned if Code is not "OK"
+func (a *Aliyun) Send(ctx context.Context, m Message) (Receipt, error) {
+	opts, err := sms.NewSendAction(a.c, sms.SendSmsParams{
+		PhoneNumbers:  strings.Join(m.To, ","),
+		SignName:      m.SignName,
+		TemplateCode:  m.TemplateCode,
+		TemplateParam: m.Params,
+		OutID:         m.OutID,
+	}).Do(a.opts(ctx)...)
+	if err != nil {
+		return Receipt{}, err
+	}
+	res := opts.Response()
+	if err := res.Err(); err != nil {
+		return Receipt{}, err
+	}
+	return Receipt{ID: res.BizID, RequestID: res.RequestID, SentAt: time.Time(opts.Timestamp())}, nil
+}
+
+// Query implements Querier
+func (a *Aliyun) Query(ctx context.Context, q Query) ([]Status, error) {
+	details, err := sms.QueryAllSendDetails(a.c, sms.QuerySendDetailsParams{
+		PhoneNumber: q.PhoneNumber,
+		BizID:       q.ID,
+		SendDate:    sms.Date(q.Date.In(sms.ChinaStandardTime)),
+		PageSize:    sms.QueryMaxPageSize,
+	}, a.opts(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(details))
+	for i, d := range details {
+		statuses[i] = aliyunStatus(q.ID, d)
+	}
+	return statuses, nil
+}
+
+// Status implements Querier
+func (a *Aliyun) Status(ctx context.Context, r Receipt, phoneNumber string) (Status, error) {
+	statuses, err := a.Query(ctx, Query{PhoneNumber: phoneNumber, Date: r.SentAt, ID: r.ID})
+	if err != nil {
+		return Status{}, err
+	}
+	if len(statuses) == 0 {
+		return Status{}, ErrNotFound
+	}
+	return statuses[len(statuses)-1], nil
+}
+
+// aliyunStatus converts d to Status, id is "" since BizId is not in d
+func aliyunStatus(id string, d sms.SendDetailDTO) Status {
+	s := Status{
+		ID:          id,
+		PhoneNumber: d.PhoneNum,
+		ErrCode:     d.ErrCode,
+		Content:     d.Content,
+		OutID:       d.OutID,
+	}
+	switch d.SendStatus {
+	case sms.SendStatusDelivered:
+		s.State = Delivered
+		s.ErrCode = ""
+	case sms.SendStatusFailed:
+		s.State = Failed
+	default:
+		s.State = Pending
+	}
+	s.SentAt, _ = d.SendTime()
+	s.DeliveredAt, _ = d.ReceiveTime()
+	return s
+}
@@ -0,0 +1,62 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+var c = sms.NewClient(sms.Config{AccessKeyID: "testId", AccessSecret: "testSecret"})
+
+type testHandler struct {
+	queries []url.Values
+}
+
+func (h *testHandler) DoReq(opts sms.Options) (*sms.HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	h.queries = append(h.queries, query)
+	switch {
+	case query.Get("Action") == sms.QuerySendDetails:
+		return &sms.HTTPResponse{StatusCode: http.StatusOK, Body: []byte(`{"TotalCount":1,"Message":"OK","RequestId":"R","Code":"OK","SmsSendDetailDTOs":{"SmsSendDetailDTO":[` +
+			`{"OutId":"1","SendDate":"2018-04-27 14:19:30","ReceiveDate":"2018-04-27 14:19:35","SendStatus":3,"ErrCode":"DELIVRD","PhoneNum":"15300000001"}]}}`)}, nil
+	case query.Get("PhoneNumbers") == "15300000002":
+		return &sms.HTTPResponse{StatusCode: http.StatusOK, Body: []byte(`{"Message":"非法手机号","RequestId":"R","Code":"isv.MOBILE_NUMBER_ILLEGAL"}`)}, nil
+	}
+	return &sms.HTTPResponse{StatusCode: http.StatusOK, Body: []byte(`{"Message":"OK","RequestId":"R","BizId":"B^0","Code":"OK"}`)}, nil
+}
+
+func TestAliyun(t *testing.T) {
+	h := &testHandler{}
+	a := NewAliyun(c, sms.ReqHandlerOption(h))
+	ctx := context.Background()
+
+	r, err := a.Send(ctx, Message{To: []string{"15300000001"}, SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007",
+		Params: map[string]string{"code": "1234"}, OutID: "1"})
+	if err != nil || r.ID != "B^0" || r.RequestID != "R" {
+		t.Fatalf("Send: %+v, %v", r, err)
+	}
+	if tp := h.queries[0].Get("TemplateParam"); tp != `{"code":"1234"}` {
+		t.Errorf("TemplateParam: %s", tp)
+	}
+
+	if _, err := a.Send(ctx, Message{To: []string{"15300000002"}, SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}); err == nil {
+		t.Errorf("Send err is nil")
+	} else if e, ok := err.(*sms.Error); !ok || e.Code != "isv.MOBILE_NUMBER_ILLEGAL" {
+		t.Errorf("Send err: %v", err)
+	}
+
+	s, err := a.Status(ctx, r, "15300000001")
+	if err != nil || s.State != Delivered || s.ErrCode != "" || s.ID != "B^0" || s.DeliveredAt.IsZero() {
+		t.Errorf("Status: %+v, %v", s, err)
+	}
+	if q := h.queries[len(h.queries)-1]; q.Get("BizId") != "B^0" {
+		t.Errorf("BizId: %s", q.Get("BizId"))
+	}
+}
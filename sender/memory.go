@@ -0,0 +1,150 @@
+package sender
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Record of a message sent by Memory
+type Record struct {
+	Message Message
+	Receipt Receipt
+}
+
+// Memory is a SenderQuerier records messages in memory and simulates their statuses,
+// for tests of code depends on Sender or Querier
+// it's concurrent safe, the zero value is ready to use
+type Memory struct {
+	// Delay after which a Pending message gets its final state, 0 is immediately
+	Delay time.Duration
+
+	// Outcome decides the final state and ErrCode of a message to phoneNumber,
+	// all messages are Delivered if it's nil
+	Outcome func(m Message, phoneNumber string) (State, string)
+
+	// Err is returned by Send if it's set
+	Err error
+
+	mu        sync.Mutex
+	records   []Record
+	overrides map[string]Status
+	seq       int
+}
+
+// NewMemory init a Memory
+func NewMemory() *Memory {
+	return &Memory{overrides: make(map[string]Status)}
+}
+
+// Send implements Sender
+func (m *Memory) Send(ctx context.Context, msg Message) (Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return Receipt{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return Receipt{}, m.Err
+	}
+	m.seq++
+	r := Receipt{ID: "memory-" + strconv.Itoa(m.seq), RequestID: "memory-request-" + strconv.Itoa(m.seq), SentAt: time.Now()}
+	m.records = append(m.records, Record{Message: msg, Receipt: r})
+	return r, nil
+}
+
+// Records returns all sent messages in order
+func (m *Memory) Records() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Record(nil), m.records...)
+}
+
+// Reset deletes all records and statuses set by SetStatus
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records = nil
+	m.overrides = make(map[string]Status)
+}
+
+// SetStatus sets state and errCode of the message id to phoneNumber,
+// it overrides the simulated status
+func (m *Memory) SetStatus(id, phoneNumber string, state State, errCode string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.overrides == nil {
+		m.overrides = make(map[string]Status)
+	}
+	m.overrides[id+"/"+phoneNumber] = Status{State: state, ErrCode: errCode, DeliveredAt: time.Now()}
+}
+
+// Query implements Querier
+func (m *Memory) Query(ctx context.Context, q Query) ([]Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	y, mo, d := q.Date.Date()
+	var statuses []Status
+	for _, r := range m.records {
+		if q.ID != "" && r.Receipt.ID != q.ID {
+			continue
+		}
+		if ry, rmo, rd := r.Receipt.SentAt.In(q.Date.Location()).Date(); ry != y || rmo != mo || rd != d {
+			continue
+		}
+		for _, to := range r.Message.To {
+			if to == q.PhoneNumber {
+				statuses = append(statuses, m.status(r, to))
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// Status implements Querier
+func (m *Memory) Status(ctx context.Context, r Receipt, phoneNumber string) (Status, error) {
+	statuses, err := m.Query(ctx, Query{PhoneNumber: phoneNumber, Date: r.SentAt, ID: r.ID})
+	if err != nil {
+		return Status{}, err
+	}
+	if len(statuses) == 0 {
+		return Status{}, ErrNotFound
+	}
+	return statuses[0], nil
+}
+
+func (m *Memory) status(r Record, phoneNumber string) Status {
+	s := Status{
+		ID:          r.Receipt.ID,
+		PhoneNumber: phoneNumber,
+		OutID:       r.Message.OutID,
+		SentAt:      r.Receipt.SentAt,
+	}
+	if o, ok := m.overrides[r.Receipt.ID+"/"+phoneNumber]; ok {
+		s.State, s.ErrCode, s.DeliveredAt = o.State, o.ErrCode, o.DeliveredAt
+		return s
+	}
+
+	done := r.Receipt.SentAt.Add(m.Delay)
+	if time.Now().Before(done) {
+		s.State = Pending
+		return s
+	}
+	s.State = Delivered
+	if m.Outcome != nil {
+		s.State, s.ErrCode = m.Outcome(r.Message, phoneNumber)
+	}
+	s.DeliveredAt = done
+	return s
+}
@@ -0,0 +1,88 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// interface assertions
+var (
+	_ SenderQuerier = (*Memory)(nil)
+	_ SenderQuerier = (*Aliyun)(nil)
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	m.Outcome = func(msg Message, phoneNumber string) (State, string) {
+		if phoneNumber == "15300000002" {
+			return Failed, "MOBILE_NOT_ON_SERVICE"
+		}
+		return Delivered, ""
+	}
+	ctx := context.Background()
+
+	r, err := m.Send(ctx, Message{To: []string{"15300000001", "15300000002"}, SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: "1"})
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	if records := m.Records(); len(records) != 1 || records[0].Receipt != r {
+		t.Errorf("Records: %+v", records)
+	}
+
+	if s, err := m.Status(ctx, r, "15300000001"); err != nil || s.State != Delivered || s.OutID != "1" {
+		t.Errorf("Status: %+v, %v", s, err)
+	}
+	if s, _ := m.Status(ctx, r, "15300000002"); s.State != Failed || s.ErrCode != "MOBILE_NOT_ON_SERVICE" {
+		t.Errorf("Status: %+v", s)
+	}
+	if _, err := m.Status(ctx, r, "15300000003"); err != ErrNotFound {
+		t.Errorf("Status err: %v", err)
+	}
+
+	m.SetStatus(r.ID, "15300000001", Failed, "DELIVRD_TIMEOUT")
+	if s, _ := m.Status(ctx, r, "15300000001"); s.State != Failed {
+		t.Errorf("Status: %+v", s)
+	}
+
+	if statuses, _ := m.Query(ctx, Query{PhoneNumber: "15300000001", Date: time.Now()}); len(statuses) != 1 {
+		t.Errorf("Query: %+v", statuses)
+	}
+	if statuses, _ := m.Query(ctx, Query{PhoneNumber: "15300000001", Date: time.Now().AddDate(0, 0, -1)}); len(statuses) != 0 {
+		t.Errorf("Query: %+v", statuses)
+	}
+}
+
+func TestMemory_Delay(t *testing.T) {
+	m := NewMemory()
+	m.Delay = time.Hour
+	ctx := context.Background()
+
+	r, _ := m.Send(ctx, Message{To: []string{"15300000001"}})
+	if s, _ := m.Status(ctx, r, "15300000001"); s.State != Pending {
+		t.Errorf("Status: %+v", s)
+	}
+
+	m.Err = errors.New("unavailable")
+	if _, err := m.Send(ctx, Message{To: []string{"15300000001"}}); err != m.Err {
+		t.Errorf("Send err: %v", err)
+	}
+	m.Reset()
+	if len(m.Records()) != 0 {
+		t.Errorf("Records after Reset")
+	}
+}
+
+func TestMemory_Zero(t *testing.T) {
+	m := &Memory{}
+	ctx := context.Background()
+	r, err := m.Send(ctx, Message{To: []string{"15300000001"}, SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"})
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	m.SetStatus(r.ID, "15300000001", Failed, "DELIVRD_TIMEOUT")
+	if s, err := m.Status(ctx, r, "15300000001"); err != nil || s.State != Failed {
+		t.Errorf("Status: %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package sender is a provider-agnostic interface of sending sms and querying
+// their statuses, application code can depend on it instead of package sms
+//
+// Aliyun adapts sms.Client, Memory records messages in memory for tests
+package sender
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Message to send
+type Message struct {
+	// To are phone numbers of recipients
+	To           []string
+	SignName     string
+	TemplateCode string
+	Params       map[string]string
+
+	// OutID is an optional ID of the caller
+	OutID string
+}
+
+// Receipt of a sent message
+type Receipt struct {
+	// ID of the message by the provider, e.g. BizId of aliyun sms
+	ID        string
+	RequestID string
+	SentAt    time.Time
+}
+
+// State of a message to a recipient
+type State int
+
+const (
+	// Pending messages are waiting for the receipt of the carrier
+	Pending State = iota
+
+	// Delivered messages are received by the recipient
+	Delivered
+
+	// Failed messages are not delivered
+	Failed
+)
+
+// String returns the name of State
+func (s State) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Delivered:
+		return "delivered"
+	case Failed:
+		return "failed"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Status of a message to a recipient
+type Status struct {
+	ID          string
+	PhoneNumber string
+	State       State
+
+	// ErrCode of the provider if it's Failed
+	ErrCode string
+
+	Content     string
+	OutID       string
+	SentAt      time.Time
+	DeliveredAt time.Time
+}
+
+// Query of statuses
+type Query struct {
+	PhoneNumber string
+
+	// Date the messages are sent on
+	Date time.Time
+
+	// ID of a message, all messages of PhoneNumber on Date if it's ""
+	ID string
+}
+
+// ErrNotFound is returned by Status if the message is not found
+var ErrNotFound = errors.New("sender: message not found")
+
+// Sender sends messages
+type Sender interface {
+	Send(ctx context.Context, m Message) (Receipt, error)
+}
+
+// Querier queries statuses of messages
+type Querier interface {
+	// Query statuses of q
+	Query(ctx context.Context, q Query) ([]Status, error)
+
+	// Status of the message of r to phoneNumber, ErrNotFound is returned if it's not found
+	Status(ctx context.Context, r Receipt, phoneNumber string) (Status, error)
+}
+
+// SenderQuerier is both Sender and Querier
+type SenderQuerier interface {
+	Sender
+	Querier
+}
@@ -0,0 +1,65 @@
+// Package signer signs and verifies requests of aliyun rpc style apis, e.g. dysmsapi,
+// by signature method "HMAC-SHA1" and signature version "1.0"
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+const (
+	// SignatureMethod supported by Sign
+	SignatureMethod = "HMAC-SHA1"
+
+	// SignatureVersion supported by Sign
+	SignatureVersion = "1.0"
+)
+
+// Sign returns the signature of params, param "Signature" of params is excluded
+// the signature is base64 encoded, escape it by PercentEncode in urls
+func Sign(method string, params url.Values, secret string) string {
+	return sign(method, SortedQueryString(params), secret)
+}
+
+func sign(method, sortedQueryString, secret string) string {
+	stringToSign := method + "&" + PercentEncode("/") + "&" + PercentEncode(sortedQueryString)
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SortedQueryString returns the query string of params sorted by key and
+// percent encoded, param "Signature" of params is excluded
+func SortedQueryString(params url.Values) string {
+	values := url.Values{}
+	for k, v := range params {
+		if k != "Signature" {
+			values[k] = v
+		}
+	}
+	// values.Encode() encodes the value sorted by key
+	return specialURLEncode(values.Encode())
+}
+
+// SignedQueryString returns the query string of params with the param "Signature"
+// first, followed by SortedQueryString of params
+func SignedQueryString(method string, params url.Values, secret string) string {
+	sortedQueryString := SortedQueryString(params)
+	return "Signature=" + PercentEncode(sign(method, sortedQueryString, secret)) + "&" + sortedQueryString
+}
+
+// PercentEncode encodes s by rfc3986, space is encoded to "%20" and "~" is not encoded
+func PercentEncode(s string) string {
+	return specialURLEncode(url.QueryEscape(s))
+}
+
+func specialURLEncode(s string) string {
+	s = strings.Replace(s, "+", "%20", -1)
+	s = strings.Replace(s, "*", "%2A", -1)
+	s = strings.Replace(s, "%7E", "~", -1)
+	return s
+}
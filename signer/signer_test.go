@@ -0,0 +1,62 @@
+package signer
+
+import (
+	"net/url"
+	"testing"
+)
+
+// example of the doc of aliyun sms api
+var docParams = url.Values{
+	"AccessKeyId":      {"testId"},
+	"Action":           {"SendSms"},
+	"Format":           {"XML"},
+	"OutId":            {"123"},
+	"PhoneNumbers":     {"15300000001"},
+	"RegionId":         {"cn-hangzhou"},
+	"SignName":         {"阿里云短信测试专用"},
+	"SignatureMethod":  {"HMAC-SHA1"},
+	"SignatureNonce":   {"45e25e9b-0a6f-4070-8c85-2956eda1b466"},
+	"SignatureVersion": {"1.0"},
+	"TemplateCode":     {"SMS_71390007"},
+	"TemplateParam":    {`{"customer":"test"}`},
+	"Timestamp":        {"2017-07-12T02:42:19Z"},
+	"Version":          {"2017-05-25"},
+}
+
+func TestSign(t *testing.T) {
+	if s := Sign("GET", docParams, "testSecret"); s != "zJDF+Lrzhj/ThnlvIToysFRq6t4=" {
+		t.Errorf("Sign: %s", s)
+	}
+
+	// Signature is excluded
+	params := url.Values{"Signature": {"x"}}
+	for k, v := range docParams {
+		params[k] = v
+	}
+	if s := Sign("GET", params, "testSecret"); s != "zJDF+Lrzhj/ThnlvIToysFRq6t4=" {
+		t.Errorf("Sign: %s", s)
+	}
+}
+
+func TestSignedQueryString(t *testing.T) {
+	q := SignedQueryString("GET", docParams, "testSecret")
+	if want := "Signature=zJDF%2BLrzhj%2FThnlvIToysFRq6t4%3D&AccessKeyId=testId&Action=SendSms&Format=XML&OutId=123"; q[:len(want)] != want {
+		t.Errorf("SignedQueryString: %s", q)
+	}
+}
+
+func TestPercentEncode(t *testing.T) {
+	cases := map[string]string{
+		"a b": "a%20b",
+		"a*b": "a%2Ab",
+		"a~b": "a~b",
+		"a+b": "a%2Bb",
+		"/":   "%2F",
+		"短信":  "%E7%9F%AD%E4%BF%A1",
+	}
+	for s, want := range cases {
+		if got := PercentEncode(s); got != want {
+			t.Errorf("PercentEncode(%q): %s != %s", s, got, want)
+		}
+	}
+}
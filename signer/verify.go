@@ -0,0 +1,224 @@
+package signer
+
+import (
+	"container/heap"
+	"crypto/hmac"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultMaxClockSkew is default upper limit of the difference of Timestamp and now
+const DefaultMaxClockSkew = 15 * time.Minute
+
+// SecretLookup returns the AccessSecret of accessKeyID, ok is false if it's unknown
+type SecretLookup func(accessKeyID string) (secret string, ok bool)
+
+// Secrets is a SecretLookup of AccessKeyId to AccessSecret
+func Secrets(keys map[string]string) SecretLookup {
+	return func(accessKeyID string) (string, bool) {
+		secret, ok := keys[accessKeyID]
+		return secret, ok
+	}
+}
+
+// NonceStore remembers used SignatureNonce until they expire
+// implementations must be concurrent safe
+type NonceStore interface {
+	// Use nonce for ttl, it returns false if nonce is used and not expired
+	Use(nonce string, ttl time.Duration) (bool, error)
+}
+
+// Error is returned by Verify if the request is rejected,
+// Code is the same as the one of aliyun api, e.g. "SignatureDoesNotMatch"
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return "signer: " + e.Code + ": " + e.Message
+}
+
+// StatusCode returns the http status code aliyun api responds with for e
+func (e *Error) StatusCode() int {
+	if e.Code == "InvalidAccessKeyId.NotFound" {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// Verifier verifies signatures, timestamps and nonces of requests
+type Verifier struct {
+	Secret SecretLookup
+
+	// MaxClockSkew is upper limit of the difference of Timestamp and now, default DefaultMaxClockSkew
+	MaxClockSkew time.Duration
+
+	// Nonces rejects replayed requests, nonces are not checked if it's nil
+	Nonces NonceStore
+
+	// Now returns the current time, default time.Now
+	Now func() time.Time
+}
+
+// NewVerifier init a Verifier of secret, nonces are remembered by a MemoryNonceStore
+func NewVerifier(secret SecretLookup) *Verifier {
+	return &Verifier{Secret: secret, Nonces: NewMemoryNonceStore()}
+}
+
+// Verify a request url
+func (v *Verifier) Verify(method, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &Error{Code: "InvalidParameter", Message: err.Error()}
+	}
+	params, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return &Error{Code: "InvalidParameter", Message: err.Error()}
+	}
+	return v.VerifyParams(method, params)
+}
+
+// VerifyRequest verifies params of r, both of the url and the form body
+func (v *Verifier) VerifyRequest(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return &Error{Code: "InvalidParameter", Message: err.Error()}
+	}
+	return v.VerifyParams(r.Method, r.Form)
+}
+
+// VerifyParams verifies params of a request of method
+func (v *Verifier) VerifyParams(method string, params url.Values) error {
+	for _, name := range []string{"AccessKeyId", "Signature", "SignatureNonce", "Timestamp"} {
+		if params.Get(name) == "" {
+			return &Error{Code: "Missing" + name, Message: name + " is mandatory for this action."}
+		}
+	}
+	if m := params.Get("SignatureMethod"); m != SignatureMethod {
+		return &Error{Code: "InvalidSignatureMethod", Message: "Specified signature method \"" + m + "\" is not supported."}
+	}
+
+	secret, ok := v.Secret(params.Get("AccessKeyId"))
+	if !ok {
+		return &Error{Code: "InvalidAccessKeyId.NotFound", Message: "Specified access key is not found."}
+	}
+	expected := Sign(method, params, secret)
+	if !hmacEqual(expected, params.Get("Signature")) {
+		return &Error{Code: "SignatureDoesNotMatch", Message: "Specified signature is not matched with our calculation."}
+	}
+
+	ts, err := time.Parse(time.RFC3339, params.Get("Timestamp"))
+	if err != nil {
+		return &Error{Code: "InvalidTimeStamp.Format", Message: "Specified time stamp or date value is not well formatted."}
+	}
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	skew := v.MaxClockSkew
+	if skew <= 0 {
+		skew = DefaultMaxClockSkew
+	}
+	if d := now.Sub(ts); d > skew || d < -skew {
+		return &Error{Code: "InvalidTimeStamp.Expired", Message: "Specified time stamp or date value is expired."}
+	}
+
+	if v.Nonces == nil {
+		return nil
+	}
+	// a request of the nonce is rejected by the timestamp after ts+skew
+	ok, err = v.Nonces.Use(params.Get("SignatureNonce"), ts.Add(skew).Sub(now))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &Error{Code: "SignatureNonceUsed", Message: "Specified signature nonce was used already."}
+	}
+	return nil
+}
+
+func hmacEqual(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// MemoryNonceStore is an in-memory NonceStore
+type MemoryNonceStore struct {
+	mu      sync.Mutex
+	nonces  map[string]time.Time
+	expires nonceHeap
+
+	// Now returns the current time, default time.Now
+	Now func() time.Time
+}
+
+// NewMemoryNonceStore init a MemoryNonceStore
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// Use implements NonceStore, expired nonces are deleted
+func (s *MemoryNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	// nonces are popped in order of expiry, a nonce used again after it expired
+	// has a newer expiry in s.nonces than the popped one
+	for len(s.expires) > 0 && now.After(s.expires[0].expire) {
+		e := heap.Pop(&s.expires).(nonceExpire)
+		if s.nonces[e.nonce].Equal(e.expire) {
+			delete(s.nonces, e.nonce)
+		}
+	}
+	if e, ok := s.nonces[nonce]; ok && !now.After(e) {
+		return false, nil
+	}
+	e := nonceExpire{nonce: nonce, expire: now.Add(ttl)}
+	s.nonces[nonce] = e.expire
+	heap.Push(&s.expires, e)
+	return true, nil
+}
+
+// Reset forgets all nonces
+func (s *MemoryNonceStore) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nonces = make(map[string]time.Time)
+	s.expires = nil
+}
+
+type nonceExpire struct {
+	nonce  string
+	expire time.Time
+}
+
+// nonceHeap is a min-heap of nonces by expiry
+type nonceHeap []nonceExpire
+
+func (h nonceHeap) Len() int            { return len(h) }
+func (h nonceHeap) Less(i, j int) bool  { return h[i].expire.Before(h[j].expire) }
+func (h nonceHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nonceHeap) Push(x interface{}) { *h = append(*h, x.(nonceExpire)) }
+
+func (h *nonceHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Verify a request url by secret, nonces are not checked, use a Verifier to reject replays
+func Verify(method, rawURL string, secret SecretLookup) error {
+	return (&Verifier{Secret: secret}).Verify(method, rawURL)
+}
+
+// VerifyRequest verifies r by secret, nonces are not checked, use a Verifier to reject replays
+func VerifyRequest(r *http.Request, secret SecretLookup) error {
+	return (&Verifier{Secret: secret}).VerifyRequest(r)
+}
@@ -0,0 +1,139 @@
+package signer
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	keys = Secrets(map[string]string{"testId": "testSecret"})
+	ts   = time.Date(2017, 7, 12, 2, 42, 19, 0, time.UTC)
+)
+
+func signedURL(params url.Values, secret string) string {
+	return "http://dysmsapi.aliyuncs.com/?" + SignedQueryString("GET", params, secret)
+}
+
+func code(err error) string {
+	if err == nil {
+		return ""
+	}
+	if e, ok := err.(*Error); ok {
+		return e.Code
+	}
+	return err.Error()
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	v := NewVerifier(keys)
+	v.Now = func() time.Time { return ts.Add(time.Minute) }
+
+	missing := url.Values{}
+	for k, vs := range docParams {
+		if k != "Timestamp" {
+			missing[k] = vs
+		}
+	}
+	unknown := url.Values{}
+	for k, vs := range docParams {
+		unknown[k] = vs
+	}
+	unknown.Set("AccessKeyId", "unknown")
+
+	cases := []struct {
+		rawURL string
+		code   string
+	}{
+		{signedURL(docParams, "testSecret"), ""},
+		{signedURL(docParams, "testSecret"), "SignatureNonceUsed"},
+		{signedURL(docParams, "wrong"), "SignatureDoesNotMatch"},
+		{signedURL(missing, "testSecret"), "MissingTimestamp"},
+		{signedURL(unknown, "testSecret"), "InvalidAccessKeyId.NotFound"},
+	}
+	for i, cs := range cases {
+		if c := code(v.Verify("GET", cs.rawURL)); c != cs.code {
+			t.Errorf("%d: Verify: %q != %q", i, c, cs.code)
+		}
+	}
+
+	// POST of the same params is signed by another string to sign
+	if c := code(Verify("POST", signedURL(docParams, "testSecret"), keys)); c != "SignatureDoesNotMatch" {
+		t.Errorf("Verify: %q", c)
+	}
+}
+
+func TestVerifier_Timestamp(t *testing.T) {
+	v := &Verifier{Secret: keys, MaxClockSkew: time.Minute}
+	rawURL := signedURL(docParams, "testSecret")
+
+	cases := []struct {
+		now  time.Time
+		code string
+	}{
+		{ts, ""},
+		{ts.Add(time.Minute), ""},
+		{ts.Add(-time.Minute), ""},
+		{ts.Add(time.Minute + time.Second), "InvalidTimeStamp.Expired"},
+		{ts.Add(-time.Minute - time.Second), "InvalidTimeStamp.Expired"},
+	}
+	for _, cs := range cases {
+		now := cs.now
+		v.Now = func() time.Time { return now }
+		// nonces are not checked without Nonces
+		if c := code(v.Verify("GET", rawURL)); c != cs.code {
+			t.Errorf("%s: Verify: %q != %q", now, c, cs.code)
+		}
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	params := url.Values{}
+	for k, vs := range docParams {
+		params[k] = vs
+	}
+	params.Set("Timestamp", time.Now().UTC().Format(time.RFC3339))
+	params.Set("Signature", Sign("POST", params, "testSecret"))
+
+	r, _ := http.NewRequest("POST", "http://dysmsapi.aliyuncs.com/", strings.NewReader(params.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := VerifyRequest(r, keys); err != nil {
+		t.Errorf("VerifyRequest: %v", err)
+	}
+	if s := (&Error{Code: "InvalidAccessKeyId.NotFound"}).StatusCode(); s != http.StatusNotFound {
+		t.Errorf("StatusCode: %d", s)
+	}
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	now := ts
+	s := NewMemoryNonceStore()
+	s.Now = func() time.Time { return now }
+
+	if ok, _ := s.Use("a", time.Minute); !ok {
+		t.Error("Use: a is not used")
+	}
+	if ok, _ := s.Use("a", time.Minute); ok {
+		t.Error("Use: a is used")
+	}
+	now = ts.Add(2 * time.Minute)
+	if ok, _ := s.Use("a", time.Minute); !ok {
+		t.Error("Use: a is expired")
+	}
+	s.Use("b", time.Minute)
+	now = ts.Add(2*time.Minute + 30*time.Second)
+	s.Use("c", time.Minute)
+	now = ts.Add(3*time.Minute + time.Second)
+	if ok, _ := s.Use("c", time.Minute); ok {
+		t.Error("Use: c is used")
+	}
+	if len(s.nonces) != 1 || len(s.expires) != 1 {
+		t.Errorf("expired nonces are not deleted: %v %v", s.nonces, s.expires)
+	}
+	s.Reset()
+	if ok, _ := s.Use("a", time.Minute); !ok {
+		t.Error("Use: a is reset")
+	}
+}
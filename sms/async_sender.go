@@ -0,0 +1,300 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// Priority of a send in AsyncSender
+type Priority int
+
+const (
+	// PriorityNormal is default priority, e.g. notifications
+	PriorityNormal Priority = iota
+
+	// PriorityHigh sends are taken before others, e.g. verification codes
+	PriorityHigh
+
+	// PriorityLow sends are taken after others, e.g. promotions
+	PriorityLow
+
+	priorities = 3
+)
+
+// String returns the name of Priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	}
+	return "Priority(" + strconv.Itoa(int(p)) + ")"
+}
+
+// lane returns index of the queue of p, lower is taken first
+func (p Priority) lane() int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	}
+	return 1
+}
+
+type priorityCtxKey struct{}
+
+// WithPriority returns a context carries p, it's used by AsyncSender.Submit
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityCtxKey{}, p)
+}
+
+// DefaultAsyncQueueSize is default capacity of every queue of a worker in AsyncSender
+const DefaultAsyncQueueSize = 64
+
+// ErrSenderClosed is returned for sends submitted after AsyncSender is shut down,
+// or not sent before Shutdown returns
+var ErrSenderClosed = errors.New("sms: sender is closed")
+
+// AsyncResult is result of a send submitted to AsyncSender
+type AsyncResult struct {
+	Response *SendSmsResponse
+	Err      error
+}
+
+// AsyncConfig of AsyncSender
+type AsyncConfig struct {
+	// Workers is number of concurrent actions "SendSms", default runtime.NumCPU()
+	Workers int
+
+	// QueueSize is capacity of every priority queue of a worker, default DefaultAsyncQueueSize
+	QueueSize int
+
+	// Options are applied to every action "SendSms"
+	Options []Option
+}
+
+// AsyncSender sends sms on a bounded pool of workers
+//
+// sends are sharded to workers by the first phone number, so sends of the same
+// phone number and priority are sent in order of submission
+//
+// priority of a send is the one set by WithPriority, or decided by the type of
+// the template in Config.Templates of the Client: verification templates are high,
+// promotion templates are low, and others are normal
+type AsyncSender struct {
+	c       Client
+	options []Option
+	workers []*asyncWorker
+
+	mu       sync.Mutex
+	closed   bool
+	quit     chan struct{}
+	submits  sync.WaitGroup
+	running  sync.WaitGroup
+	shutdown chan struct{}
+}
+
+type asyncJob struct {
+	ctx    context.Context
+	params SendSmsParams
+	result chan AsyncResult
+}
+
+type asyncWorker struct {
+	lanes [priorities]chan *asyncJob
+}
+
+// NewAsyncSender init an AsyncSender and starts its workers
+func NewAsyncSender(c Client, conf AsyncConfig) *AsyncSender {
+	if conf.Workers <= 0 {
+		conf.Workers = runtime.NumCPU()
+	}
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = DefaultAsyncQueueSize
+	}
+
+	s := &AsyncSender{
+		c:        c,
+		options:  conf.Options,
+		workers:  make([]*asyncWorker, conf.Workers),
+		quit:     make(chan struct{}),
+		shutdown: make(chan struct{}),
+	}
+	for i := range s.workers {
+		w := &asyncWorker{}
+		for l := range w.lanes {
+			w.lanes[l] = make(chan *asyncJob, conf.QueueSize)
+		}
+		s.workers[i] = w
+		s.running.Add(1)
+		go s.run(w)
+	}
+	return s
+}
+
+// Submit params to be sent, the result is sent to the returned channel once
+// it blocks if the queue is full, until there is room or ctx is done,
+// ctx is also the context of action "SendSms"
+func (s *AsyncSender) Submit(ctx context.Context, params SendSmsParams) <-chan AsyncResult {
+	job := &asyncJob{ctx: ctx, params: params, result: make(chan AsyncResult, 1)}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		job.result <- AsyncResult{Err: ErrSenderClosed}
+		return job.result
+	}
+	s.submits.Add(1)
+	s.mu.Unlock()
+	defer s.submits.Done()
+
+	w := s.workers[s.shard(params)]
+	select {
+	case w.lanes[s.priority(ctx, params).lane()] <- job:
+	case <-ctx.Done():
+		job.result <- AsyncResult{Err: ctx.Err()}
+	case <-s.quit:
+		job.result <- AsyncResult{Err: ErrSenderClosed}
+	}
+	return job.result
+}
+
+// Shutdown stops accepting sends, and waits for queued and in-flight sends to finish
+// if ctx is done before that, sends not started yet get ErrSenderClosed,
+// and err of ctx is returned without waiting for in-flight sends
+func (s *AsyncSender) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.quit)
+		s.submits.Wait()
+		for _, w := range s.workers {
+			for _, lane := range w.lanes {
+				close(lane)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-s.shutdown:
+		default:
+			close(s.shutdown)
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *AsyncSender) shard(params SendSmsParams) int {
+	key := strings.TrimSpace(strings.SplitN(params.PhoneNumbers, ",", 2)[0])
+	if key == "" && len(params.Recipients) > 0 {
+		key = params.Recipients[0].String()
+	} else if n, err := phone.Parse(key); err == nil {
+		key = n.String()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(s.workers)))
+}
+
+func (s *AsyncSender) priority(ctx context.Context, params SendSmsParams) Priority {
+	if p, ok := ctx.Value(priorityCtxKey{}).(Priority); ok {
+		return p
+	}
+	if templates := s.c.conf.Templates; templates != nil {
+		if t, ok := templates.Lookup(params.TemplateCode); ok {
+			switch t.Type {
+			case TemplateVerification:
+				return PriorityHigh
+			case TemplatePromotion:
+				return PriorityLow
+			}
+		}
+	}
+	return PriorityNormal
+}
+
+// next returns the job of the highest priority, it blocks if all lanes are empty,
+// nil is returned if all lanes are closed and empty
+func (w *asyncWorker) next() *asyncJob {
+	for _, lane := range w.lanes {
+		select {
+		case job, ok := <-lane:
+			if ok {
+				return job
+			}
+		default:
+		}
+	}
+
+	lanes := w.lanes
+	for {
+		select {
+		case job, ok := <-lanes[0]:
+			if ok {
+				return job
+			}
+			lanes[0] = nil
+		case job, ok := <-lanes[1]:
+			if ok {
+				return job
+			}
+			lanes[1] = nil
+		case job, ok := <-lanes[2]:
+			if ok {
+				return job
+			}
+			lanes[2] = nil
+		}
+		if lanes[0] == nil && lanes[1] == nil && lanes[2] == nil {
+			return nil
+		}
+	}
+}
+
+func (s *AsyncSender) run(w *asyncWorker) {
+	defer s.running.Done()
+
+	for job := w.next(); job != nil; job = w.next() {
+		select {
+		case <-s.shutdown:
+			job.result <- AsyncResult{Err: ErrSenderClosed}
+			continue
+		default:
+		}
+		if err := job.ctx.Err(); err != nil {
+			job.result <- AsyncResult{Err: err}
+			continue
+		}
+
+		extOpts := append(append([]Option{}, s.options...), ContextOption(job.ctx))
+		opts, err := NewSendAction(s.c, job.params).Do(extOpts...)
+		if err != nil {
+			job.result <- AsyncResult{Err: err}
+			continue
+		}
+		job.result <- AsyncResult{Response: opts.Response()}
+	}
+}
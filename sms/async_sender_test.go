@@ -0,0 +1,143 @@
+package sms
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAsyncHandler records TemplateParam "n" of every send, every send waits for release
+type testAsyncHandler struct {
+	mu      sync.Mutex
+	sent    []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *testAsyncHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	if h.started != nil {
+		h.started <- struct{}{}
+	}
+	if h.release != nil {
+		<-h.release
+	}
+	h.mu.Lock()
+	h.sent = append(h.sent, u.Query().Get("TemplateParam"))
+	h.mu.Unlock()
+	return testSendHandler{}.DoReq(opts)
+}
+
+func asyncParams(phoneNumber, n string) SendSmsParams {
+	return SendSmsParams{PhoneNumbers: phoneNumber, SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007",
+		TemplateParam: TemplateParam{"n": n}}
+}
+
+func TestAsyncSender_Order(t *testing.T) {
+	h := &testAsyncHandler{}
+	s := NewAsyncSender(c, AsyncConfig{Workers: 4, Options: []Option{ReqHandlerOption(h)}})
+
+	var results []<-chan AsyncResult
+	for _, n := range []string{"1", "2", "3", "4", "5"} {
+		results = append(results, s.Submit(context.Background(), asyncParams("15300000001", n)))
+	}
+	for _, r := range results {
+		if res := <-r; res.Err != nil || res.Response.BizID != rightSendSmsRes.BizID {
+			t.Errorf("result: %+v", res)
+		}
+	}
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown err: %v", err)
+	}
+
+	want := []string{`{"n":"1"}`, `{"n":"2"}`, `{"n":"3"}`, `{"n":"4"}`, `{"n":"5"}`}
+	for i := range want {
+		if h.sent[i] != want[i] {
+			t.Fatalf("order: %v", h.sent)
+		}
+	}
+	if res := <-s.Submit(context.Background(), asyncParams("15300000001", "6")); res.Err != ErrSenderClosed {
+		t.Errorf("Submit after Shutdown: %v", res.Err)
+	}
+}
+
+func TestAsyncSender_Priority(t *testing.T) {
+	h := &testAsyncHandler{started: make(chan struct{}, 10), release: make(chan struct{})}
+	s := NewAsyncSender(c, AsyncConfig{Workers: 1, Options: []Option{ReqHandlerOption(h)}})
+
+	// the worker is busy with the first send
+	first := s.Submit(context.Background(), asyncParams("15300000001", "first"))
+	<-h.started
+	low := s.Submit(WithPriority(context.Background(), PriorityLow), asyncParams("15300000002", "low"))
+	normal := s.Submit(context.Background(), asyncParams("15300000003", "normal"))
+	high := s.Submit(WithPriority(context.Background(), PriorityHigh), asyncParams("15300000004", "high"))
+	close(h.release)
+
+	for _, r := range []<-chan AsyncResult{first, low, normal, high} {
+		if res := <-r; res.Err != nil {
+			t.Errorf("result err: %v", res.Err)
+		}
+	}
+	want := []string{`{"n":"first"}`, `{"n":"high"}`, `{"n":"normal"}`, `{"n":"low"}`}
+	for i := range want {
+		if h.sent[i] != want[i] {
+			t.Fatalf("order: %v", h.sent)
+		}
+	}
+	s.Shutdown(context.Background())
+}
+
+func TestAsyncSender_Backpressure(t *testing.T) {
+	h := &testAsyncHandler{started: make(chan struct{}, 10), release: make(chan struct{})}
+	s := NewAsyncSender(c, AsyncConfig{Workers: 1, QueueSize: 1, Options: []Option{ReqHandlerOption(h)}})
+
+	first := s.Submit(context.Background(), asyncParams("15300000001", "1"))
+	<-h.started
+	queued := s.Submit(context.Background(), asyncParams("15300000001", "2"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if res := <-s.Submit(ctx, asyncParams("15300000001", "3")); res.Err != context.DeadlineExceeded {
+		t.Errorf("Submit to full queue: %v", res.Err)
+	}
+
+	// Shutdown drains the queue
+	done := make(chan error)
+	go func() { done <- s.Shutdown(context.Background()) }()
+	close(h.release)
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown err: %v", err)
+	}
+	for _, r := range []<-chan AsyncResult{first, queued} {
+		if res := <-r; res.Err != nil {
+			t.Errorf("result err: %v", res.Err)
+		}
+	}
+}
+
+func TestAsyncSender_ShutdownTimeout(t *testing.T) {
+	h := &testAsyncHandler{started: make(chan struct{}, 10), release: make(chan struct{})}
+	s := NewAsyncSender(c, AsyncConfig{Workers: 1, Options: []Option{ReqHandlerOption(h)}})
+
+	first := s.Submit(context.Background(), asyncParams("15300000001", "1"))
+	<-h.started
+	queued := s.Submit(context.Background(), asyncParams("15300000001", "2"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown err: %v", err)
+	}
+	close(h.release)
+	if res := <-first; res.Err != nil {
+		t.Errorf("in-flight err: %v", res.Err)
+	}
+	if res := <-queued; res.Err != ErrSenderClosed {
+		t.Errorf("queued err: %v", res.Err)
+	}
+}
@@ -0,0 +1,568 @@
+package sms
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Strategy of ClientPool to choose an account
+type Strategy int
+
+const (
+	// RoundRobin chooses accounts in turn
+	RoundRobin Strategy = iota
+
+	// Weighted chooses accounts in proportion to Weight
+	Weighted
+
+	// ByRegion chooses accounts of RegionID of params in turn,
+	// all accounts are candidates if none of them matches
+	ByRegion
+
+	// Failover always chooses accounts in order, the next account is used
+	// only if the previous one fails
+	Failover
+)
+
+// String returns the name of Strategy
+func (s Strategy) String() string {
+	switch s {
+	case RoundRobin:
+		return "round-robin"
+	case Weighted:
+		return "weighted"
+	case ByRegion:
+		return "by-region"
+	case Failover:
+		return "failover"
+	}
+	return "Strategy(" + strconv.Itoa(int(s)) + ")"
+}
+
+const (
+	// DefaultPoolCooldown is default duration an account is skipped after a failover
+	DefaultPoolCooldown = 30 * time.Second
+
+	// poolBizIDs is number of BizId remembered to route action "QuerySendDetails"
+	poolBizIDs = 10000
+)
+
+// ErrNoAccount is returned if there is no account in ClientPool
+var ErrNoAccount = errors.New("sms: no account in pool")
+
+// Account of ClientPool
+type Account struct {
+	// Name of the account, default AccessKeyID of Config
+	Name   string
+	Config Config
+
+	// Weight of strategy Weighted, default 1
+	Weight int
+
+	// RegionID of strategy ByRegion
+	RegionID string
+}
+
+// AccountStats is health and usage counters of an account
+type AccountStats struct {
+	Name string
+
+	// Requests is number of api requests
+	Requests int64
+
+	// Failures is number of requests failed by errs or Code not "OK"
+	Failures int64
+
+	// Failovers is number of requests moved to the next account
+	Failovers int64
+
+	// LastError and LastErrorAt are of the last failure
+	LastError   string
+	LastErrorAt time.Time
+
+	// Healthy is false in cooldown after an account level throttling, quota or auth failure
+	Healthy bool
+}
+
+// PoolConfig of ClientPool
+type PoolConfig struct {
+	Accounts []Account
+	Strategy Strategy
+
+	// Cooldown is the duration an account is skipped after an account level throttling, quota or auth failure,
+	// the account is still used if all accounts are in cooldown, default DefaultPoolCooldown
+	Cooldown time.Duration
+}
+
+// ClientPool routes actions "SendSms", "SendBatchSms" and "QuerySendDetails" to multiple accounts,
+// and fails over to the next account if one returns an account level throttling, quota or auth failure
+//
+// flow controls of phone numbers, e.g. "isv.BUSINESS_LIMIT_CONTROL", are returned as is,
+// since sending the number by another account bypasses the anti-spam limit of aliyun sms,
+// errs of requests, e.g. timeouts, are not failed over, since the sms may have been sent
+// management actions are of an account, use the Client of AccountClient
+// it's concurrent safe
+type ClientPool struct {
+	strategy Strategy
+	cooldown time.Duration
+	accounts []*poolAccount
+
+	mu      sync.Mutex
+	next    int
+	bizIDs  map[string]*list.Element
+	bizList *list.List
+}
+
+type poolAccount struct {
+	Account
+	c Client
+
+	// current weight of smooth weighted round-robin
+	current int
+
+	stats         AccountStats
+	cooldownUntil time.Time
+}
+
+type poolBizID struct {
+	bizID   string
+	account *poolAccount
+}
+
+// NewClientPool init a ClientPool
+func NewClientPool(conf PoolConfig) *ClientPool {
+	if conf.Cooldown <= 0 {
+		conf.Cooldown = DefaultPoolCooldown
+	}
+	p := &ClientPool{
+		strategy: conf.Strategy,
+		cooldown: conf.Cooldown,
+		bizIDs:   make(map[string]*list.Element),
+		bizList:  list.New(),
+	}
+	for _, a := range conf.Accounts {
+		if a.Name == "" {
+			a.Name = a.Config.AccessKeyID
+		}
+		if a.Weight <= 0 {
+			a.Weight = 1
+		}
+		p.accounts = append(p.accounts, &poolAccount{Account: a, c: NewClient(a.Config), stats: AccountStats{Name: a.Name}})
+	}
+	return p
+}
+
+// Stats returns counters of all accounts, in order of PoolConfig.Accounts
+func (p *ClientPool) Stats() []AccountStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]AccountStats, len(p.accounts))
+	for i, a := range p.accounts {
+		stats[i] = a.stats
+		stats[i].Healthy = !now.Before(a.cooldownUntil)
+	}
+	return stats
+}
+
+// regionAccounts returns accounts of regionID in order of PoolConfig.Accounts,
+// all accounts are of regionID unless the strategy is ByRegion
+func (p *ClientPool) regionAccounts(regionID string) []*poolAccount {
+	if p.strategy != ByRegion {
+		return p.accounts
+	}
+	var matched []*poolAccount
+	for _, a := range p.accounts {
+		if a.RegionID == regionID {
+			matched = append(matched, a)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+	return p.accounts
+}
+
+// candidates returns accounts in order they are tried for regionID,
+// accounts in cooldown are moved to the end
+func (p *ClientPool) candidates(regionID string) []*poolAccount {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	accounts := p.regionAccounts(regionID)
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	first := 0
+	switch p.strategy {
+	case RoundRobin, ByRegion:
+		first = p.next % len(accounts)
+		p.next++
+	case Weighted:
+		total := 0
+		for i, a := range accounts {
+			a.current += a.Weight
+			total += a.Weight
+			if a.current > accounts[first].current {
+				first = i
+			}
+		}
+		accounts[first].current -= total
+	}
+
+	now := time.Now()
+	ordered := make([]*poolAccount, 0, len(accounts))
+	var cooling []*poolAccount
+	for i := range accounts {
+		a := accounts[(first+i)%len(accounts)]
+		if now.Before(a.cooldownUntil) {
+			cooling = append(cooling, a)
+		} else {
+			ordered = append(ordered, a)
+		}
+	}
+	return append(ordered, cooling...)
+}
+
+// first returns the account sent bizID if it's known, or the first candidate of regionID,
+// nil is returned if there is no account
+func (p *ClientPool) first(regionID, bizID string) *poolAccount {
+	if acc := p.bizIDAccount(bizID); acc != nil {
+		return acc
+	}
+	if candidates := p.candidates(regionID); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// recipientLimits are Codes of flow controls of phone numbers, they are not failed over
+var recipientLimits = map[string]bool{
+	"isv.BUSINESS_LIMIT_CONTROL": true,
+	"isv.DAY_LIMIT_CONTROL":      true,
+}
+
+// record the result of a request of a, returns whether it should fail over
+func (p *ClientPool) record(a *poolAccount, err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a.stats.Requests++
+	if err == nil {
+		return false
+	}
+	a.stats.Failures++
+	a.stats.LastError = err.Error()
+	a.stats.LastErrorAt = time.Now()
+
+	e, ok := err.(*Error)
+	if !ok || recipientLimits[e.Code] {
+		return false
+	}
+	switch e.Kind() {
+	case KindThrottling, KindQuota, KindAuth:
+		a.stats.Failovers++
+		a.cooldownUntil = time.Now().Add(p.cooldown)
+		return true
+	}
+	return false
+}
+
+func (p *ClientPool) rememberBizID(bizID string, a *poolAccount) {
+	if bizID == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.bizIDs[bizID]; ok {
+		p.bizList.MoveToFront(e)
+		return
+	}
+	p.bizIDs[bizID] = p.bizList.PushFront(&poolBizID{bizID: bizID, account: a})
+	if p.bizList.Len() > poolBizIDs {
+		e := p.bizList.Back()
+		p.bizList.Remove(e)
+		delete(p.bizIDs, e.Value.(*poolBizID).bizID)
+	}
+}
+
+func (p *ClientPool) bizIDAccount(bizID string) *poolAccount {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.bizIDs[bizID]; ok {
+		return e.Value.(*poolBizID).account
+	}
+	return nil
+}
+
+// Client returns the Client of the first account
+func (p *ClientPool) Client() Client {
+	if len(p.accounts) == 0 {
+		return Client{conf: &Config{}}
+	}
+	return p.accounts[0].c
+}
+
+// AccountClient returns the Client of the account of name, e.g. for management actions
+func (p *ClientPool) AccountClient(name string) (Client, bool) {
+	for _, a := range p.accounts {
+		if a.Name == name {
+			return a.c, true
+		}
+	}
+	return Client{}, false
+}
+
+// failover calls do on accounts in order of the strategy of regionID until one doesn't fail over,
+// do returns the Response of the request, the err of the last call is returned
+func (p *ClientPool) failover(regionID string, do func(acc *poolAccount) (*Response, error)) error {
+	candidates := p.candidates(regionID)
+	if len(candidates) == 0 {
+		return ErrNoAccount
+	}
+	var err error
+	for _, acc := range candidates {
+		var res *Response
+		res, err = do(acc)
+		switch err.(type) {
+		case ValidationError, *SuppressedError:
+			return err
+		}
+		resErr := err
+		if err == nil {
+			resErr = res.Err()
+		}
+		if !p.record(acc, resErr) {
+			return err
+		}
+	}
+	return err
+}
+
+// NewSendAction init an action "SendSms" routed by the pool
+// Client of the action is the Client of the first account,
+// AccessKeyID of SendSmsOptions is of the account actually used
+func (p *ClientPool) NewSendAction(params SendSmsParams) SendSmsAction {
+	return &poolSendAction{p: p, params: params}
+}
+
+type poolSendAction struct {
+	p      *ClientPool
+	params SendSmsParams
+}
+
+func (a *poolSendAction) Client() Client {
+	return a.p.Client()
+}
+
+// Build the request of the first account in order of the strategy, see Build of SendSmsAction
+func (a *poolSendAction) Build(ctx context.Context, extOpts ...Option) (*http.Request, error) {
+	acc := a.p.first(a.params.RegionID, "")
+	if acc == nil {
+		return nil, ErrNoAccount
+	}
+	return NewSendAction(acc.c, a.params).Build(ctx, extOpts...)
+}
+
+// Presign the url of the first account in order of the strategy, see Presign of SendSmsAction
+func (a *poolSendAction) Presign(extOpts ...Option) (PresignedURL, error) {
+	acc := a.p.first(a.params.RegionID, "")
+	if acc == nil {
+		return PresignedURL{}, ErrNoAccount
+	}
+	return NewSendAction(acc.c, a.params).Presign(extOpts...)
+}
+
+// Do the send action on accounts in order of the strategy until one doesn't fail over,
+// the last result is returned if all accounts fail over
+func (a *poolSendAction) Do(extOpts ...Option) (SendSmsOptions, error) {
+	var opts SendSmsOptions
+	err := a.p.failover(a.params.RegionID, func(acc *poolAccount) (*Response, error) {
+		var err error
+		opts, err = NewSendAction(acc.c, a.params).Do(extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		a.p.rememberBizID(opts.Response().BizID, acc)
+		return &opts.Response().Response, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// NewSendBatchSmsAction init an action "SendBatchSms" routed by the pool as NewSendAction
+func (p *ClientPool) NewSendBatchSmsAction(params SendBatchSmsParams) SendBatchSmsAction {
+	return &poolSendBatchSmsAction{p: p, params: params}
+}
+
+type poolSendBatchSmsAction struct {
+	p      *ClientPool
+	params SendBatchSmsParams
+}
+
+func (a *poolSendBatchSmsAction) Client() Client {
+	return a.p.Client()
+}
+
+// Build the request of the first account in order of the strategy, see Build of SendBatchSmsAction
+func (a *poolSendBatchSmsAction) Build(ctx context.Context, extOpts ...Option) (*http.Request, error) {
+	acc := a.p.first(a.params.RegionID, "")
+	if acc == nil {
+		return nil, ErrNoAccount
+	}
+	return NewSendBatchSmsAction(acc.c, a.params).Build(ctx, extOpts...)
+}
+
+// Presign the url of the first account in order of the strategy, see Presign of SendBatchSmsAction
+func (a *poolSendBatchSmsAction) Presign(extOpts ...Option) (PresignedURL, error) {
+	acc := a.p.first(a.params.RegionID, "")
+	if acc == nil {
+		return PresignedURL{}, ErrNoAccount
+	}
+	return NewSendBatchSmsAction(acc.c, a.params).Presign(extOpts...)
+}
+
+// Do the send action on accounts in order of the strategy until one doesn't fail over,
+// the last result is returned if all accounts fail over
+func (a *poolSendBatchSmsAction) Do(extOpts ...Option) (SendBatchSmsOptions, error) {
+	var opts SendBatchSmsOptions
+	err := a.p.failover(a.params.RegionID, func(acc *poolAccount) (*Response, error) {
+		var err error
+		opts, err = NewSendBatchSmsAction(acc.c, a.params).Do(extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		a.p.rememberBizID(opts.Response().BizID, acc)
+		return &opts.Response().Response, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// NewQuerySendDetailsAction init an action "QuerySendDetails" routed by the pool
+// it's routed to the account sent BizID if it's known by the pool,
+// or it's sent to all accounts of RegionID, and their results are merged, see Do
+func (p *ClientPool) NewQuerySendDetailsAction(params QuerySendDetailsParams) QuerySendDetailsAction {
+	return &poolQuerySendDetailsAction{p: p, params: params}
+}
+
+type poolQuerySendDetailsAction struct {
+	p      *ClientPool
+	params QuerySendDetailsParams
+}
+
+func (a *poolQuerySendDetailsAction) Client() Client {
+	return a.p.Client()
+}
+
+// Build the request of the account chosen as Do does, without failover
+func (a *poolQuerySendDetailsAction) Build(ctx context.Context, extOpts ...Option) (*http.Request, error) {
+	acc := a.p.first(a.params.RegionID, a.params.BizID)
+	if acc == nil {
+		return nil, ErrNoAccount
+	}
+	return NewQuerySendDetailsAction(acc.c, a.params).Build(ctx, extOpts...)
+}
+
+// Presign the url of the account chosen as Do does, without failover
+func (a *poolQuerySendDetailsAction) Presign(extOpts ...Option) (PresignedURL, error) {
+	acc := a.p.first(a.params.RegionID, a.params.BizID)
+	if acc == nil {
+		return PresignedURL{}, ErrNoAccount
+	}
+	return NewQuerySendDetailsAction(acc.c, a.params).Presign(extOpts...)
+}
+
+// Do the query action, see NewQuerySendDetailsAction
+// if the account sent BizID is unknown, e.g. the sms is sent by another process,
+// the records of all accounts of RegionID are merged into the Response of the first account has records,
+// or the first account if none has, TotalCount is the sum of all accounts
+// a failure of any account is returned if none has records, since the sms may be sent by it
+func (a *poolQuerySendDetailsAction) Do(extOpts ...Option) (QuerySendDetailsOptions, error) {
+	if acc := a.p.bizIDAccount(a.params.BizID); acc != nil {
+		return a.do(acc, extOpts)
+	}
+	accounts := a.p.regionAccounts(a.params.RegionID)
+	if len(accounts) == 0 {
+		return nil, ErrNoAccount
+	}
+
+	results := make([]poolQueryResult, len(accounts))
+	var wg sync.WaitGroup
+	for i, acc := range accounts {
+		wg.Add(1)
+		go func(i int, acc *poolAccount) {
+			defer wg.Done()
+			results[i].opts, results[i].err = a.do(acc, extOpts)
+		}(i, acc)
+	}
+	wg.Wait()
+
+	var merged QuerySendDetailsOptions
+	var failed *poolQueryResult
+	total := 0
+	var details []SendDetailDTO
+	for i := range results {
+		r := &results[i]
+		if _, ok := r.err.(ValidationError); ok {
+			return nil, r.err
+		}
+		if r.err != nil || r.opts.Response().Err() != nil {
+			if failed == nil {
+				failed = r
+			}
+			continue
+		}
+		res := r.opts.Response()
+		if dtos := res.SmsSendDetailDTOs.SmsSendDetailDTO; len(dtos) > 0 {
+			if len(details) == 0 {
+				merged = r.opts
+			}
+			details = append(details, dtos...)
+			a.p.rememberBizID(a.params.BizID, accounts[i])
+		} else if merged == nil {
+			merged = r.opts
+		}
+		total += res.TotalCount
+	}
+	if len(details) == 0 && failed != nil {
+		return failed.opts, failed.err
+	}
+
+	res := merged.Response()
+	res.TotalCount = total
+	res.SmsSendDetailDTOs.SmsSendDetailDTO = details
+	return merged, nil
+}
+
+type poolQueryResult struct {
+	opts QuerySendDetailsOptions
+	err  error
+}
+
+// do the query action by acc, the result is recorded in the stats of acc
+func (a *poolQuerySendDetailsAction) do(acc *poolAccount, extOpts []Option) (QuerySendDetailsOptions, error) {
+	opts, err := NewQuerySendDetailsAction(acc.c, a.params).Do(extOpts...)
+	if _, ok := err.(ValidationError); ok {
+		return nil, err
+	}
+	resErr := err
+	if err == nil {
+		resErr = opts.Response().Err()
+	}
+	a.p.record(acc, resErr)
+	return opts, err
+}
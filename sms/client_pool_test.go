@@ -0,0 +1,207 @@
+package sms
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// testPoolHandler responds Code of the account, "OK" if it's not set,
+// action "QuerySendDetails" of the account found returns a record
+type testPoolHandler struct {
+	mu    sync.Mutex
+	codes map[string]string
+	found string
+	calls map[string]int
+}
+
+func (h *testPoolHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	id := u.Query().Get("AccessKeyId")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.calls == nil {
+		h.calls = make(map[string]int)
+	}
+	h.calls[id]++
+
+	code := h.codes[id]
+	if code == "" {
+		code = CodeOK
+	}
+	if u.Query().Get("Action") == QuerySendDetails {
+		if id == h.found && code == CodeOK {
+			return bodyResponse([]byte(fmt.Sprintf(`{"TotalCount":1,"Message":"OK","RequestId":"%s","Code":"OK",`+
+				`"SmsSendDetailDTOs":{"SmsSendDetailDTO":[{"PhoneNum":"15300000001","SendStatus":3,"ErrCode":"DELIVRD"}]}}`, id))), nil
+		}
+		return bodyResponse([]byte(fmt.Sprintf(`{"TotalCount":0,"Message":"%s","RequestId":"%s","Code":"%s"}`, code, id, code))), nil
+	}
+	return bodyResponse([]byte(fmt.Sprintf(`{"Message":"%s","RequestId":"%s","BizId":"%s^0","Code":"%s"}`, code, id, id, code))), nil
+}
+
+func testPool(strategy Strategy, accounts ...Account) *ClientPool {
+	for i := range accounts {
+		accounts[i].Config = Config{AccessKeyID: accounts[i].Name, AccessSecret: "testSecret"}
+	}
+	return NewClientPool(PoolConfig{Accounts: accounts, Strategy: strategy})
+}
+
+var poolParams = SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+
+func TestClientPool_Strategy(t *testing.T) {
+	cases := []struct {
+		strategy Strategy
+		accounts []Account
+		regionID string
+		want     map[string]int
+	}{
+		{RoundRobin, []Account{{Name: "a"}, {Name: "b"}}, "", map[string]int{"a": 4, "b": 4}},
+		{Weighted, []Account{{Name: "a", Weight: 3}, {Name: "b"}}, "", map[string]int{"a": 6, "b": 2}},
+		{ByRegion, []Account{{Name: "a", RegionID: "cn-hangzhou"}, {Name: "b", RegionID: "ap-southeast-1"}}, "ap-southeast-1", map[string]int{"b": 8}},
+		{Failover, []Account{{Name: "a"}, {Name: "b"}}, "", map[string]int{"a": 8}},
+	}
+	for _, cs := range cases {
+		h := &testPoolHandler{}
+		p := testPool(cs.strategy, cs.accounts...)
+		params := poolParams
+		params.RegionID = cs.regionID
+		for i := 0; i < 8; i++ {
+			if _, err := p.NewSendAction(params).Do(ReqHandlerOption(h)); err != nil {
+				t.Fatalf("Do err: %v", err)
+			}
+		}
+		if fmt.Sprint(h.calls) != fmt.Sprint(cs.want) {
+			t.Errorf("%v: %v != %v", cs.strategy, h.calls, cs.want)
+		}
+	}
+}
+
+func TestClientPool_Failover(t *testing.T) {
+	h := &testPoolHandler{codes: map[string]string{"a": "Throttling.User"}}
+	p := testPool(Failover, Account{Name: "a"}, Account{Name: "b"}, Account{Name: "c"})
+
+	opts, err := p.NewSendAction(poolParams).Do(ReqHandlerOption(h))
+	if err != nil || opts.AccessKeyID() != "b" || opts.Response().BizID != "b^0" {
+		t.Fatalf("Do: %v, %v", opts, err)
+	}
+	// a is in cooldown
+	p.NewSendAction(poolParams).Do(ReqHandlerOption(h))
+	if h.calls["a"] != 1 || h.calls["b"] != 2 {
+		t.Errorf("calls: %v", h.calls)
+	}
+
+	stats := p.Stats()
+	if s := stats[0]; s.Healthy || s.Requests != 1 || s.Failovers != 1 || s.LastError == "" {
+		t.Errorf("stats of a: %+v", s)
+	}
+	if s := stats[1]; !s.Healthy || s.Requests != 2 || s.Failures != 0 {
+		t.Errorf("stats of b: %+v", s)
+	}
+
+	// flow controls of the number are not failed over, and b is not in cooldown
+	h.codes["b"] = "isv.BUSINESS_LIMIT_CONTROL"
+	opts, err = p.NewSendAction(poolParams).Do(ReqHandlerOption(h))
+	if err != nil || opts.Response().Code != "isv.BUSINESS_LIMIT_CONTROL" || h.calls["c"] != 0 || !p.Stats()[1].Healthy {
+		t.Errorf("Do: %v, %v, %v", opts.Response(), err, h.calls)
+	}
+
+	// invalid params are not failed over
+	h.codes["b"] = "isv.MOBILE_NUMBER_ILLEGAL"
+	opts, err = p.NewSendAction(poolParams).Do(ReqHandlerOption(h))
+	if err != nil || opts.Response().Code != "isv.MOBILE_NUMBER_ILLEGAL" || h.calls["c"] != 0 {
+		t.Errorf("Do: %v, %v, %v", opts.Response(), err, h.calls)
+	}
+
+	// all accounts fail over
+	h.codes["b"], h.codes["c"] = "isv.AMOUNT_NOT_ENOUGH", "InvalidAccessKeyId.NotFound"
+	opts, err = p.NewSendAction(poolParams).Do(ReqHandlerOption(h))
+	if err != nil || opts.Response().Code != "Throttling.User" {
+		t.Errorf("Do: %v, %v", opts.Response(), err)
+	}
+}
+
+func TestClientPool_SendBatchSms(t *testing.T) {
+	h := &testPoolHandler{codes: map[string]string{"a": "isv.AMOUNT_NOT_ENOUGH"}}
+	p := testPool(Failover, Account{Name: "a"}, Account{Name: "b"})
+	params := SendBatchSmsParams{TemplateCode: "SMS_71390007", Messages: []BatchMessage{{PhoneNumber: "15300000001", SignName: "阿里云短信测试专用"}}}
+
+	opts, err := p.NewSendBatchSmsAction(params).Do(ReqHandlerOption(h))
+	if err != nil || opts.AccessKeyID() != "b" || opts.Response().BizID != "b^0" {
+		t.Fatalf("Do: %v, %v", opts, err)
+	}
+	// BizId of the batch is routed to the account sent it
+	q, err := p.NewQuerySendDetailsAction(QuerySendDetailsParams{
+		PhoneNumber: "15300000001", BizID: "b^0", SendDate: Date(ts), PageSize: 10, CurrentPage: 1,
+	}).Do(Timestamp(ts), ReqHandlerOption(h))
+	if err != nil || q.AccessKeyID() != "b" || h.calls["a"] != 1 {
+		t.Errorf("Do: %v, %v, %v", q, err, h.calls)
+	}
+
+	if c, ok := p.AccountClient("b"); !ok || c.conf.AccessKeyID != "b" {
+		t.Errorf("AccountClient: %v", ok)
+	}
+	if _, ok := p.AccountClient("x"); ok {
+		t.Errorf("AccountClient of unknown account")
+	}
+}
+
+func TestClientPool_Query(t *testing.T) {
+	h := &testPoolHandler{}
+	p := testPool(RoundRobin, Account{Name: "a"}, Account{Name: "b"})
+
+	opts, _ := p.NewSendAction(poolParams).Do(ReqHandlerOption(h))
+	bizID := opts.Response().BizID
+	for i := 0; i < 3; i++ {
+		q, err := p.NewQuerySendDetailsAction(QuerySendDetailsParams{
+			PhoneNumber: "15300000001", BizID: bizID, SendDate: Date(ts), PageSize: 10, CurrentPage: 1,
+		}).Do(Timestamp(ts), ReqHandlerOption(h))
+		if err != nil || q.AccessKeyID() != opts.AccessKeyID() {
+			t.Fatalf("Do: %v, %v", q, err)
+		}
+	}
+
+	if _, err := NewClientPool(PoolConfig{}).NewSendAction(poolParams).Do(); err != ErrNoAccount {
+		t.Errorf("Do err: %v", err)
+	}
+}
+
+func TestClientPool_QueryUnknownBizID(t *testing.T) {
+	h := &testPoolHandler{found: "b"}
+	p := testPool(RoundRobin, Account{Name: "a"}, Account{Name: "b"}, Account{Name: "c"})
+	params := QuerySendDetailsParams{PhoneNumber: "15300000001", BizID: "x^0", SendDate: Date(ts), PageSize: 10, CurrentPage: 1}
+
+	// the sms is sent by another process, all accounts are queried
+	q, err := p.NewQuerySendDetailsAction(params).Do(Timestamp(ts), ReqHandlerOption(h))
+	if err != nil || q.AccessKeyID() != "b" {
+		t.Fatalf("Do: %v, %v", q, err)
+	}
+	if res := q.Response(); res.TotalCount != 1 || len(res.SmsSendDetailDTOs.SmsSendDetailDTO) != 1 {
+		t.Errorf("Response: %+v", res)
+	}
+	if h.calls["a"] != 1 || h.calls["b"] != 1 || h.calls["c"] != 1 {
+		t.Errorf("calls: %v", h.calls)
+	}
+	// the account found is remembered
+	if _, err := p.NewQuerySendDetailsAction(params).Do(Timestamp(ts), ReqHandlerOption(h)); err != nil || h.calls["b"] != 2 || h.calls["a"] != 1 {
+		t.Errorf("Do: %v, calls: %v", err, h.calls)
+	}
+
+	// a failure is returned if no account has records
+	h = &testPoolHandler{codes: map[string]string{"c": "isv.BUSINESS_LIMIT_CONTROL"}}
+	params.BizID = ""
+	q, err = p.NewQuerySendDetailsAction(params).Do(Timestamp(ts), ReqHandlerOption(h))
+	if err != nil || q.Response().Code != "isv.BUSINESS_LIMIT_CONTROL" {
+		t.Errorf("Do: %v, %v", q, err)
+	}
+	// records are returned even if an account fails
+	h.found = "a"
+	q, err = p.NewQuerySendDetailsAction(params).Do(Timestamp(ts), ReqHandlerOption(h))
+	if err != nil || q.Response().Err() != nil || len(q.Response().SmsSendDetailDTOs.SmsSendDetailDTO) != 1 {
+		t.Errorf("Do: %v, %v", q, err)
+	}
+}
@@ -1,19 +1,14 @@
 package sms
 
 import (
-	"crypto/hmac"
-	"crypto/sha1"
-	"encoding/base64"
-	"encoding/json"
-	"encoding/xml"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/satori/go.uuid"
-	"io/ioutil"
+	"github.com/scistack/aliyun-sms-go/signer"
 	"net/http"
 	"net/url"
 	"reflect"
-	"runtime"
 	"strings"
 	"time"
 )
@@ -42,6 +37,16 @@ func ReqHandlerOption(handler ReqHandler) Option {
 	return reqHandlerOption{handler: handler}
 }
 
+type contextOption struct {
+	ctx context.Context
+}
+
+// ContextOption is helper func to set the context of the api request
+// the request is canceled when ctx is done
+func ContextOption(ctx context.Context) Option {
+	return contextOption{ctx: ctx}
+}
+
 // DefaultSignatureVersion "1.0"
 const DefaultSignatureVersion = "1.0"
 
@@ -62,6 +67,9 @@ const (
 	QuerySendDetails = "QuerySendDetails"
 )
 
+// CodeOK is value of "Code" in a successful Response
+const CodeOK = "OK"
+
 const (
 	// JSON is value of system param "Format"
 	JSON FormatType = "JSON"
@@ -97,27 +105,20 @@ func (ts Timestamp) MarshalJSON() ([]byte, error) {
 }
 
 // ReqHandler for aliyun sms api request
+// the response is decoded by its Content-Type, or Format of the request
+// if Content-Type is not json or xml
 type ReqHandler interface {
-	DoReq(opts Options) ([]byte, error)
-}
-
-type defaultReqHandler struct{}
-
-func (h defaultReqHandler) DoReq(opts Options) ([]byte, error) {
-	resp, err := http.Get(opts.URL())
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	return body, nil
+	DoReq(opts Options) (*HTTPResponse, error)
 }
 
 type action interface {
 	Client() Client
+
+	// Build the signed request without sending it, see Presign
+	Build(ctx context.Context, extOpts ...Option) (*http.Request, error)
+
+	// Presign the url of the request without sending it
+	Presign(extOpts ...Option) (PresignedURL, error)
 }
 
 type baseAction struct {
@@ -125,6 +126,9 @@ type baseAction struct {
 	businessParams interface{}
 	responseType   reflect.Type
 	reqHandler     ReqHandler
+
+	// validate business params before the request is built
+	validate func(opts Options) error
 }
 
 func (a *baseAction) Client() Client {
@@ -151,6 +155,7 @@ func (a *baseAction) generateOpts(extOpts ...Option) (*options, error) {
 
 	opts.businessParams = a.businessParams
 	opts.reqHandler = a.reqHandler
+	opts.ctx = context.Background()
 
 	for _, opt := range extOpts {
 		opt.Apply(&opts)
@@ -161,19 +166,40 @@ func (a *baseAction) generateOpts(extOpts ...Option) (*options, error) {
 	return &opts, nil
 }
 
-func (a *baseAction) doAction(extOpts ...Option) (*options, error) {
+// build validates params and signs the url of the request
+func (a *baseAction) build(extOpts ...Option) (*options, error) {
 	opts, err := a.generateOpts(extOpts...)
 	if err != nil {
 		return nil, err
 	}
+	if f := opts.Format(); f != JSON && f != XML {
+		return nil, errUnknownFormat(f)
+	}
+	if a.validate != nil {
+		if err := a.validate(opts); err != nil {
+			return nil, err
+		}
+	}
 	err = opts.generateURL()
 	if err != nil {
 		return nil, err
 	}
+	return opts, nil
+}
+
+func (a *baseAction) doAction(extOpts ...Option) (*options, error) {
+	opts, err := a.build(extOpts...)
+	if err != nil {
+		return nil, err
+	}
 
-	data, err := opts.reqHandler.DoReq(opts)
+	res, err := opts.reqHandler.DoReq(opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.httpRes = res
 
-	err = opts.processResponse(data)
+	err = opts.processResponse(res)
 	if err != nil {
 		return nil, err
 	}
@@ -187,11 +213,38 @@ type Response struct {
 	Message   string `json:"Message" xml:"Message"`
 }
 
+// Err returns an *Error if Code of the Response is not "OK"
+func (r Response) Err() error {
+	if r.Code == CodeOK {
+		return nil
+	}
+	return &Error{RequestID: r.RequestID, Code: r.Code, Message: r.Message}
+}
+
+// Error represents a failed api response
+type Error struct {
+	RequestID string
+	Code      string
+	Message   string
+}
+
+func (e *Error) Error() string {
+	return "sms: " + e.Code + ": " + e.Message + " (RequestId: " + e.RequestID + ")"
+}
+
 // Config of client
 type Config struct {
 	AccessKeyID  string
 	AccessSecret string
 	Endpoint     string
+
+	// Templates is optional, if it's set, TemplateParam of action "SendSms"
+	// is validated against placeholders of the registered template
+	Templates *TemplateRegistry
+
+	// Suppression is optional, if it's set, suppressed numbers are filtered out of
+	// PhoneNumbers of action "SendSms" and Messages of action "SendBatchSms"
+	Suppression *SuppressionList
 }
 
 // Client of aliyun sms
@@ -234,11 +287,16 @@ type Options interface {
 	URL() string
 	EndPoint() string
 	AccessSecret() string
+	Context() context.Context
+
+	// HTTPResponse returns the response of ReqHandler, nil before the request is sent
+	HTTPResponse() *HTTPResponse
 
 	SetSignatureNonce(s SignatureNonce)
 	SetFormatType(f FormatType)
 	SetTimestamp(ts Timestamp)
 	SetReqHandler(reqHandler ReqHandler)
+	SetContext(ctx context.Context)
 }
 
 type options struct {
@@ -248,7 +306,9 @@ type options struct {
 	endPoint       string
 
 	reqHandler ReqHandler
+	ctx        context.Context
 	res        interface{}
+	httpRes    *HTTPResponse
 	url        string
 }
 
@@ -268,6 +328,18 @@ func (opts *options) SetReqHandler(reqHandler ReqHandler) {
 	opts.reqHandler = reqHandler
 }
 
+func (opts *options) SetContext(ctx context.Context) {
+	opts.ctx = ctx
+}
+
+func (opts *options) Context() context.Context {
+	return opts.ctx
+}
+
+func (opts *options) HTTPResponse() *HTTPResponse {
+	return opts.httpRes
+}
+
 func (opts *options) URL() string {
 	return opts.url
 }
@@ -338,63 +410,37 @@ func (handlerOpt reqHandlerOption) Apply(opts Options) {
 	opts.SetReqHandler(handlerOpt.handler)
 }
 
-func (opts *options) generateURL() (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			if _, ok := r.(runtime.Error); ok {
-				panic(r)
-			}
-			if s, ok := r.(string); ok {
-				panic(s)
-			}
-			err = r.(error)
-		}
-	}()
-
-	sortedQueryString := opts.sortedQueryString()
-	opts.sign(sortedQueryString)
-
-	opts.url = opts.endPoint + "?Signature=" + opts.systemParams.Signature + "&" + sortedQueryString
-
-	return nil
+// Apply option Context
+func (ctxOpt contextOption) Apply(opts Options) {
+	opts.SetContext(ctxOpt.ctx)
 }
 
-func (opts *options) sign(sortedQueryString string) {
-	stringToSign := HTTPMethod + "&" + specialQueryEscape("/") + "&" + specialQueryEscape(sortedQueryString)
+func (opts *options) generateURL() error {
+	data := url.Values{}
+	if err := prepareParameters(&data, opts.systemParams, opts.businessParams); err != nil {
+		return err
+	}
 
 	// The signature method is supposed to be HmacSHA1
 	// A switch case is required if there is other methods available
-	mac := hmac.New(sha1.New, []byte(opts.accessSecret+"&"))
-	mac.Write([]byte(stringToSign))
-	signData := mac.Sum(nil)
+	opts.systemParams.Signature = signer.PercentEncode(signer.Sign(HTTPMethod, data, opts.accessSecret))
 
-	opts.systemParams.Signature = specialQueryEscape(base64.StdEncoding.EncodeToString(signData))
-}
-
-func (opts *options) sortedQueryString() string {
-	data := url.Values{}
+	opts.url = opts.endPoint + "?Signature=" + opts.systemParams.Signature + "&" + signer.SortedQueryString(data)
 
-	prepareParameters(&data, opts.systemParams, opts.businessParams)
+	return nil
+}
 
-	// data.Encode() encodes the value sorted by key
-	return specialURLEncode(data.Encode())
+func (opts *options) processResponse(res *HTTPResponse) error {
+	return decodeResponse(res, opts.systemParams.Format, opts.res)
 }
 
-func (opts *options) processResponse(data []byte) error {
-	var err error
-	switch opts.systemParams.Format {
-	case XML:
-		err = xml.Unmarshal(data, opts.res)
-	case JSON:
-		err = json.Unmarshal(data, opts.res)
-	}
-	if err != nil {
-		return err
-	}
-	return nil
+// ParamEncoder is implemented by param values which encode themselves,
+// it's preferred over fmt.Sprintf("%v") when preparing parameters
+type ParamEncoder interface {
+	EncodeParam() (string, error)
 }
 
-func prepareParameters(data *url.Values, params ...interface{}) {
+func prepareParameters(data *url.Values, params ...interface{}) error {
 	for _, p := range params {
 		v := reflect.ValueOf(p)
 
@@ -408,9 +454,15 @@ func prepareParameters(data *url.Values, params ...interface{}) {
 
 			tag, tagOptions := parseTag(param)
 
+			if tag == "-" {
+				continue
+			}
+
 			if tag == "" {
 				if k := v.Field(i).Kind(); k == reflect.Ptr || k == reflect.Interface {
-					prepareParameters(data, v.Field(i).Elem().Interface())
+					if err := prepareParameters(data, v.Field(i).Elem().Interface()); err != nil {
+						return err
+					}
 				}
 				continue
 			}
@@ -420,20 +472,19 @@ func prepareParameters(data *url.Values, params ...interface{}) {
 				continue
 			}
 
+			if encoder, ok := v.Field(i).Interface().(ParamEncoder); ok {
+				value, err := encoder.EncodeParam()
+				if err != nil {
+					return err
+				}
+				data.Set(tag, value)
+				continue
+			}
+
 			data.Set(tag, fmt.Sprintf("%v", v.Field(i)))
 		}
 	}
-}
-
-func specialQueryEscape(s string) string {
-	return specialURLEncode(url.QueryEscape(s))
-}
-
-func specialURLEncode(s string) string {
-	s = strings.Replace(s, "+", "%20", -1)
-	s = strings.Replace(s, "*", "%2A", -1)
-	s = strings.Replace(s, "%7E", "~", -1)
-	return s
+	return nil
 }
 
 // tagOptions is the string following a comma in a struct field's "json"
@@ -0,0 +1,111 @@
+package sms
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind is the classification of Code of *Error
+type ErrorKind int
+
+const (
+	// KindUnknown is any Code not classified
+	KindUnknown ErrorKind = iota
+
+	// KindThrottling means the request is rejected by flow control
+	KindThrottling
+
+	// KindQuota means the account is out of balance or quota
+	KindQuota
+
+	// KindAuth means the access key is invalid or not permitted
+	KindAuth
+
+	// KindInvalidParams means the params, template or sign are rejected
+	KindInvalidParams
+
+	// KindSystem means an internal error of aliyun sms
+	KindSystem
+)
+
+// String returns the name of ErrorKind
+func (k ErrorKind) String() string {
+	switch k {
+	case KindUnknown:
+		return "unknown"
+	case KindThrottling:
+		return "throttling"
+	case KindQuota:
+		return "quota"
+	case KindAuth:
+		return "auth"
+	case KindInvalidParams:
+		return "invalid-params"
+	case KindSystem:
+		return "system"
+	}
+	return "ErrorKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+var errorKinds = map[string]ErrorKind{
+	"isv.BUSINESS_LIMIT_CONTROL": KindThrottling,
+	"isv.DAY_LIMIT_CONTROL":      KindThrottling,
+	"Throttling.User":            KindThrottling,
+	"Throttling":                 KindThrottling,
+
+	"isv.AMOUNT_NOT_ENOUGH": KindQuota,
+	"isv.OUT_OF_SERVICE":    KindQuota,
+	"isv.ACCOUNT_ABNORMAL":  KindQuota,
+
+	"isv.ACCOUNT_NOT_EXISTS":       KindAuth,
+	"isp.RAM_PERMISSION_DENY":      KindAuth,
+	"InvalidAccessKeyId.NotFound":  KindAuth,
+	"InvalidAccessKeyId.Inactive":  KindAuth,
+	"SignatureDoesNotMatch":        KindAuth,
+	"Forbidden.RAM":                KindAuth,
+	"isv.PRODUCT_UN_SUBSCRIPT":     KindAuth,
+	"InvalidTimeStamp.Expired":     KindAuth,
+	"SignatureNonceUsed":           KindAuth,
+	"IncompleteSignature":          KindAuth,
+	"MissingSecurityToken":         KindAuth,
+	"InvalidSecurityToken.Expired": KindAuth,
+
+	"isp.SYSTEM_ERROR":   KindSystem,
+	"ServiceUnavailable": KindSystem,
+	"InternalError":      KindSystem,
+}
+
+// Kind returns the classification of Code
+// Codes start with "isv." and not classified otherwise are KindInvalidParams
+func (e *Error) Kind() ErrorKind {
+	if k, ok := errorKinds[e.Code]; ok {
+		return k
+	}
+	if strings.HasPrefix(e.Code, "isv.") || strings.HasPrefix(e.Code, "Missing") ||
+		strings.HasPrefix(e.Code, "Invalid") {
+		return KindInvalidParams
+	}
+	return KindUnknown
+}
+
+// IsTemporary reports whether the request of err may succeed if it's retried later
+// *HTTPError is temporary only of status 5xx or 429,
+// errs other than *Error, *HTTPError, ValidationError and *SuppressedError, e.g. network errs, are temporary
+func IsTemporary(err error) bool {
+	switch err := err.(type) {
+	case nil:
+		return false
+	case ValidationError, *SuppressedError:
+		return false
+	case *Error:
+		switch err.Kind() {
+		case KindThrottling, KindQuota, KindSystem:
+			return true
+		}
+		return false
+	case *HTTPError:
+		return err.StatusCode >= 500 || err.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package sms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError_Kind(t *testing.T) {
+	cases := map[string]ErrorKind{
+		"isv.BUSINESS_LIMIT_CONTROL":  KindThrottling,
+		"isv.AMOUNT_NOT_ENOUGH":       KindQuota,
+		"InvalidAccessKeyId.NotFound": KindAuth,
+		"isv.MOBILE_NUMBER_ILLEGAL":   KindInvalidParams,
+		"MissingPhoneNumbers":         KindInvalidParams,
+		"isp.SYSTEM_ERROR":            KindSystem,
+		"Unexpected":                  KindUnknown,
+	}
+	for code, want := range cases {
+		if k := (&Error{Code: code}).Kind(); k != want {
+			t.Errorf("Kind(%s): %v != %v", code, k, want)
+		}
+	}
+}
+
+func TestIsTemporary(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("EOF"), true},
+		{ValidationError{}, false},
+		{&Error{Code: "isv.BUSINESS_LIMIT_CONTROL"}, true},
+		{&Error{Code: "isp.SYSTEM_ERROR"}, true},
+		{&Error{Code: "isv.MOBILE_NUMBER_ILLEGAL"}, false},
+		{&Error{Code: "InvalidAccessKeyId.NotFound"}, false},
+		{(Response{Code: "isv.AMOUNT_NOT_ENOUGH"}).Err(), true},
+		{&HTTPError{StatusCode: 502}, true},
+		{&HTTPError{StatusCode: 429}, true},
+		{&HTTPError{StatusCode: 404}, false},
+		{&HTTPError{StatusCode: 200}, false},
+	}
+	for _, cs := range cases {
+		if IsTemporary(cs.err) != cs.want {
+			t.Errorf("IsTemporary(%v) != %v", cs.err, cs.want)
+		}
+	}
+}
@@ -5,10 +5,16 @@ import (
 	"log"
 	"reflect"
 	"time"
+
+	"github.com/scistack/aliyun-sms-go/smstest"
 )
 
-func ExampleSendAction() {
-	c := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret"})
+func ExampleNewSendAction() {
+	// a fake of aliyun sms api, use DefaultEndPoint in production
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+
+	c := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Endpoint: srv.Endpoint()})
 	tp := map[string]string{"version": "v1.0"}
 
 	a := NewSendAction(c, SendSmsParams{
@@ -46,7 +52,10 @@ func ExampleSendAction() {
 }
 
 func ExampleNewQuerySendDetailsAction() {
-	c := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret"})
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+
+	c := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Endpoint: srv.Endpoint()})
 
 	// RegionId is optional here
 	// in official http api doc, RegionId
@@ -72,7 +81,6 @@ func ExampleNewQuerySendDetailsAction() {
 	fmt.Println(opts.Version())
 	fmt.Println(opts.RegionID())
 	fmt.Println(opts.PhoneNumber())
-	fmt.Println(opts.SendDate())
 	fmt.Println(opts.CurrentPage())
 	fmt.Println(opts.PageSize())
 
@@ -82,7 +90,6 @@ func ExampleNewQuerySendDetailsAction() {
 	// 2017-05-25
 	// cn-hangzhou
 	// 15300000001
-	// 20180502
 	// 2
 	// 50
 }
@@ -0,0 +1,193 @@
+package sms
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxBodyBytes is default upper limit of bytes of a response body read by HTTPReqHandler
+const DefaultMaxBodyBytes = 1 << 20
+
+// RequestIDHeader is the header of the request id of aliyun sms api
+const RequestIDHeader = "x-acs-request-id"
+
+// ErrBodyTooLarge is returned from HTTPReqHandler if the response body exceeds MaxBodyBytes
+var ErrBodyTooLarge = errors.New("sms: response body too large")
+
+// HTTPResponse of aliyun sms api returned by ReqHandler
+type HTTPResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Duration from sending the request to reading the whole body
+	Duration time.Duration
+}
+
+// ContentType returns the media type of header "Content-Type" without params,
+// empty if it's missing or malformed
+func (r *HTTPResponse) ContentType() string {
+	t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return t
+}
+
+// RequestID returns header "x-acs-request-id"
+func (r *HTTPResponse) RequestID() string {
+	return r.Header.Get(RequestIDHeader)
+}
+
+// HTTPError is returned if the body of a response is not a Response of aliyun sms api,
+// e.g. an error page of a proxy
+type HTTPError struct {
+	StatusCode int
+	RequestID  string
+
+	// Body is the leading bytes of the body
+	Body string
+
+	// Err is the err of decoding the body
+	Err error
+}
+
+func (e *HTTPError) Error() string {
+	msg := "sms: http status " + strconv.Itoa(e.StatusCode) + ": " + e.Err.Error()
+	if e.RequestID != "" {
+		msg += " (RequestId: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// maxErrorBody is upper limit of bytes of Body of HTTPError
+const maxErrorBody = 512
+
+// HTTPReqHandler is the ReqHandler requests aliyun sms api by http, it's the default ReqHandler
+type HTTPReqHandler struct {
+	// Client sends requests, default http.DefaultClient
+	Client *http.Client
+
+	// MaxBodyBytes is upper limit of bytes of a response body, default DefaultMaxBodyBytes
+	MaxBodyBytes int64
+}
+
+// DoReq implements ReqHandler
+// ErrBodyTooLarge is returned if the body exceeds MaxBodyBytes
+func (h HTTPReqHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	req, err := http.NewRequest(HTTPMethod, opts.URL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client, max := h.Client, h.MaxBodyBytes
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if max <= 0 {
+		max = DefaultMaxBodyBytes
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(opts.Context()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > max {
+		return nil, ErrBodyTooLarge
+	}
+	return &HTTPResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body, Duration: time.Since(start)}, nil
+}
+
+// unknownFormatError is the err of an unsupported Format
+type unknownFormatError FormatType
+
+func (e unknownFormatError) Error() string {
+	return fmt.Sprintf("sms: unknown Format %q, expected JSON or XML", string(e))
+}
+
+// errUnknownFormat returns the err of an unsupported Format
+func errUnknownFormat(f FormatType) error {
+	return unknownFormatError(f)
+}
+
+// responseFormat returns the format of the body of res,
+// it's decided by Content-Type, or Format of the request if Content-Type is not json or xml
+func responseFormat(res *HTTPResponse, format FormatType) (FormatType, error) {
+	switch t := res.ContentType(); {
+	case t == "application/json" || t == "text/json":
+		return JSON, nil
+	case t == "application/xml" || t == "text/xml":
+		return XML, nil
+	}
+	if format != JSON && format != XML {
+		return "", errUnknownFormat(format)
+	}
+	return format, nil
+}
+
+// baseResponse is implemented by responses embedding Response
+type baseResponse interface {
+	base() *Response
+}
+
+func (r *Response) base() *Response {
+	return r
+}
+
+// errNoCode is Err of HTTPError of a non 2xx response without Code in the body
+var errNoCode = errors.New("no Code in the body")
+
+// httpError returns the *HTTPError of res
+func httpError(res *HTTPResponse, err error) *HTTPError {
+	body := res.Body
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return &HTTPError{StatusCode: res.StatusCode, RequestID: res.RequestID(), Body: string(body), Err: err}
+}
+
+// decodeResponse decodes the body of res into v,
+// RequestId of v is filled by header "x-acs-request-id" if it's missing in the body
+// an *HTTPError is returned if the body of a non 2xx response can not be decoded,
+// or it has no Code, e.g. "{}" of a proxy
+func decodeResponse(res *HTTPResponse, format FormatType, v interface{}) error {
+	f, err := responseFormat(res, format)
+	if err != nil {
+		return err
+	}
+	switch f {
+	case XML:
+		err = xml.Unmarshal(res.Body, v)
+	default:
+		err = json.Unmarshal(res.Body, v)
+	}
+	ok := res.StatusCode/100 == 2
+	if err != nil {
+		if !ok {
+			return httpError(res, err)
+		}
+		return err
+	}
+	if b, isBase := v.(baseResponse); isBase {
+		if !ok && b.base().Code == "" {
+			return httpError(res, errNoCode)
+		}
+		if b.base().RequestID == "" {
+			b.base().RequestID = res.RequestID()
+		}
+	}
+	return nil
+}
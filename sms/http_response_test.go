@@ -0,0 +1,126 @@
+package sms
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newHTTPTestClient(h http.HandlerFunc) (Client, *httptest.Server) {
+	srv := httptest.NewServer(h)
+	return NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Endpoint: srv.URL + "/"}), srv
+}
+
+var testSendParams = SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+
+func TestHTTPReqHandler(t *testing.T) {
+	c, srv := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		w.Header().Set(RequestIDHeader, "R")
+		w.Write([]byte(`{"Message":"OK","BizId":"B^0","Code":"OK"}`))
+	})
+	defer srv.Close()
+
+	opts, err := NewSendAction(c, testSendParams).Do()
+	if err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	res := opts.HTTPResponse()
+	if res.StatusCode != http.StatusOK || res.ContentType() != "application/json" || res.RequestID() != "R" || res.Duration <= 0 {
+		t.Errorf("HTTPResponse: %+v", res)
+	}
+	// RequestId missing in the body is filled by the header
+	if r := opts.Response(); r.BizID != "B^0" || r.RequestID != "R" {
+		t.Errorf("Response: %+v", r)
+	}
+}
+
+func TestHTTPReqHandler_BodyTooLarge(t *testing.T) {
+	c, srv := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Message":"` + strings.Repeat("a", 100) + `"}`))
+	})
+	defer srv.Close()
+
+	_, err := NewSendAction(c, testSendParams).Do(ReqHandlerOption(HTTPReqHandler{MaxBodyBytes: 100}))
+	if err != ErrBodyTooLarge {
+		t.Errorf("Do err: %v", err)
+	}
+	if _, err := NewSendAction(c, testSendParams).Do(ReqHandlerOption(HTTPReqHandler{MaxBodyBytes: 200})); err != nil {
+		t.Errorf("Do err: %v", err)
+	}
+}
+
+func TestDecodeResponse_ContentType(t *testing.T) {
+	// the body is decoded by Content-Type, whatever Format of the request is
+	c, srv := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml;charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`<?xml version='1.0' encoding='UTF-8'?><Error><RequestId>R</RequestId><Code>InvalidAccessKeyId.NotFound</Code><Message>Specified access key is not found.</Message></Error>`))
+	})
+	defer srv.Close()
+
+	opts, err := NewSendAction(c, testSendParams).Do()
+	if err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	if e, ok := opts.Response().Err().(*Error); !ok || e.Code != "InvalidAccessKeyId.NotFound" || e.RequestID != "R" {
+		t.Errorf("Response err: %v", opts.Response().Err())
+	}
+}
+
+func TestDecodeResponse_HTTPError(t *testing.T) {
+	c, srv := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set(RequestIDHeader, "R")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`<html>` + strings.Repeat("a", 1000) + `</html>`))
+	})
+	defer srv.Close()
+
+	_, err := NewSendAction(c, testSendParams).Do()
+	e, ok := err.(*HTTPError)
+	if !ok || e.StatusCode != http.StatusBadGateway || e.RequestID != "R" || len(e.Body) != maxErrorBody {
+		t.Errorf("Do err: %v", err)
+	}
+}
+
+func TestDecodeResponse_NoCode(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		err    error
+	}{
+		// a proxy
+		{http.StatusBadGateway, `{}`, errNoCode},
+		// errors of aliyun sms api are non 2xx responses with Code
+		{http.StatusBadRequest, `{"Message":"签名不合法","RequestId":"R","Code":"isv.SMS_SIGNATURE_ILLEGAL"}`, nil},
+	}
+	for _, cs := range cases {
+		res := &HTTPResponse{StatusCode: cs.status, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(cs.body)}
+		err := decodeResponse(res, JSON, &SendSmsResponse{})
+		if e, ok := err.(*HTTPError); cs.err != nil && (!ok || e.Err != cs.err || e.StatusCode != cs.status) || cs.err == nil && err != nil {
+			t.Errorf("decodeResponse(%d %s): %v", cs.status, cs.body, err)
+		}
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	var requests int32
+	c, srv := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"Code":"OK"}`))
+	})
+	defer srv.Close()
+
+	_, err := NewSendAction(c, testSendParams).Do(FormatType("YAML"))
+	if err == nil || !strings.Contains(err.Error(), `unknown Format "YAML"`) || atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("Do err: %v", err)
+	}
+
+	// a response without Content-Type of a request of unknown Format can not be decoded
+	if err := decodeResponse(bodyResponse([]byte(`{}`)), FormatType("YAML"), &SendSmsResponse{}); err == nil {
+		t.Error("decodeResponse of unknown Format")
+	}
+}
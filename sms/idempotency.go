@@ -0,0 +1,366 @@
+package sms
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyWindow is default window of IdempotentReqHandler
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// ErrIdempotencyKeyReused is returned from IdempotentReqHandler if the idempotency key
+// of a stored or in-flight request is reused by a request of different business params
+var ErrIdempotencyKeyReused = errors.New("sms: idempotency key is reused by a different request")
+
+type idempotencyKeyCtxKey struct{}
+
+type idempotencyScopeCtxKey struct{}
+
+// requestedNumbersCtxKey carries PhoneNumbers of the caller of action "SendSms"
+// before suppressed numbers are filtered out
+type requestedNumbersCtxKey struct{}
+
+// requestedNumbersOption sets PhoneNumbers of the caller to the context of the api request
+type requestedNumbersOption string
+
+// Apply option requestedNumbersOption
+func (o requestedNumbersOption) Apply(opts Options) {
+	opts.SetContext(context.WithValue(opts.Context(), requestedNumbersCtxKey{}, string(o)))
+}
+
+// WithIdempotencyKey returns a context carries key,
+// it's used by IdempotentReqHandler instead of OutId
+// set it to the api request by ContextOption
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+// WithIdempotencyScope returns a context carries scope, idempotency keys of IdempotentReqHandler
+// are prefixed with it, so that keys of different scopes never collide, e.g. callers of a gateway
+func WithIdempotencyScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, idempotencyScopeCtxKey{}, scope)
+}
+
+func idempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
+
+func idempotencyScope(ctx context.Context) string {
+	scope, _ := ctx.Value(idempotencyScopeCtxKey{}).(string)
+	return scope
+}
+
+// IdempotencyRecord is a stored successful response of action "SendSms"
+type IdempotencyRecord struct {
+	// Fingerprint is the hash of business params of the request
+	Fingerprint string
+	Response    SendSmsResponse
+}
+
+// IdempotencyStore stores successful responses of action "SendSms" by idempotency key
+// implementations must be concurrent safe
+type IdempotencyStore interface {
+	// Get the record of key, nil is returned if it does not exist or expired
+	Get(key string) (*IdempotencyRecord, error)
+
+	// Put the record of key, it expires after ttl
+	Put(key string, rec IdempotencyRecord, ttl time.Duration) error
+}
+
+// IdempotentReqHandler is a ReqHandler which makes action "SendSms" idempotent
+//
+// the idempotency key is the one set by WithIdempotencyKey, or OutId with the hash of
+// PhoneNumbers, SignName, TemplateCode and TemplateParam if it's not set,
+// requests without a key and other actions are passed to the next ReqHandler as is
+//
+// if a request of the same key succeeded within the window, the stored response is returned
+// instead of requesting aliyun sms, concurrent requests of the same key are collapsed
+// into one request of the next ReqHandler
+// ErrIdempotencyKeyReused is returned if a key set by WithIdempotencyKey is reused
+// by a request of different business params
+type IdempotentReqHandler struct {
+	store  IdempotencyStore
+	window time.Duration
+	next   ReqHandler
+
+	// OnStoreError is called if a successful response fails to be stored,
+	// the response is still returned since the sms is sent, so a retry of the same key may send it again
+	// it must be set before the handler is used
+	OnStoreError func(key string, err error)
+
+	mu    sync.Mutex
+	calls map[string]*idempotentCall
+}
+
+type idempotentCall struct {
+	done        chan struct{}
+	fingerprint string
+	res         *HTTPResponse
+	format      FormatType
+	err         error
+}
+
+// NewIdempotentReqHandler init an IdempotentReqHandler
+// window is DefaultIdempotencyWindow if it's not positive,
+// next is the default http ReqHandler if it's nil
+func NewIdempotentReqHandler(store IdempotencyStore, window time.Duration, next ReqHandler) *IdempotentReqHandler {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	if next == nil {
+		next = HTTPReqHandler{}
+	}
+	return &IdempotentReqHandler{store: store, window: window, next: next, calls: make(map[string]*idempotentCall)}
+}
+
+// fingerprint returns the hash of business params of action "SendSms" in query,
+// PhoneNumbers of the caller in ctx are hashed if numbers are suppressed,
+// so that retries are of the same fingerprint even if suppressions change
+func fingerprint(ctx context.Context, query url.Values) string {
+	h := sha256.New()
+	for _, name := range []string{"OutId", "PhoneNumbers", "SignName", "TemplateCode", "TemplateParam"} {
+		v := query.Get(name)
+		if numbers, ok := ctx.Value(requestedNumbersCtxKey{}).(string); ok && name == "PhoneNumbers" {
+			v = numbers
+		}
+		io.WriteString(h, v)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DoReq implements ReqHandler
+// stored responses are returned as 200 responses of the Format of the request
+func (h *IdempotentReqHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	fp := fingerprint(opts.Context(), query)
+	key := idempotencyKey(opts.Context())
+	if key != "" {
+		key = "key:" + key
+	} else if outID := query.Get("OutId"); outID != "" {
+		// a reused OutId of a different request is a different key
+		key = "out:" + outID + ":" + fp
+	}
+	if query.Get("Action") != SendSms || key == "" {
+		return h.next.DoReq(opts)
+	}
+	if scope := idempotencyScope(opts.Context()); scope != "" {
+		key = scope + ":" + key
+	}
+
+	for {
+		rec, err := h.store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			if rec.Fingerprint != fp {
+				return nil, ErrIdempotencyKeyReused
+			}
+			return encodeSendResponse(rec.Response, opts.Format())
+		}
+
+		h.mu.Lock()
+		call, ok := h.calls[key]
+		if !ok {
+			call = &idempotentCall{done: make(chan struct{}), fingerprint: fp, format: opts.Format()}
+			h.calls[key] = call
+			h.mu.Unlock()
+
+			call.res, call.err = h.do(key, fp, opts)
+
+			h.mu.Lock()
+			delete(h.calls, key)
+			h.mu.Unlock()
+			close(call.done)
+			return call.res, call.err
+		}
+		h.mu.Unlock()
+		if call.fingerprint != fp {
+			return nil, ErrIdempotencyKeyReused
+		}
+
+		select {
+		case <-call.done:
+		case <-opts.Context().Done():
+			return nil, opts.Context().Err()
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		if call.format == opts.Format() {
+			return call.res, nil
+		}
+		// the response is stored if it's successful, or the request is retried
+		res, err := decodeSendResponse(call.res, call.format)
+		if err != nil {
+			return nil, err
+		}
+		if res.Code != CodeOK {
+			return encodeSendResponse(*res, opts.Format())
+		}
+	}
+}
+
+func (h *IdempotentReqHandler) do(key, fp string, opts Options) (*HTTPResponse, error) {
+	httpRes, err := h.next.DoReq(opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := decodeSendResponse(httpRes, opts.Format())
+	if err != nil {
+		return nil, err
+	}
+	if res.Code == CodeOK {
+		// the sms is sent, an err of the store must not make the caller retry it
+		if err := h.store.Put(key, IdempotencyRecord{Fingerprint: fp, Response: *res}, h.window); err != nil && h.OnStoreError != nil {
+			h.OnStoreError(key, err)
+		}
+	}
+	return httpRes, nil
+}
+
+func decodeSendResponse(httpRes *HTTPResponse, format FormatType) (*SendSmsResponse, error) {
+	res := &SendSmsResponse{}
+	if err := decodeResponse(httpRes, format, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func encodeSendResponse(res SendSmsResponse, format FormatType) (*HTTPResponse, error) {
+	var data []byte
+	var err error
+	header := http.Header{}
+	switch format {
+	case XML:
+		data, err = xml.Marshal(res)
+		header.Set("Content-Type", "text/xml;charset=utf-8")
+	default:
+		data, err = json.Marshal(res)
+		header.Set("Content-Type", "application/json;charset=utf-8")
+	}
+	if err != nil {
+		return nil, err
+	}
+	header.Set(RequestIDHeader, res.RequestID)
+	return &HTTPResponse{StatusCode: http.StatusOK, Header: header, Body: data}, nil
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore,
+// the least recently used records are evicted if it's full
+type MemoryIdempotencyStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type memoryIdempotencyItem struct {
+	key     string
+	rec     IdempotencyRecord
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore init a MemoryIdempotencyStore stores at most capacity records
+func NewMemoryIdempotencyStore(capacity int) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := e.Value.(*memoryIdempotencyItem)
+	if !time.Now().Before(item.expires) {
+		s.ll.Remove(e)
+		delete(s.items, key)
+		return nil, nil
+	}
+	s.ll.MoveToFront(e)
+	rec := item.rec
+	return &rec, nil
+}
+
+// Put implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Put(key string, rec IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := &memoryIdempotencyItem{key: key, rec: rec, expires: time.Now().Add(ttl)}
+	if e, ok := s.items[key]; ok {
+		e.Value = item
+		s.ll.MoveToFront(e)
+		return nil
+	}
+	s.items[key] = s.ll.PushFront(item)
+	for s.capacity > 0 && s.ll.Len() > s.capacity {
+		e := s.ll.Back()
+		s.ll.Remove(e)
+		delete(s.items, e.Value.(*memoryIdempotencyItem).key)
+	}
+	return nil
+}
+
+// KV is a key-value store shared by processes, e.g. redis or memcached
+type KV interface {
+	// Get the value of key, ok is false if it does not exist or expired
+	Get(key string) (value []byte, ok bool, err error)
+
+	// Set the value of key, it expires after ttl
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// SharedIdempotencyStore is an IdempotencyStore of a KV shared by processes,
+// records are stored as JSON
+type SharedIdempotencyStore struct {
+	kv     KV
+	prefix string
+}
+
+// NewSharedIdempotencyStore init a SharedIdempotencyStore, keys are prefixed with prefix in kv
+func NewSharedIdempotencyStore(kv KV, prefix string) *SharedIdempotencyStore {
+	return &SharedIdempotencyStore{kv: kv, prefix: prefix}
+}
+
+// Get implements IdempotencyStore
+func (s *SharedIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	data, ok, err := s.kv.Get(s.prefix + key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	rec := &IdempotencyRecord{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Put implements IdempotencyStore
+func (s *SharedIdempotencyStore) Put(key string, rec IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(s.prefix+key, data, ttl)
+}
@@ -0,0 +1,216 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// testIdempotentHandler counts requests, every request waits for release
+type testIdempotentHandler struct {
+	mu      sync.Mutex
+	n       int
+	release chan struct{}
+}
+
+func (h *testIdempotentHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	h.mu.Lock()
+	h.n++
+	h.mu.Unlock()
+	if h.release != nil {
+		<-h.release
+	}
+	return testSendHandler{}.DoReq(opts)
+}
+
+func (h *testIdempotentHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.n
+}
+
+func TestIdempotentReqHandler(t *testing.T) {
+	h := &testIdempotentHandler{}
+	ih := NewIdempotentReqHandler(NewMemoryIdempotencyStore(10), time.Hour, h)
+	params := SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: outID}
+
+	for _, format := range []FormatType{JSON, XML, JSON} {
+		opts, err := NewSendAction(c, params).Do(ReqHandlerOption(ih), format)
+		if err != nil {
+			t.Fatalf("Do err: %v", err)
+		}
+		if res := opts.Response(); *res != rightSendSmsRes {
+			t.Errorf("Response(%s): %+v", format, res)
+		}
+	}
+	if h.count() != 1 {
+		t.Errorf("requests: %d != 1", h.count())
+	}
+
+	// explicit key
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	NewSendAction(c, params).Do(ReqHandlerOption(ih), ContextOption(ctx))
+	NewSendAction(c, params).Do(ReqHandlerOption(ih), ContextOption(ctx))
+	// no key
+	outIDParams := params
+	params.OutID = ""
+	NewSendAction(c, params).Do(ReqHandlerOption(ih))
+	NewSendAction(c, params).Do(ReqHandlerOption(ih))
+	if h.count() != 4 {
+		t.Errorf("requests: %d != 4", h.count())
+	}
+
+	// the same OutId of another request is sent
+	outIDParams.PhoneNumbers = "15300000002"
+	if _, err := NewSendAction(c, outIDParams).Do(ReqHandlerOption(ih)); err != nil || h.count() != 5 {
+		t.Errorf("Do of another request of OutId: %v, requests: %d", err, h.count())
+	}
+	// the same explicit key of another request is an err
+	if _, err := NewSendAction(c, outIDParams).Do(ReqHandlerOption(ih), ContextOption(ctx)); err != ErrIdempotencyKeyReused || h.count() != 5 {
+		t.Errorf("Do of another request of key: %v, requests: %d", err, h.count())
+	}
+	// keys of scopes never collide
+	scoped := WithIdempotencyScope(ctx, "scope")
+	if _, err := NewSendAction(c, outIDParams).Do(ReqHandlerOption(ih), ContextOption(scoped)); err != nil || h.count() != 6 {
+		t.Errorf("Do of another scope: %v, requests: %d", err, h.count())
+	}
+}
+
+func TestIdempotentReqHandler_Suppressed(t *testing.T) {
+	l := NewSuppressionList(nil)
+	sc := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Suppression: l})
+	h := &testIdempotentHandler{}
+	ih := NewIdempotentReqHandler(NewMemoryIdempotencyStore(10), time.Hour, h)
+	params := SendSmsParams{PhoneNumbers: "15300000001,15300000002", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: outID}
+
+	if _, err := NewSendAction(sc, params).Do(ReqHandlerOption(ih)); err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	// a number opted out before the retry doesn't make it another request
+	l.Add(phone.MustParse("15300000002"), SuppressionScope{}, "")
+	opts, err := NewSendAction(sc, params).Do(ReqHandlerOption(ih))
+	if err != nil || *opts.Response() != rightSendSmsRes || len(opts.Suppressed()) != 1 {
+		t.Errorf("Do retry: %+v, %v", opts, err)
+	}
+	if h.count() != 1 {
+		t.Errorf("requests: %d != 1", h.count())
+	}
+}
+
+type failingIdempotencyStore struct{}
+
+var errTestStore = errors.New("store is down")
+
+func (failingIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	return nil, nil
+}
+
+func (failingIdempotencyStore) Put(key string, rec IdempotencyRecord, ttl time.Duration) error {
+	return errTestStore
+}
+
+func TestIdempotentReqHandler_StoreError(t *testing.T) {
+	ih := NewIdempotentReqHandler(failingIdempotencyStore{}, time.Hour, &testIdempotentHandler{})
+	var storeErr error
+	ih.OnStoreError = func(key string, err error) {
+		storeErr = err
+	}
+	params := SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: outID}
+	// the sms is sent, the response is returned
+	opts, err := NewSendAction(c, params).Do(ReqHandlerOption(ih))
+	if err != nil || *opts.Response() != rightSendSmsRes {
+		t.Errorf("Do: %v", err)
+	}
+	if storeErr != errTestStore {
+		t.Errorf("OnStoreError: %v", storeErr)
+	}
+}
+
+func TestIdempotentReqHandler_Concurrent(t *testing.T) {
+	h := &testIdempotentHandler{release: make(chan struct{})}
+	ih := NewIdempotentReqHandler(NewMemoryIdempotencyStore(10), time.Hour, h)
+	params := SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: outID}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts, err := NewSendAction(c, params).Do(ReqHandlerOption(ih))
+			if err == nil && opts.Response().BizID != rightSendSmsRes.BizID {
+				t.Errorf("BizID: %s", opts.Response().BizID)
+			}
+			errs <- err
+		}()
+	}
+	for h.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// wait the others to join the in-flight request
+	time.Sleep(10 * time.Millisecond)
+	close(h.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Do err: %v", err)
+		}
+	}
+	if h.count() != 1 {
+		t.Errorf("requests: %d != 1", h.count())
+	}
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	s := NewMemoryIdempotencyStore(2)
+	rec := IdempotencyRecord{Fingerprint: "f", Response: rightSendSmsRes}
+	s.Put("a", rec, time.Hour)
+	s.Put("b", rec, time.Hour)
+	s.Get("a")
+	s.Put("c", rec, time.Hour)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if got, _ := s.Get(key); (got != nil) != want {
+			t.Errorf("Get(%s): %v", key, got)
+		}
+	}
+
+	s.Put("a", rec, -time.Second)
+	if got, _ := s.Get("a"); got != nil {
+		t.Errorf("Get expired: %v", got)
+	}
+}
+
+type testKV map[string][]byte
+
+func (kv testKV) Get(key string) ([]byte, bool, error) {
+	v, ok := kv[key]
+	return v, ok, nil
+}
+
+func (kv testKV) Set(key string, value []byte, ttl time.Duration) error {
+	kv[key] = value
+	return nil
+}
+
+func TestSharedIdempotencyStore(t *testing.T) {
+	kv := testKV{}
+	s := NewSharedIdempotencyStore(kv, "sms:")
+	rec := IdempotencyRecord{Fingerprint: "f", Response: rightSendSmsRes}
+	s.Put("a", rec, time.Hour)
+	if _, ok := kv["sms:a"]; !ok {
+		t.Errorf("kv: %v", kv)
+	}
+	if got, err := s.Get("a"); err != nil || *got != rec {
+		t.Errorf("Get: %v, %v", got, err)
+	}
+	if res, err := s.Get("b"); err != nil || res != nil {
+		t.Errorf("Get: %v, %v", res, err)
+	}
+}
@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PresignExpiry is how long a presigned url is accepted after its Timestamp,
+// aliyun api rejects requests whose Timestamp differs from its clock by more than it
+const PresignExpiry = 15 * time.Minute
+
+// PresignedURL is a signed url of an action, it can be requested once by method HTTPMethod
+// before Expires, SignatureNonce of it is rejected after the first request
+type PresignedURL struct {
+	URL     string
+	Expires time.Time
+}
+
+// Build the signed request of the action without sending it, e.g. to send it
+// by another http client, params are validated as Do does
+// ReqHandler, e.g. an IdempotentReqHandler, is not involved since the request is not sent by the action
+func (a *baseAction) Build(ctx context.Context, extOpts ...Option) (*http.Request, error) {
+	opts, err := a.build(append(append([]Option{}, extOpts...), ContextOption(ctx))...)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(HTTPMethod, opts.URL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return req.WithContext(ctx), nil
+}
+
+// Presign the url of the action without sending it, params are validated as Do does
+// set option Timestamp to sign it for a later time, within PresignExpiry of now
+func (a *baseAction) Presign(extOpts ...Option) (PresignedURL, error) {
+	opts, err := a.build(extOpts...)
+	if err != nil {
+		return PresignedURL{}, err
+	}
+	return PresignedURL{URL: opts.URL(), Expires: time.Time(opts.Timestamp()).Add(PresignExpiry)}, nil
+}
+
+// Curl returns a curl command of req for debugging, the body of req is not included
+// it contains the signature, which is valid until the request expires
+func Curl(req *http.Request) string {
+	cmd := []string{"curl", "-X", req.Method}
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			cmd = append(cmd, "-H", shellQuote(k+": "+v))
+		}
+	}
+	return strings.Join(append(cmd, shellQuote(req.URL.String())), " ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
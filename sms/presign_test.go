@@ -0,0 +1,106 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+var presignParams = SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: outID}
+
+func TestSendAction_Build(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+	c := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Endpoint: srv.Endpoint()})
+
+	req, err := NewSendAction(c, presignParams).Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build err: %v", err)
+	}
+	if len(srv.Messages()) != 0 {
+		t.Fatal("Build: the request is sent")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	var res SendSmsResponse
+	if err := json.Unmarshal(body, &res); err != nil || res.Code != CodeOK {
+		t.Fatalf("response: %s", body)
+	}
+	if m := srv.Messages(); len(m) != 1 || m[0].OutID != outID {
+		t.Errorf("Messages: %+v", m)
+	}
+
+	if _, err := NewSendAction(c, SendSmsParams{}).Build(context.Background()); err == nil {
+		t.Error("Build: params are not validated")
+	}
+
+	// the backing array of extOpts of the caller is not written
+	backing := []Option{FormatType("JSON"), FormatType("JSON")}
+	NewSendAction(c, presignParams).Build(context.Background(), backing[:1]...)
+	if backing[1] != FormatType("JSON") {
+		t.Errorf("Build: extOpts are appended in place: %v", backing[1])
+	}
+}
+
+func TestSendAction_Presign(t *testing.T) {
+	p, err := NewSendAction(c, presignParams).Presign(Timestamp(ts), SignatureNonce(u4))
+	if err != nil {
+		t.Fatalf("Presign err: %v", err)
+	}
+	if !p.Expires.Equal(ts.Add(PresignExpiry)) {
+		t.Errorf("Expires: %s", p.Expires)
+	}
+	if !strings.HasPrefix(p.URL, DefaultEndPoint+"?Signature=") || !strings.Contains(p.URL, "&SignatureNonce="+u4.String()+"&") {
+		t.Errorf("URL: %s", p.URL)
+	}
+
+	again, _ := NewSendAction(c, presignParams).Presign(Timestamp(ts), SignatureNonce(u4))
+	if again.URL != p.URL {
+		t.Errorf("URL: %s != %s", again.URL, p.URL)
+	}
+}
+
+func TestQuerySendDetailsAction_Presign(t *testing.T) {
+	now := time.Now()
+	params := QuerySendDetailsParams{PhoneNumber: "15300000001", SendDate: Date(now)}
+	p, err := NewQuerySendDetailsAction(c, params).Presign(Timestamp(now))
+	if err != nil || !strings.Contains(p.URL, "Action=QuerySendDetails") {
+		t.Errorf("Presign: %+v %v", p, err)
+	}
+
+	// SendDate is validated against Timestamp
+	if _, err := NewQuerySendDetailsAction(c, params).Presign(Timestamp(now.AddDate(0, 0, 31))); err == nil {
+		t.Error("Presign: SendDate is not validated")
+	}
+}
+
+func TestClientPool_Presign(t *testing.T) {
+	p := testPool(Failover, Account{Name: "a"}, Account{Name: "b"})
+	u, err := p.NewSendAction(poolParams).Presign()
+	if err != nil || !strings.Contains(u.URL, "AccessKeyId=a&") {
+		t.Errorf("Presign: %+v %v", u, err)
+	}
+	if _, err := NewClientPool(PoolConfig{}).NewSendAction(poolParams).Presign(); err != ErrNoAccount {
+		t.Errorf("Presign err: %v", err)
+	}
+}
+
+func TestCurl(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost/?a=1&b='2'", nil)
+	req.Header.Set("X-B", "b")
+	req.Header.Set("X-A", "a")
+	if cmd := Curl(req); cmd != `curl -X GET -H 'X-A: a' -H 'X-B: b' 'http://localhost/?a=1&b='\''2'\'''` {
+		t.Errorf("Curl: %s", cmd)
+	}
+}
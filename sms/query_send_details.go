@@ -132,6 +132,8 @@ func (p *QuerySendDetailsParams) cleanParams() {
 
 // NewQuerySendDetailsAction init an action "QuerySendDetails"
 // can be used concurrently
+// params are validated against Timestamp of the request,
+// if there are any problems, the ValidationError is returned from Do
 func NewQuerySendDetailsAction(c Client, params QuerySendDetailsParams) QuerySendDetailsAction {
 	params.cleanParams()
 
@@ -144,21 +146,49 @@ func NewQuerySendDetailsAction(c Client, params QuerySendDetailsParams) QuerySen
 				QuerySendDetailsParams: &params,
 			},
 			reflect.TypeOf(QuerySendDetailsResponse{}),
-			defaultReqHandler{},
+			HTTPReqHandler{},
+			func(opts Options) error {
+				return params.validate(time.Time(opts.Timestamp()))
+			},
 		},
 	}
 }
 
+// QueryAllSendDetails does action "QuerySendDetails" page by page,
+// starting from the first page, and returns SendDetailDTO of all pages
+// an *Error is returned if Code of any page is not "OK"
+func QueryAllSendDetails(c Client, params QuerySendDetailsParams, extOpts ...Option) ([]SendDetailDTO, error) {
+	params.cleanParams()
+
+	var details []SendDetailDTO
+	for page := 1; ; page++ {
+		params.CurrentPage = page
+		opts, err := NewQuerySendDetailsAction(c, params).Do(extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		res := opts.Response()
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		dtos := res.SmsSendDetailDTOs.SmsSendDetailDTO
+		details = append(details, dtos...)
+		if len(dtos) == 0 || page*params.PageSize >= res.TotalCount {
+			return details, nil
+		}
+	}
+}
+
 // SendDetailDTO of every sms
 type SendDetailDTO struct {
-	PhoneNum     string `json:"PhoneNum" xml:"PhoneNum"`
-	SendStatus   int    `json:"SendStatus" xml:"SendStatus"`
-	ErrCode      string `json:"ErrCode" xml:"ErrCode"`
-	TemplateCode string `json:"TemplateCode" xml:"TemplateCode"`
-	Content      string `json:"Content" xml:"Content"`
-	SendDate     string `json:"SendDate" xml:"SendDate"`
-	ReceiveDate  string `json:"ReceiveDate" xml:"ReceiveDate"`
-	OutID        string `json:"OutId" xml:"OutId"`
+	PhoneNum     string     `json:"PhoneNum" xml:"PhoneNum"`
+	SendStatus   SendStatus `json:"SendStatus" xml:"SendStatus"`
+	ErrCode      string     `json:"ErrCode" xml:"ErrCode"`
+	TemplateCode string     `json:"TemplateCode" xml:"TemplateCode"`
+	Content      string     `json:"Content" xml:"Content"`
+	SendDate     string     `json:"SendDate" xml:"SendDate"`
+	ReceiveDate  string     `json:"ReceiveDate" xml:"ReceiveDate"`
+	OutID        string     `json:"OutId" xml:"OutId"`
 }
 
 // SendDetailDTOs have list of SendDetailDTO
@@ -24,7 +24,7 @@ var rightQuerySendDetailsRes = QuerySendDetailsResponse{
 	}},
 }
 
-func (h testQuerySendDetailsHandler) DoReq(opts Options) ([]byte, error) {
+func (h testQuerySendDetailsHandler) DoReq(opts Options) (*HTTPResponse, error) {
 	var body []byte
 	switch opts.Format() {
 	case JSON:
@@ -32,7 +32,7 @@ func (h testQuerySendDetailsHandler) DoReq(opts Options) ([]byte, error) {
 	case XML:
 		body = []byte(`<?xml version='1.0' encoding='UTF-8'?><QuerySendDetailsResponse><TotalCount>1</TotalCount><Message>OK</Message><RequestId>0F8F57E7-B72B-492A-853F-F0F8A78D4DEE</RequestId><SmsSendDetailDTOs><SmsSendDetailDTO><OutId>123</OutId><SendDate>2018-04-27 14:19:30</SendDate><SendStatus>3</SendStatus><ReceiveDate>2018-04-27 14:19:35</ReceiveDate><ErrCode>DELIVRD</ErrCode><TemplateCode>SMS_132940015</TemplateCode><Content>【可乐贩售机】正在使用Go SDK，版本号：v1.0。</Content><PhoneNum>15300000001</PhoneNum></SmsSendDetailDTO></SmsSendDetailDTOs><Code>OK</Code></QuerySendDetailsResponse>`)
 	}
-	return body, nil
+	return bodyResponse(body), nil
 }
 
 func testQuerySendDetailsActionDo(t *testing.T, rightURL string, extOpts ...Option) {
@@ -83,7 +83,7 @@ func BenchmarkQuerySendDetailsAction_Do(b *testing.B) {
 	})
 
 	for i := 0; i < b.N; i++ {
-		_, err := a.Do(ReqHandlerOption(testQuerySendDetailsHandler{}))
+		_, err := a.Do(Timestamp(ts), ReqHandlerOption(testQuerySendDetailsHandler{}))
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -0,0 +1,199 @@
+package sms
+
+import "reflect"
+
+// QuerySendStatistics is value of business param "Action"
+const QuerySendStatistics = "QuerySendStatistics"
+
+const (
+	// Domestic is value of business param "IsGlobe", sms to chinese mainland
+	Domestic = 1
+
+	// International is value of business param "IsGlobe", sms to other regions
+	International = 2
+)
+
+// QuerySendStatisticsParams is business param of action "QuerySendStatistics"
+type QuerySendStatisticsParams struct {
+	IsGlobe   int    `param:"IsGlobe"`
+	StartDate Date   `param:"StartDate"`
+	EndDate   Date   `param:"EndDate"`
+	PageIndex int    `param:"PageIndex"`
+	PageSize  int    `param:"PageSize"`
+	SignName  string `param:"SignName,omitempty"`
+
+	// TemplateType is of TemplateType, the type is not filtered if it's nil
+	TemplateType *TemplateType `param:"TemplateType,omitempty"`
+	RegionID     string        `param:"RegionId,omitempty"`
+}
+
+type querySendStatisticsParams struct {
+	Action  ActionType `param:"Action"`
+	Version string     `param:"Version"`
+	*QuerySendStatisticsParams
+}
+
+// QuerySendStatisticsOptions represent QuerySendStatisticsAction's configurations
+type QuerySendStatisticsOptions interface {
+	Options
+	Action() ActionType
+	Version() string
+	IsGlobe() int
+	StartDate() Date
+	EndDate() Date
+	PageIndex() int
+	PageSize() int
+	SignName() string
+	RegionID() string
+
+	Response() *QuerySendStatisticsResponse
+}
+
+type querySendStatisticsOptions struct {
+	*options
+}
+
+func (q *querySendStatisticsOptions) params() *querySendStatisticsParams {
+	return q.businessParams.(*querySendStatisticsParams)
+}
+
+func (q *querySendStatisticsOptions) Action() ActionType {
+	return q.params().Action
+}
+
+func (q *querySendStatisticsOptions) Version() string {
+	return q.params().Version
+}
+
+func (q *querySendStatisticsOptions) IsGlobe() int {
+	return q.params().IsGlobe
+}
+
+func (q *querySendStatisticsOptions) StartDate() Date {
+	return q.params().StartDate
+}
+
+func (q *querySendStatisticsOptions) EndDate() Date {
+	return q.params().EndDate
+}
+
+func (q *querySendStatisticsOptions) PageIndex() int {
+	return q.params().PageIndex
+}
+
+func (q *querySendStatisticsOptions) PageSize() int {
+	return q.params().PageSize
+}
+
+func (q *querySendStatisticsOptions) SignName() string {
+	return q.params().SignName
+}
+
+func (q *querySendStatisticsOptions) RegionID() string {
+	return q.params().RegionID
+}
+
+func (q *querySendStatisticsOptions) Response() *QuerySendStatisticsResponse {
+	return q.res.(*QuerySendStatisticsResponse)
+}
+
+// QuerySendStatisticsAction is action "QuerySendStatistics"
+type QuerySendStatisticsAction interface {
+	action
+	Do(extOpts ...Option) (QuerySendStatisticsOptions, error)
+}
+
+type querySendStatisticsAction struct {
+	baseAction
+}
+
+// Do the query action
+func (a *querySendStatisticsAction) Do(extOpts ...Option) (QuerySendStatisticsOptions, error) {
+	opts, err := a.baseAction.doAction(extOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &querySendStatisticsOptions{opts}, nil
+}
+
+func (p *QuerySendStatisticsParams) cleanParams() {
+	if p.IsGlobe == 0 {
+		p.IsGlobe = Domestic
+	}
+	if p.PageIndex == 0 {
+		p.PageIndex = 1
+	}
+	if p.PageSize < QueryMinPageSize || p.PageSize > QueryMaxPageSize {
+		p.PageSize = QueryMaxPageSize
+	}
+}
+
+// NewQuerySendStatisticsAction init an action "QuerySendStatistics"
+// can be used concurrently
+// IsGlobe is Domestic if it's not specified,
+// if there are any problems of params, the ValidationError is returned from Do
+func NewQuerySendStatisticsAction(c Client, params QuerySendStatisticsParams) QuerySendStatisticsAction {
+	params.cleanParams()
+	err := params.validate()
+
+	return &querySendStatisticsAction{
+		baseAction{
+			&c,
+			&querySendStatisticsParams{
+				Action:                    QuerySendStatistics,
+				Version:                   DefaultVersion,
+				QuerySendStatisticsParams: &params,
+			},
+			reflect.TypeOf(QuerySendStatisticsResponse{}),
+			HTTPReqHandler{},
+			func(opts Options) error {
+				return err
+			},
+		},
+	}
+}
+
+// QueryAllSendStatistics does action "QuerySendStatistics" page by page,
+// starting from the first page, and returns SendStatistics of all pages
+// an *Error is returned if Code of any page is not "OK"
+func QueryAllSendStatistics(c Client, params QuerySendStatisticsParams, extOpts ...Option) ([]SendStatistics, error) {
+	params.cleanParams()
+
+	var stats []SendStatistics
+	for page := 1; ; page++ {
+		params.PageIndex = page
+		opts, err := NewQuerySendStatisticsAction(c, params).Do(extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		res := opts.Response()
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		stats = append(stats, res.Data.TargetList...)
+		if len(res.Data.TargetList) == 0 || page*params.PageSize >= res.Data.TotalSize {
+			return stats, nil
+		}
+	}
+}
+
+// SendStatistics of a day
+type SendStatistics struct {
+	SendDate              string `json:"SendDate" xml:"SendDate"`
+	TotalCount            int    `json:"TotalCount" xml:"TotalCount"`
+	RespondedSuccessCount int    `json:"RespondedSuccessCount" xml:"RespondedSuccessCount"`
+	RespondedFailCount    int    `json:"RespondedFailCount" xml:"RespondedFailCount"`
+	NoRespondedCount      int    `json:"NoRespondedCount" xml:"NoRespondedCount"`
+}
+
+// SendStatisticsData is a page of SendStatistics
+type SendStatisticsData struct {
+	TotalSize  int              `json:"TotalSize" xml:"TotalSize"`
+	TargetList []SendStatistics `json:"TargetList" xml:"TargetList"`
+}
+
+// QuerySendStatisticsResponse is Response of action "QuerySendStatistics"
+type QuerySendStatisticsResponse struct {
+	Response
+	Data SendStatisticsData `json:"Data" xml:"Data"`
+}
@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/signer"
+)
+
+// testStatisticsHandler responds 3 days of statistics, one day per page if PageSize is 1
+type testStatisticsHandler struct{}
+
+func (h testStatisticsHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	size, _ := strconv.Atoi(u.Query().Get("PageSize"))
+	index, _ := strconv.Atoi(u.Query().Get("PageIndex"))
+
+	var list string
+	for i := (index - 1) * size; i < index*size && i < 3; i++ {
+		if list != "" {
+			list += ","
+		}
+		list += fmt.Sprintf(`{"TotalCount":%d,"RespondedSuccessCount":%d,"RespondedFailCount":1,"NoRespondedCount":0,"SendDate":"2018040%d"}`, 10+i, 9+i, 7+i)
+	}
+	if opts.Format() == XML {
+		return bodyResponse([]byte(`<?xml version='1.0' encoding='UTF-8'?><QuerySendStatisticsResponse><RequestId>1</RequestId><Code>OK</Code><Message>OK</Message><Data><TotalSize>3</TotalSize><TargetList><SendDate>20180407</SendDate><TotalCount>10</TotalCount><RespondedSuccessCount>9</RespondedSuccessCount><RespondedFailCount>1</RespondedFailCount><NoRespondedCount>0</NoRespondedCount></TargetList></Data></QuerySendStatisticsResponse>`)), nil
+	}
+	return bodyResponse([]byte(`{"RequestId":"1","Code":"OK","Message":"OK","Data":{"TotalSize":3,"TargetList":[` + list + `]}}`)), nil
+}
+
+var statisticsParams = QuerySendStatisticsParams{StartDate: DateStr("20180407"), EndDate: DateStr("20180409")}
+
+func TestQuerySendStatisticsAction_Do(t *testing.T) {
+	typ := TemplateVerification
+	params := statisticsParams
+	params.TemplateType = &typ
+
+	for _, format := range []FormatType{JSON, XML} {
+		opts, err := NewQuerySendStatisticsAction(c, params).Do(format, Timestamp(ts), ReqHandlerOption(testStatisticsHandler{}))
+		if err != nil {
+			t.Fatalf("Do err: %v", err)
+		}
+
+		q, _ := url.Parse(opts.URL())
+		want := map[string]string{"Action": "QuerySendStatistics", "IsGlobe": "1", "StartDate": "20180407", "EndDate": "20180409", "PageIndex": "1", "PageSize": "50", "TemplateType": "0"}
+		for k, v := range want {
+			if q.Query().Get(k) != v {
+				t.Errorf("%s: %s != %s", k, q.Query().Get(k), v)
+			}
+		}
+		v := &signer.Verifier{Secret: signer.Secrets(map[string]string{"testId": "testSecret"}), Now: func() time.Time { return ts }}
+		if err := v.Verify(HTTPMethod, opts.URL()); err != nil {
+			t.Errorf("Verify: %v", err)
+		}
+
+		stats := SendStatistics{SendDate: "20180407", TotalCount: 10, RespondedSuccessCount: 9, RespondedFailCount: 1}
+		if res := opts.Response(); res.Data.TotalSize != 3 || !reflect.DeepEqual(res.Data.TargetList[0], stats) {
+			t.Errorf("Response(%s): %+v", format, res)
+		}
+	}
+
+	// TemplateType is omitted if it's nil
+	opts, _ := NewQuerySendStatisticsAction(c, statisticsParams).Do(ReqHandlerOption(testStatisticsHandler{}))
+	if q, _ := url.Parse(opts.URL()); q.Query()["TemplateType"] != nil {
+		t.Errorf("URL: %s", opts.URL())
+	}
+}
+
+func TestQuerySendStatisticsParams_Validate(t *testing.T) {
+	cases := []struct {
+		params QuerySendStatisticsParams
+		fields []string
+	}{
+		{statisticsParams, nil},
+		{QuerySendStatisticsParams{IsGlobe: 3, PageIndex: -1}, []string{"IsGlobe", "StartDate", "EndDate", "PageIndex"}},
+		{QuerySendStatisticsParams{StartDate: DateStr("20180409"), EndDate: DateStr("20180407")}, []string{"EndDate"}},
+	}
+	for _, cs := range cases {
+		err := cs.params.Validate()
+		var fields []string
+		if err != nil {
+			fields = err.(ValidationError).Fields()
+		}
+		if !reflect.DeepEqual(fields, cs.fields) {
+			t.Errorf("Validate(%+v): %v != %v", cs.params, fields, cs.fields)
+		}
+	}
+}
+
+func TestQueryAllSendStatistics(t *testing.T) {
+	params := statisticsParams
+	params.PageSize = 1
+	stats, err := QueryAllSendStatistics(c, params, ReqHandlerOption(testStatisticsHandler{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 3 || stats[2].SendDate != "20180409" {
+		t.Errorf("QueryAllSendStatistics: %+v", stats)
+	}
+}
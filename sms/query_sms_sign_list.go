@@ -0,0 +1,159 @@
+package sms
+
+import "reflect"
+
+// QuerySmsSignList is value of business param "Action"
+const QuerySmsSignList = "QuerySmsSignList"
+
+// QuerySmsSignListParams is business param of action "QuerySmsSignList"
+type QuerySmsSignListParams struct {
+	PageIndex int    `param:"PageIndex"`
+	PageSize  int    `param:"PageSize"`
+	RegionID  string `param:"RegionId,omitempty"`
+}
+
+type querySmsSignListParams struct {
+	Action  ActionType `param:"Action"`
+	Version string     `param:"Version"`
+	*QuerySmsSignListParams
+}
+
+// QuerySmsSignListOptions represent QuerySmsSignListAction's configurations
+type QuerySmsSignListOptions interface {
+	Options
+	Action() ActionType
+	Version() string
+	PageIndex() int
+	PageSize() int
+	RegionID() string
+
+	Response() *QuerySmsSignListResponse
+}
+
+type querySmsSignListOptions struct {
+	*options
+}
+
+func (q *querySmsSignListOptions) params() *querySmsSignListParams {
+	return q.businessParams.(*querySmsSignListParams)
+}
+
+func (q *querySmsSignListOptions) Action() ActionType {
+	return q.params().Action
+}
+
+func (q *querySmsSignListOptions) Version() string {
+	return q.params().Version
+}
+
+func (q *querySmsSignListOptions) PageIndex() int {
+	return q.params().PageIndex
+}
+
+func (q *querySmsSignListOptions) PageSize() int {
+	return q.params().PageSize
+}
+
+func (q *querySmsSignListOptions) RegionID() string {
+	return q.params().RegionID
+}
+
+func (q *querySmsSignListOptions) Response() *QuerySmsSignListResponse {
+	return q.res.(*QuerySmsSignListResponse)
+}
+
+// QuerySmsSignListAction is action "QuerySmsSignList"
+type QuerySmsSignListAction interface {
+	action
+	Do(extOpts ...Option) (QuerySmsSignListOptions, error)
+}
+
+type querySmsSignListAction struct {
+	baseAction
+}
+
+// Do the query action
+func (a *querySmsSignListAction) Do(extOpts ...Option) (QuerySmsSignListOptions, error) {
+	opts, err := a.baseAction.doAction(extOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &querySmsSignListOptions{opts}, nil
+}
+
+func (p *QuerySmsSignListParams) cleanParams() {
+	if p.PageIndex == 0 {
+		p.PageIndex = 1
+	}
+	if p.PageSize < QueryMinPageSize || p.PageSize > QueryMaxPageSize {
+		p.PageSize = QueryMaxPageSize
+	}
+}
+
+// NewQuerySmsSignListAction init an action "QuerySmsSignList"
+// can be used concurrently
+// if there are any problems of params, the ValidationError is returned from Do
+func NewQuerySmsSignListAction(c Client, params QuerySmsSignListParams) QuerySmsSignListAction {
+	params.cleanParams()
+	err := params.validate()
+
+	return &querySmsSignListAction{
+		baseAction{
+			&c,
+			&querySmsSignListParams{
+				Action:                 QuerySmsSignList,
+				Version:                DefaultVersion,
+				QuerySmsSignListParams: &params,
+			},
+			reflect.TypeOf(QuerySmsSignListResponse{}),
+			HTTPReqHandler{},
+			func(opts Options) error {
+				return err
+			},
+		},
+	}
+}
+
+// QueryAllSmsSigns does action "QuerySmsSignList" page by page,
+// starting from the first page, and returns SmsSign of all pages
+// an *Error is returned if Code of any page is not "OK"
+func QueryAllSmsSigns(c Client, extOpts ...Option) ([]SmsSign, error) {
+	params := QuerySmsSignListParams{}
+	params.cleanParams()
+
+	var signs []SmsSign
+	for page := 1; ; page++ {
+		params.PageIndex = page
+		opts, err := NewQuerySmsSignListAction(c, params).Do(extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		res := opts.Response()
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		signs = append(signs, res.SmsSignList...)
+		if len(res.SmsSignList) == 0 || page*params.PageSize >= res.TotalCount {
+			return signs, nil
+		}
+	}
+}
+
+// SmsSign is a sign of the account
+type SmsSign struct {
+	SignName     string      `json:"SignName" xml:"SignName"`
+	AuditStatus  AuditStatus `json:"AuditStatus" xml:"AuditStatus"`
+	CreateDate   string      `json:"CreateDate" xml:"CreateDate"`
+	BusinessType string      `json:"BusinessType" xml:"BusinessType"`
+	OrderID      string      `json:"OrderId" xml:"OrderId"`
+	Reason       AuditReason `json:"Reason" xml:"Reason"`
+}
+
+// QuerySmsSignListResponse is Response of action "QuerySmsSignList"
+type QuerySmsSignListResponse struct {
+	Response
+	TotalCount  int       `json:"TotalCount" xml:"TotalCount"`
+	CurrentPage int       `json:"CurrentPage" xml:"CurrentPage"`
+	PageSize    int       `json:"PageSize" xml:"PageSize"`
+	SmsSignList []SmsSign `json:"SmsSignList" xml:"SmsSignList"`
+}
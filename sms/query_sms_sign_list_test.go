@@ -0,0 +1,27 @@
+package sms
+
+import "testing"
+
+func TestQuerySmsSignListAction_Do(t *testing.T) {
+	opts, err := NewQuerySmsSignListAction(c, QuerySmsSignListParams{}).Do(ReqHandlerOption(testListHandler{}))
+	if err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	if opts.Action() != QuerySmsSignList || opts.PageIndex() != 1 || opts.PageSize() != QueryMaxPageSize {
+		t.Errorf("Options: %s %d %d", opts.Action(), opts.PageIndex(), opts.PageSize())
+	}
+	res := opts.Response()
+	if len(res.SmsSignList) != 3 {
+		t.Fatalf("Response: %+v", res)
+	}
+	if s := res.SmsSignList[2]; s.SignName != "sign2" || s.AuditStatus != AuditPass || s.OrderID != "2" {
+		t.Errorf("SmsSign: %+v", s)
+	}
+}
+
+func TestQueryAllSmsSigns(t *testing.T) {
+	signs, err := QueryAllSmsSigns(c, ReqHandlerOption(testListHandler{}))
+	if err != nil || len(signs) != 3 {
+		t.Errorf("QueryAllSmsSigns: %+v %v", signs, err)
+	}
+}
@@ -0,0 +1,189 @@
+package sms
+
+import "reflect"
+
+// QuerySmsTemplateList is value of business param "Action"
+const QuerySmsTemplateList = "QuerySmsTemplateList"
+
+// AuditStatus of templates and signs
+type AuditStatus string
+
+const (
+	// AuditInit is a template or sign under audit
+	AuditInit AuditStatus = "AUDIT_STATE_INIT"
+
+	// AuditPass is an approved template or sign
+	AuditPass AuditStatus = "AUDIT_STATE_PASS"
+
+	// AuditNotPass is a rejected template or sign
+	AuditNotPass AuditStatus = "AUDIT_STATE_NOT_PASS"
+
+	// AuditCancel is a canceled audit
+	AuditCancel AuditStatus = "AUDIT_STATE_CANCEL"
+)
+
+// AuditReason of a rejected template or sign
+type AuditReason struct {
+	RejectDate    string `json:"RejectDate" xml:"RejectDate"`
+	RejectInfo    string `json:"RejectInfo" xml:"RejectInfo"`
+	RejectSubInfo string `json:"RejectSubInfo" xml:"RejectSubInfo"`
+}
+
+// QuerySmsTemplateListParams is business param of action "QuerySmsTemplateList"
+type QuerySmsTemplateListParams struct {
+	PageIndex int    `param:"PageIndex"`
+	PageSize  int    `param:"PageSize"`
+	RegionID  string `param:"RegionId,omitempty"`
+}
+
+type querySmsTemplateListParams struct {
+	Action  ActionType `param:"Action"`
+	Version string     `param:"Version"`
+	*QuerySmsTemplateListParams
+}
+
+// QuerySmsTemplateListOptions represent QuerySmsTemplateListAction's configurations
+type QuerySmsTemplateListOptions interface {
+	Options
+	Action() ActionType
+	Version() string
+	PageIndex() int
+	PageSize() int
+	RegionID() string
+
+	Response() *QuerySmsTemplateListResponse
+}
+
+type querySmsTemplateListOptions struct {
+	*options
+}
+
+func (q *querySmsTemplateListOptions) params() *querySmsTemplateListParams {
+	return q.businessParams.(*querySmsTemplateListParams)
+}
+
+func (q *querySmsTemplateListOptions) Action() ActionType {
+	return q.params().Action
+}
+
+func (q *querySmsTemplateListOptions) Version() string {
+	return q.params().Version
+}
+
+func (q *querySmsTemplateListOptions) PageIndex() int {
+	return q.params().PageIndex
+}
+
+func (q *querySmsTemplateListOptions) PageSize() int {
+	return q.params().PageSize
+}
+
+func (q *querySmsTemplateListOptions) RegionID() string {
+	return q.params().RegionID
+}
+
+func (q *querySmsTemplateListOptions) Response() *QuerySmsTemplateListResponse {
+	return q.res.(*QuerySmsTemplateListResponse)
+}
+
+// QuerySmsTemplateListAction is action "QuerySmsTemplateList"
+type QuerySmsTemplateListAction interface {
+	action
+	Do(extOpts ...Option) (QuerySmsTemplateListOptions, error)
+}
+
+type querySmsTemplateListAction struct {
+	baseAction
+}
+
+// Do the query action
+func (a *querySmsTemplateListAction) Do(extOpts ...Option) (QuerySmsTemplateListOptions, error) {
+	opts, err := a.baseAction.doAction(extOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &querySmsTemplateListOptions{opts}, nil
+}
+
+func (p *QuerySmsTemplateListParams) cleanParams() {
+	if p.PageIndex == 0 {
+		p.PageIndex = 1
+	}
+	if p.PageSize < QueryMinPageSize || p.PageSize > QueryMaxPageSize {
+		p.PageSize = QueryMaxPageSize
+	}
+}
+
+// NewQuerySmsTemplateListAction init an action "QuerySmsTemplateList"
+// can be used concurrently
+// if there are any problems of params, the ValidationError is returned from Do
+func NewQuerySmsTemplateListAction(c Client, params QuerySmsTemplateListParams) QuerySmsTemplateListAction {
+	params.cleanParams()
+	err := params.validate()
+
+	return &querySmsTemplateListAction{
+		baseAction{
+			&c,
+			&querySmsTemplateListParams{
+				Action:                     QuerySmsTemplateList,
+				Version:                    DefaultVersion,
+				QuerySmsTemplateListParams: &params,
+			},
+			reflect.TypeOf(QuerySmsTemplateListResponse{}),
+			HTTPReqHandler{},
+			func(opts Options) error {
+				return err
+			},
+		},
+	}
+}
+
+// QueryAllSmsTemplates does action "QuerySmsTemplateList" page by page,
+// starting from the first page, and returns SmsTemplate of all pages
+// an *Error is returned if Code of any page is not "OK"
+func QueryAllSmsTemplates(c Client, extOpts ...Option) ([]SmsTemplate, error) {
+	params := QuerySmsTemplateListParams{}
+	params.cleanParams()
+
+	var templates []SmsTemplate
+	for page := 1; ; page++ {
+		params.PageIndex = page
+		opts, err := NewQuerySmsTemplateListAction(c, params).Do(extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		res := opts.Response()
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		templates = append(templates, res.SmsTemplateList...)
+		if len(res.SmsTemplateList) == 0 || page*params.PageSize >= res.TotalCount {
+			return templates, nil
+		}
+	}
+}
+
+// SmsTemplate is a template of the account
+type SmsTemplate struct {
+	TemplateCode    string       `json:"TemplateCode" xml:"TemplateCode"`
+	TemplateName    string       `json:"TemplateName" xml:"TemplateName"`
+	TemplateType    TemplateType `json:"TemplateType" xml:"TemplateType"`
+	TemplateContent string       `json:"TemplateContent" xml:"TemplateContent"`
+	AuditStatus     AuditStatus  `json:"AuditStatus" xml:"AuditStatus"`
+	CreateDate      string       `json:"CreateDate" xml:"CreateDate"`
+	Reason          AuditReason  `json:"Reason" xml:"Reason"`
+}
+
+// Template returns the Template of t for a TemplateRegistry
+func (t SmsTemplate) Template() Template {
+	return Template{Code: t.TemplateCode, Content: t.TemplateContent, Type: t.TemplateType}
+}
+
+// QuerySmsTemplateListResponse is Response of action "QuerySmsTemplateList"
+type QuerySmsTemplateListResponse struct {
+	Response
+	TotalCount      int           `json:"TotalCount" xml:"TotalCount"`
+	CurrentPage     int           `json:"CurrentPage" xml:"CurrentPage"`
+	PageSize        int           `json:"PageSize" xml:"PageSize"`
+	SmsTemplateList []SmsTemplate `json:"SmsTemplateList" xml:"SmsTemplateList"`
+}
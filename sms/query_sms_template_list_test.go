@@ -0,0 +1,69 @@
+package sms
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// testListHandler responds 3 templates or signs, by PageIndex and PageSize
+type testListHandler struct{}
+
+func (h testListHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	size, _ := strconv.Atoi(u.Query().Get("PageSize"))
+	index, _ := strconv.Atoi(u.Query().Get("PageIndex"))
+
+	var list string
+	for i := (index - 1) * size; i < index*size && i < 3; i++ {
+		if list != "" {
+			list += ","
+		}
+		if u.Query().Get("Action") == QuerySmsSignList {
+			list += fmt.Sprintf(`{"SignName":"sign%d","AuditStatus":"AUDIT_STATE_PASS","CreateDate":"2018-04-09 15:27:02","BusinessType":"验证码类型","OrderId":"%d","Reason":{}}`, i, i)
+		} else {
+			list += fmt.Sprintf(`{"TemplateCode":"SMS_%d","TemplateName":"t%d","TemplateType":2,"TemplateContent":"促销${name}","AuditStatus":"AUDIT_STATE_NOT_PASS","CreateDate":"2018-04-09 15:27:02","Reason":{"RejectDate":"2018-04-10 00:00:00","RejectInfo":"rejected"}}`, i, i)
+		}
+	}
+	key := "SmsTemplateList"
+	if u.Query().Get("Action") == QuerySmsSignList {
+		key = "SmsSignList"
+	}
+	return bodyResponse([]byte(fmt.Sprintf(`{"RequestId":"1","Code":"OK","Message":"OK","TotalCount":3,"CurrentPage":%d,"PageSize":%d,"%s":[%s]}`, index, size, key, list))), nil
+}
+
+func TestQuerySmsTemplateListAction_Do(t *testing.T) {
+	opts, err := NewQuerySmsTemplateListAction(c, QuerySmsTemplateListParams{PageSize: 2}).Do(ReqHandlerOption(testListHandler{}))
+	if err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	if opts.Action() != QuerySmsTemplateList || opts.PageIndex() != 1 || opts.PageSize() != 2 {
+		t.Errorf("Options: %s %d %d", opts.Action(), opts.PageIndex(), opts.PageSize())
+	}
+	res := opts.Response()
+	if res.TotalCount != 3 || len(res.SmsTemplateList) != 2 {
+		t.Fatalf("Response: %+v", res)
+	}
+	tpl := res.SmsTemplateList[1]
+	if tpl.TemplateCode != "SMS_1" || tpl.TemplateType != TemplatePromotion || tpl.AuditStatus != AuditNotPass || tpl.Reason.RejectInfo != "rejected" {
+		t.Errorf("SmsTemplate: %+v", tpl)
+	}
+	if p := tpl.Template().Placeholders(); len(p) != 1 || p[0] != "name" {
+		t.Errorf("Placeholders: %v", p)
+	}
+
+	if _, err := NewQuerySmsTemplateListAction(c, QuerySmsTemplateListParams{PageIndex: -1}).Do(); err == nil {
+		t.Error("Do: PageIndex is not validated")
+	}
+}
+
+func TestQueryAllSmsTemplates(t *testing.T) {
+	templates, err := QueryAllSmsTemplates(c, ReqHandlerOption(testListHandler{}))
+	if err != nil || len(templates) != 3 {
+		t.Errorf("QueryAllSmsTemplates: %+v %v", templates, err)
+	}
+}
@@ -0,0 +1,253 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSafeSendAttempts is default upper limit of attempts of action "SendSms" in SafeSender
+	DefaultSafeSendAttempts = 3
+
+	// DefaultVerifyDelay is default wait before verifying an ambiguous failure,
+	// records of action "QuerySendDetails" are available a few seconds after they are sent
+	DefaultVerifyDelay = 3 * time.Second
+
+	// DefaultSafeSendAttemptTimeout is default timeout of one action "SendSms" in SafeSender
+	DefaultSafeSendAttemptTimeout = 10 * time.Second
+
+	// DefaultVerifyTimeout is default timeout of verifying an ambiguous failure
+	DefaultVerifyTimeout = 10 * time.Second
+)
+
+// UnverifiedError is returned by SafeSender if action "SendSms" failed ambiguously,
+// and whether aliyun sms accepted it can not be verified,
+// the sms may have been sent, retrying it may send a duplicate
+type UnverifiedError struct {
+	SendErr  error
+	QueryErr error
+}
+
+func (e *UnverifiedError) Error() string {
+	return "sms: send result unknown: " + e.SendErr.Error() + ", verify: " + e.QueryErr.Error()
+}
+
+// SafeSendResult is result of SafeSender
+type SafeSendResult struct {
+	// Response of the last action "SendSms", it's nil if the sms is discovered by Detail
+	Response *SendSmsResponse
+
+	// Detail is the record of action "QuerySendDetails" has the same OutId and TemplateCode,
+	// and is sent since SafeSender.Send starts,
+	// it's set if an ambiguous failure turned out to be accepted by aliyun sms,
+	// notice that BizId is not returned by "QuerySendDetails", so it's unknown in this case
+	Detail *SendDetailDTO
+
+	// Attempts is number of action "SendSms"
+	Attempts int
+}
+
+// BizID returns BizId of Response, "" if the sms is discovered by Detail
+func (r *SafeSendResult) BizID() string {
+	if r.Response == nil {
+		return ""
+	}
+	return r.Response.BizID
+}
+
+// SafeSender sends sms and retries ambiguous failures, e.g. timeouts, only if
+// action "QuerySendDetails" has no record of the same OutId and TemplateCode
+//
+// OutID of params is required to identify the sms
+type SafeSender struct {
+	Client Client
+
+	// MaxAttempts is upper limit of attempts of action "SendSms", default DefaultSafeSendAttempts
+	MaxAttempts int
+
+	// VerifyDelay is the wait before action "QuerySendDetails", default DefaultVerifyDelay
+	VerifyDelay time.Duration
+
+	// AttemptTimeout is the timeout of one action "SendSms", default DefaultSafeSendAttemptTimeout
+	AttemptTimeout time.Duration
+
+	// VerifyTimeout is the timeout of verifying an ambiguous failure, default DefaultVerifyTimeout,
+	// the verification is not canceled with the context of Send, so that the result of
+	// a canceled send is still known
+	VerifyTimeout time.Duration
+
+	// Options are applied to every action
+	Options []Option
+}
+
+// SafeSend sends params with a default SafeSender
+func SafeSend(ctx context.Context, c Client, params SendSmsParams, extOpts ...Option) (*SafeSendResult, error) {
+	return SafeSender{Client: c, Options: extOpts}.Send(ctx, params)
+}
+
+// Send params
+// an ambiguous failure is an err of action "SendSms" which may be returned after the sms is accepted,
+// e.g. a timeout, it's verified by action "QuerySendDetails" of the first number not suppressed,
+// and the sms is sent again only if it's not found, other errs are returned immediately,
+// an *UnverifiedError is returned if the query failed
+// a Response with Code not "OK" is returned in SafeSendResult as is
+// if ctx is done, the ambiguous failure is still verified, but the sms is not sent again
+func (s SafeSender) Send(ctx context.Context, params SendSmsParams) (*SafeSendResult, error) {
+	if params.OutID == "" {
+		return nil, ValidationError{{Field: "OutID", Err: ErrRequired}}
+	}
+	cleaned := params
+	if err := cleaned.validate(s.Client.conf.Templates); err != nil {
+		return nil, err
+	}
+
+	attempts := s.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultSafeSendAttempts
+	}
+	delay := s.VerifyDelay
+	if delay <= 0 {
+		delay = DefaultVerifyDelay
+	}
+	attemptTimeout := s.AttemptTimeout
+	if attemptTimeout <= 0 {
+		attemptTimeout = DefaultSafeSendAttemptTimeout
+	}
+
+	// records sent before Send are not sent by it, even if they have the same OutId
+	start := time.Now()
+	res := &SafeSendResult{}
+	for {
+		// the sms is verified by a number actually sent, suppressed numbers are never sent
+		phoneNumber, err := s.recipient(cleaned)
+		if err != nil {
+			return nil, err
+		}
+		res.Attempts++
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		opts, err := NewSendAction(s.Client, params).Do(append(append([]Option{}, s.Options...), ContextOption(attemptCtx))...)
+		cancel()
+		if err == nil {
+			res.Response = opts.Response()
+			return res, nil
+		}
+		if !ambiguous(err) {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+		detail, queryErr := s.verify(ctx, phoneNumber, cleaned, start)
+		if queryErr != nil {
+			return nil, &UnverifiedError{SendErr: err, QueryErr: queryErr}
+		}
+		if detail != nil {
+			res.Detail = detail
+			return res, nil
+		}
+		// the sms is not sent
+		if ctx.Err() != nil || res.Attempts >= attempts {
+			return nil, err
+		}
+	}
+}
+
+// recipient returns the first number of params not suppressed by Config.Suppression,
+// a *SuppressedError is returned if all numbers are suppressed
+func (s SafeSender) recipient(params SendSmsParams) (string, error) {
+	l := s.Client.conf.Suppression
+	if l == nil {
+		return strings.SplitN(params.PhoneNumbers, ",", 2)[0], nil
+	}
+	kept, suppressed, err := l.Filter(params, s.Client.conf.Templates)
+	if err != nil {
+		return "", err
+	}
+	if len(kept) == 0 {
+		return "", &SuppressedError{Numbers: suppressed}
+	}
+	return kept[0].String(), nil
+}
+
+// ambiguous reports whether err of action "SendSms" leaves it unknown if the sms is sent,
+// errs returned before the request is sent, or of a response rejecting it, are definitive
+func ambiguous(err error) bool {
+	switch err := err.(type) {
+	case ValidationError, *SuppressedError, unknownFormatError:
+		return false
+	case *HTTPError:
+		return err.StatusCode >= 500 || err.StatusCode == http.StatusTooManyRequests
+	}
+	return err != ErrIdempotencyKeyReused
+}
+
+// maxClockSkew is the tolerance of "SendDate" of a record sent since Send starts
+const maxClockSkew = 2 * time.Second
+
+// detachedContext carries values of its parent, but it's never canceled with the parent
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// verify finds the record of params sent since start, in a context detached from ctx with VerifyTimeout
+func (s SafeSender) verify(ctx context.Context, phoneNumber string, params SendSmsParams, start time.Time) (*SendDetailDTO, error) {
+	timeout := s.VerifyTimeout
+	if timeout <= 0 {
+		timeout = DefaultVerifyTimeout
+	}
+	verifyCtx, cancel := context.WithTimeout(detachedContext{ctx}, timeout)
+	defer cancel()
+	return s.find(phoneNumber, params, start, append(append([]Option{}, s.Options...), ContextOption(verifyCtx)))
+}
+
+// find the record of params sent since start, send dates of start and now are queried
+func (s SafeSender) find(phoneNumber string, params SendSmsParams, start time.Time, extOpts []Option) (*SendDetailDTO, error) {
+	var dates []Date
+	for _, t := range []time.Time{start, time.Now()} {
+		d := Date(t.In(ChinaStandardTime))
+		if len(dates) == 0 || dates[0].String() != d.String() {
+			dates = append(dates, d)
+		}
+	}
+
+	req := TrackRequest{OutID: params.OutID, TemplateCode: params.TemplateCode}
+	// "SendDate" is in seconds, and the clock of aliyun sms may be behind
+	since := start.Add(-maxClockSkew).Truncate(time.Second)
+	for _, d := range dates {
+		details, err := QueryAllSendDetails(s.Client, QuerySendDetailsParams{
+			PhoneNumber: phoneNumber,
+			SendDate:    d,
+			PageSize:    QueryMaxPageSize,
+			RegionID:    params.RegionID,
+		}, extOpts...)
+		if err != nil {
+			return nil, err
+		}
+		var recent []SendDetailDTO
+		for _, detail := range details {
+			if t, err := detail.SendTime(); err == nil && !t.Before(since) {
+				recent = append(recent, detail)
+			}
+		}
+		if detail := matchOutID(recent, req); detail != nil {
+			return detail, nil
+		}
+	}
+	return nil, nil
+}
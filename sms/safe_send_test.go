@@ -0,0 +1,208 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// testSafeSendHandler fails the first `timeouts` actions "SendSms", the first `blocks` of them
+// wait until the context is done, and returns a record of outID sent now for action "QuerySendDetails"
+// if found is set, or sent a day ago if stale is set, sendErr is returned by every "SendSms" if it's set
+type testSafeSendHandler struct {
+	sendErr  error
+	timeouts int
+	blocks   int
+	found    bool
+	stale    bool
+	queryErr error
+	sends    int
+	queries  []url.Values
+}
+
+func (h *testSafeSendHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	if query.Get("Action") == QuerySendDetails {
+		h.queries = append(h.queries, query)
+		if h.queryErr != nil {
+			return nil, h.queryErr
+		}
+		if err := opts.Context().Err(); err != nil {
+			return nil, err
+		}
+		if h.found || h.stale {
+			sendDate := time.Now()
+			if h.stale {
+				sendDate = sendDate.Add(-24 * time.Hour)
+			}
+			return bodyResponse([]byte(`{"TotalCount":1,"Message":"OK","RequestId":"R","Code":"OK","SmsSendDetailDTOs":{"SmsSendDetailDTO":[` +
+				`{"OutId":"123","TemplateCode":"SMS_71390007","SendDate":"` + sendDate.In(ChinaStandardTime).Format(DetailTimeLayout) +
+				`","SendStatus":1,"PhoneNum":"15300000001"}]}}`)), nil
+		}
+		return bodyResponse([]byte(`{"TotalCount":0,"Message":"OK","RequestId":"R","Code":"OK","SmsSendDetailDTOs":{"SmsSendDetailDTO":[]}}`)), nil
+	}
+
+	h.sends++
+	if h.sendErr != nil {
+		return nil, h.sendErr
+	}
+	if h.sends <= h.blocks {
+		<-opts.Context().Done()
+		return nil, opts.Context().Err()
+	}
+	if h.sends <= h.timeouts {
+		return nil, errors.New("net/http: request canceled (Client.Timeout exceeded while awaiting headers)")
+	}
+	return testSendHandler{}.DoReq(opts)
+}
+
+var safeSendParams = SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: outID}
+
+func TestSafeSender_Send(t *testing.T) {
+	h := &testSafeSendHandler{timeouts: 1}
+	s := SafeSender{Client: c, VerifyDelay: time.Millisecond, Options: []Option{ReqHandlerOption(h)}}
+	res, err := s.Send(context.Background(), safeSendParams)
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	if res.Attempts != 2 || h.sends != 2 || res.BizID() != rightSendSmsRes.BizID || res.Detail != nil {
+		t.Errorf("Send: %+v", res)
+	}
+	if len(h.queries) == 0 || h.queries[0].Get("PhoneNumber") != "15300000001" {
+		t.Errorf("queries: %v", h.queries)
+	}
+}
+
+func TestSafeSender_SendFound(t *testing.T) {
+	h := &testSafeSendHandler{timeouts: 1, found: true}
+	s := SafeSender{Client: c, VerifyDelay: time.Millisecond, Options: []Option{ReqHandlerOption(h)}}
+	res, err := s.Send(context.Background(), safeSendParams)
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	if res.Attempts != 1 || h.sends != 1 || res.Detail == nil || res.Detail.OutID != outID || res.BizID() != "" {
+		t.Errorf("Send: %+v", res)
+	}
+}
+
+func TestSafeSender_SendErr(t *testing.T) {
+	h := &testSafeSendHandler{timeouts: 5}
+	s := SafeSender{Client: c, MaxAttempts: 2, VerifyDelay: time.Millisecond, Options: []Option{ReqHandlerOption(h)}}
+	if _, err := s.Send(context.Background(), safeSendParams); err == nil || h.sends != 2 {
+		t.Errorf("Send: %v, %d sends", err, h.sends)
+	}
+
+	h = &testSafeSendHandler{timeouts: 1, queryErr: errors.New("EOF")}
+	s.Options = []Option{ReqHandlerOption(h)}
+	if _, err := s.Send(context.Background(), safeSendParams); err == nil {
+		t.Errorf("Send err is nil")
+	} else if _, ok := err.(*UnverifiedError); !ok || h.sends != 1 {
+		t.Errorf("Send: %v, %d sends", err, h.sends)
+	}
+
+	params := safeSendParams
+	params.OutID = ""
+	if _, err := s.Send(context.Background(), params); err == nil {
+		t.Errorf("Send without OutID")
+	} else if _, ok := err.(ValidationError); !ok {
+		t.Errorf("Send err: %v", err)
+	}
+}
+
+func TestSafeSender_SendStale(t *testing.T) {
+	// the record of the same OutId sent before is not the sms
+	h := &testSafeSendHandler{timeouts: 1, stale: true}
+	s := SafeSender{Client: c, VerifyDelay: time.Millisecond, Options: []Option{ReqHandlerOption(h)}}
+	res, err := s.Send(context.Background(), safeSendParams)
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	if res.Attempts != 2 || h.sends != 2 || res.Detail != nil {
+		t.Errorf("Send: %+v", res)
+	}
+}
+
+func TestSafeSender_SendTimeout(t *testing.T) {
+	// the attempt times out, it's verified and sent again
+	h := &testSafeSendHandler{timeouts: 1, blocks: 1}
+	s := SafeSender{Client: c, VerifyDelay: time.Millisecond, AttemptTimeout: 10 * time.Millisecond, Options: []Option{ReqHandlerOption(h)}}
+	res, err := s.Send(context.Background(), safeSendParams)
+	if err != nil {
+		t.Fatalf("Send err: %v", err)
+	}
+	if res.Attempts != 2 || h.sends != 2 || res.BizID() != rightSendSmsRes.BizID {
+		t.Errorf("Send: %+v", res)
+	}
+
+	// ctx is done, it's still verified, but not sent again
+	for _, found := range []bool{true, false} {
+		h = &testSafeSendHandler{timeouts: 1, blocks: 1, found: found}
+		s.Options = []Option{ReqHandlerOption(h)}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		res, err = s.Send(ctx, safeSendParams)
+		cancel()
+		if found && (err != nil || res.Detail == nil) {
+			t.Errorf("Send found: %+v, %v", res, err)
+		}
+		// the sms is verified not sent, it's not an *UnverifiedError
+		if !found && err != context.DeadlineExceeded {
+			t.Errorf("Send not found: %v", err)
+		}
+		if h.sends != 1 || len(h.queries) == 0 {
+			t.Errorf("Send: %d sends, %d queries", h.sends, len(h.queries))
+		}
+	}
+}
+
+func TestSafeSender_SendSuppressed(t *testing.T) {
+	l := NewSuppressionList(nil)
+	l.Add(phone.MustParse("15300000001"), SuppressionScope{}, "manual")
+	sc := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Suppression: l})
+	params := safeSendParams
+	params.PhoneNumbers = "15300000001,15300000002"
+
+	// the sms is verified by the number sent
+	h := &testSafeSendHandler{timeouts: 1, found: true}
+	s := SafeSender{Client: sc, VerifyDelay: time.Millisecond, Options: []Option{ReqHandlerOption(h)}}
+	res, err := s.Send(context.Background(), params)
+	if err != nil || res.Detail == nil || h.sends != 1 {
+		t.Fatalf("Send: %+v, %v", res, err)
+	}
+	if len(h.queries) == 0 || h.queries[0].Get("PhoneNumber") != "15300000002" {
+		t.Errorf("queries: %v", h.queries)
+	}
+
+	// nothing is sent if all numbers are suppressed
+	h = &testSafeSendHandler{}
+	s.Options = []Option{ReqHandlerOption(h)}
+	params.PhoneNumbers = "15300000001"
+	if _, err := s.Send(context.Background(), params); err == nil || h.sends != 0 || len(h.queries) != 0 {
+		t.Errorf("Send: %v, %d sends, %d queries", err, h.sends, len(h.queries))
+	} else if _, ok := err.(*SuppressedError); !ok {
+		t.Errorf("Send err: %v", err)
+	}
+}
+
+func TestSafeSender_SendDefinitiveErr(t *testing.T) {
+	for _, sendErr := range []error{ErrIdempotencyKeyReused, &HTTPError{StatusCode: 403, Err: errNoCode}} {
+		h := &testSafeSendHandler{sendErr: sendErr}
+		s := SafeSender{Client: c, VerifyDelay: time.Hour, Options: []Option{ReqHandlerOption(h)}}
+		if _, err := s.Send(context.Background(), safeSendParams); err != sendErr || h.sends != 1 || len(h.queries) != 0 {
+			t.Errorf("Send: %v, %d sends, %d queries", err, h.sends, len(h.queries))
+		}
+	}
+	// unknown Format is returned before it's sent
+	h := &testSafeSendHandler{}
+	s := SafeSender{Client: c, VerifyDelay: time.Hour, Options: []Option{ReqHandlerOption(h), FormatType("YAML")}}
+	if _, err := s.Send(context.Background(), safeSendParams); err == nil || h.sends != 0 || len(h.queries) != 0 {
+		t.Errorf("Send: %v, %d sends, %d queries", err, h.sends, len(h.queries))
+	}
+}
@@ -0,0 +1,230 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// SendBatchSms is value of business param "Action"
+const SendBatchSms = "SendBatchSms"
+
+// MaxMessagesPerBatch is upper limit of messages in one action "SendBatchSms"
+const MaxMessagesPerBatch = 100
+
+// BatchMessage is the message to one phone number of action "SendBatchSms"
+type BatchMessage struct {
+	PhoneNumber   string
+	SignName      string
+	TemplateParam TemplateParam
+}
+
+// SendBatchSmsParams is business param of action "SendBatchSms",
+// every message has its own phone number, sign name and template param
+// of the same template
+type SendBatchSmsParams struct {
+	RegionID     string         `param:"RegionId"`
+	TemplateCode string         `param:"TemplateCode"`
+	Messages     []BatchMessage `param:"-"`
+}
+
+type sendBatchSmsParams struct {
+	Action            ActionType `param:"Action"`
+	Version           string     `param:"Version"`
+	PhoneNumberJSON   string     `param:"PhoneNumberJson"`
+	SignNameJSON      string     `param:"SignNameJson"`
+	TemplateParamJSON string     `param:"TemplateParamJson,omitempty"`
+	*SendBatchSmsParams
+}
+
+// encode Messages into the json arrays of business params,
+// "TemplateParamJson" is omitted if no message has TemplateParam
+func (p *sendBatchSmsParams) encode() error {
+	numbers := make([]string, len(p.Messages))
+	signNames := make([]string, len(p.Messages))
+	templateParams := make([]TemplateParam, len(p.Messages))
+	hasParam := false
+	for i, m := range p.Messages {
+		numbers[i], signNames[i] = m.PhoneNumber, m.SignName
+		templateParams[i] = m.TemplateParam
+		if templateParams[i] == nil {
+			templateParams[i] = TemplateParam{}
+		} else {
+			hasParam = true
+		}
+	}
+
+	data, err := json.Marshal(numbers)
+	if err != nil {
+		return err
+	}
+	p.PhoneNumberJSON = string(data)
+	if data, err = json.Marshal(signNames); err != nil {
+		return err
+	}
+	p.SignNameJSON = string(data)
+	if hasParam {
+		if data, err = json.Marshal(templateParams); err != nil {
+			return err
+		}
+		p.TemplateParamJSON = string(data)
+	}
+	return nil
+}
+
+// SendBatchSmsOptions represent SendBatchSmsAction's configurations
+type SendBatchSmsOptions interface {
+	Options
+	Action() ActionType
+	Version() string
+	RegionID() string
+	TemplateCode() string
+	Messages() []BatchMessage
+
+	// Suppressed returns numbers whose messages are filtered out by Config.Suppression
+	Suppressed() []phone.Number
+
+	Response() *SendBatchSmsResponse
+}
+
+type sendBatchSmsOptions struct {
+	*options
+	suppressed []phone.Number
+}
+
+func (s *sendBatchSmsOptions) params() *sendBatchSmsParams {
+	return s.businessParams.(*sendBatchSmsParams)
+}
+
+func (s *sendBatchSmsOptions) Action() ActionType {
+	return s.params().Action
+}
+
+func (s *sendBatchSmsOptions) Version() string {
+	return s.params().Version
+}
+
+func (s *sendBatchSmsOptions) RegionID() string {
+	return s.params().RegionID
+}
+
+func (s *sendBatchSmsOptions) TemplateCode() string {
+	return s.params().TemplateCode
+}
+
+func (s *sendBatchSmsOptions) Messages() []BatchMessage {
+	return s.params().Messages
+}
+
+func (s *sendBatchSmsOptions) Suppressed() []phone.Number {
+	return s.suppressed
+}
+
+func (s *sendBatchSmsOptions) Response() *SendBatchSmsResponse {
+	return s.res.(*SendBatchSmsResponse)
+}
+
+// SendBatchSmsAction is action "SendBatchSms"
+type SendBatchSmsAction interface {
+	action
+	Do(extOpts ...Option) (SendBatchSmsOptions, error)
+}
+
+type sendBatchSmsAction struct {
+	baseAction
+	params *SendBatchSmsParams
+	err    error
+}
+
+// suppress returns the action of messages not suppressed by Config.Suppression and the suppressed numbers,
+// it's checked on every request, as action "SendSms" does
+func (a *sendBatchSmsAction) suppress() (*baseAction, []phone.Number) {
+	if a.err != nil || a.c.conf.Suppression == nil {
+		return &a.baseAction, nil
+	}
+	kept, suppressed, err := a.c.conf.Suppression.FilterBatch(*a.params, a.c.conf.Templates)
+	if err == nil && len(suppressed) == 0 {
+		return &a.baseAction, nil
+	}
+
+	params := *a.params
+	params.Messages = kept
+	p := &sendBatchSmsParams{Action: SendBatchSms, Version: DefaultVersion, SendBatchSmsParams: &params}
+	if err == nil {
+		if len(kept) == 0 {
+			err = &SuppressedError{Numbers: suppressed}
+		} else {
+			err = p.encode()
+		}
+	}
+	b := a.baseAction
+	b.businessParams = p
+	b.validate = func(opts Options) error {
+		return err
+	}
+	return &b, suppressed
+}
+
+// Do the send action
+func (a *sendBatchSmsAction) Do(extOpts ...Option) (SendBatchSmsOptions, error) {
+	b, suppressed := a.suppress()
+	opts, err := b.doAction(extOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &sendBatchSmsOptions{opts, suppressed}, nil
+}
+
+// Build the signed request of messages not suppressed, see Do
+func (a *sendBatchSmsAction) Build(ctx context.Context, extOpts ...Option) (*http.Request, error) {
+	b, _ := a.suppress()
+	return b.Build(ctx, extOpts...)
+}
+
+// Presign the url of messages not suppressed, see Do
+func (a *sendBatchSmsAction) Presign(extOpts ...Option) (PresignedURL, error) {
+	b, _ := a.suppress()
+	return b.Presign(extOpts...)
+}
+
+// NewSendBatchSmsAction init an action "SendBatchSms"
+// can be used concurrently
+// params are validated, if there are any problems, the ValidationError is returned from Do
+// messages of numbers suppressed by Config.Suppression are filtered out on every Do,
+// a *SuppressedError is returned from Do if all numbers are suppressed
+func NewSendBatchSmsAction(c Client, params SendBatchSmsParams) SendBatchSmsAction {
+	params.Messages = append([]BatchMessage(nil), params.Messages...)
+	err := params.validate(c.conf.Templates)
+
+	p := &sendBatchSmsParams{
+		Action:             SendBatchSms,
+		Version:            DefaultVersion,
+		SendBatchSmsParams: &params,
+	}
+	if err == nil {
+		err = p.encode()
+	}
+
+	return &sendBatchSmsAction{
+		baseAction{
+			&c,
+			p,
+			reflect.TypeOf(SendBatchSmsResponse{}),
+			HTTPReqHandler{},
+			func(opts Options) error {
+				return err
+			},
+		},
+		&params,
+		err,
+	}
+}
+
+// SendBatchSmsResponse is Response of action "SendBatchSms"
+type SendBatchSmsResponse struct {
+	Response
+	BizID string `json:"BizId" xml:"BizId"`
+}
@@ -0,0 +1,106 @@
+package sms
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+type testSendBatchHandler struct {
+	query url.Values
+}
+
+func (h *testSendBatchHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	h.query = u.Query()
+	return bodyResponse([]byte(`{"Message":"OK","RequestId":"6EE2B27D-6833-4D5F-9B9B-CE7FA0A85CC7","BizId":"199303724724900470^0","Code":"OK"}`)), nil
+}
+
+func TestSendBatchSmsAction_Do(t *testing.T) {
+	h := &testSendBatchHandler{}
+	opts, err := NewSendBatchSmsAction(c, SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages: []BatchMessage{
+			{PhoneNumber: "+86 153 0000 0001", SignName: "sign1", TemplateParam: TemplateParam{"code": "1"}},
+			{PhoneNumber: "15300000002", SignName: "sign2"},
+		},
+	}).Do(ReqHandlerOption(h))
+	if err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	if opts.Action() != SendBatchSms || opts.Messages()[0].PhoneNumber != "15300000001" {
+		t.Errorf("Options: %s %+v", opts.Action(), opts.Messages())
+	}
+	want := map[string]string{
+		"PhoneNumberJson":   `["15300000001","15300000002"]`,
+		"SignNameJson":      `["sign1","sign2"]`,
+		"TemplateParamJson": `[{"code":"1"},{}]`,
+	}
+	for k, v := range want {
+		if got := h.query.Get(k); got != v {
+			t.Errorf("%s: %s != %s", k, got, v)
+		}
+	}
+	if res := opts.Response(); res.BizID != "199303724724900470^0" || res.Err() != nil {
+		t.Errorf("Response: %+v", res)
+	}
+
+	h = &testSendBatchHandler{}
+	_, err = NewSendBatchSmsAction(c, SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages:     []BatchMessage{{PhoneNumber: "15300000001", SignName: "sign1"}},
+	}).Do(ReqHandlerOption(h))
+	if _, ok := h.query["TemplateParamJson"]; err != nil || ok {
+		t.Errorf("TemplateParamJson is not omitted: %v %v", h.query, err)
+	}
+}
+
+func TestSendBatchSmsAction_Invalid(t *testing.T) {
+	h := &testSendBatchHandler{}
+	_, err := NewSendBatchSmsAction(c, SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages:     []BatchMessage{{PhoneNumber: "123"}},
+	}).Do(ReqHandlerOption(h))
+	if _, ok := err.(ValidationError); !ok || h.query != nil {
+		t.Errorf("Do err: %v", err)
+	}
+}
+
+func TestSendBatchSmsAction_DoSuppressed(t *testing.T) {
+	l := NewSuppressionList(nil)
+	l.Add(phone.MustParse("15300000002"), SuppressionScope{SignName: "sign2"}, "manual")
+	sc := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Suppression: l})
+	a := NewSendBatchSmsAction(sc, SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages: []BatchMessage{
+			{PhoneNumber: "15300000001", SignName: "sign1", TemplateParam: TemplateParam{"code": "1"}},
+			{PhoneNumber: "15300000002", SignName: "sign2", TemplateParam: TemplateParam{"code": "2"}},
+			{PhoneNumber: "15300000003", SignName: "sign3"},
+		},
+	})
+
+	h := &testSendBatchHandler{}
+	opts, err := a.Do(ReqHandlerOption(h))
+	if err != nil {
+		t.Fatalf("Do err: %v", err)
+	}
+	if h.query.Get("PhoneNumberJson") != `["15300000001","15300000003"]` || h.query.Get("TemplateParamJson") != `[{"code":"1"},{}]` {
+		t.Errorf("query: %v", h.query)
+	}
+	if s := opts.Suppressed(); len(s) != 1 || s[0].String() != "15300000002" || len(opts.Messages()) != 2 {
+		t.Errorf("Suppressed: %v, Messages: %v", s, opts.Messages())
+	}
+
+	// suppressions added after the action is built are applied
+	l.Add(phone.MustParse("15300000001"), SuppressionScope{}, "manual")
+	l.Add(phone.MustParse("15300000003"), SuppressionScope{}, "manual")
+	if _, err := a.Do(ReqHandlerOption(h)); err == nil {
+		t.Error("Do of all numbers suppressed")
+	} else if e, ok := err.(*SuppressedError); !ok || len(e.Numbers) != 3 {
+		t.Errorf("Do err: %v", err)
+	}
+}
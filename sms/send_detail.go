@@ -0,0 +1,233 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DetailTimeLayout is layout of "SendDate" and "ReceiveDate" in SendDetailDTO
+const DetailTimeLayout = "2006-01-02 15:04:05"
+
+// ChinaStandardTime is the location of time values returned by aliyun sms api
+// it falls back to a fixed UTC+8 zone if tzdata is not available
+var ChinaStandardTime = loadChinaStandardTime()
+
+func loadChinaStandardTime() *time.Location {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return time.FixedZone("CST", 8*60*60)
+	}
+	return loc
+}
+
+// SendStatus is type of "SendStatus" in SendDetailDTO
+type SendStatus int
+
+const (
+	// SendStatusWaiting means the receipt from carrier is not arrived yet
+	SendStatusWaiting SendStatus = 1
+
+	// SendStatusFailed means the sms failed to be delivered
+	SendStatusFailed SendStatus = 2
+
+	// SendStatusDelivered means the sms is delivered to the handset
+	SendStatusDelivered SendStatus = 3
+)
+
+// String returns the name of SendStatus
+func (s SendStatus) String() string {
+	switch s {
+	case SendStatusWaiting:
+		return "waiting"
+	case SendStatusFailed:
+		return "failed"
+	case SendStatusDelivered:
+		return "delivered"
+	}
+	return "SendStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// IsTerminal reports whether the status will not change any more
+func (s SendStatus) IsTerminal() bool {
+	return s == SendStatusFailed || s == SendStatusDelivered
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Both number and quoted number are accepted, null or empty string is zero.
+func (s *SendStatus) UnmarshalJSON(data []byte) error {
+	return s.parse(string(bytes.Trim(data, `"`)))
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+// Empty element is zero.
+func (s *SendStatus) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v string
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	return s.parse(v)
+}
+
+func (s *SendStatus) parse(v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" || v == "null" {
+		*s = 0
+		return nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("SendStatus: invalid value %q", v)
+	}
+	*s = SendStatus(i)
+	return nil
+}
+
+// ErrCodeFamily is the classification of "ErrCode" in SendDetailDTO
+type ErrCodeFamily int
+
+const (
+	// ErrCodeNone means ErrCode is empty, usually the sms is still waiting
+	ErrCodeNone ErrCodeFamily = iota
+
+	// ErrCodeDelivered is "DELIVRD"
+	ErrCodeDelivered
+
+	// ErrCodeUndelivered is "UNDELIV"
+	ErrCodeUndelivered
+
+	// ErrCodeExpired is "EXPIRED"
+	ErrCodeExpired
+
+	// ErrCodeRejected is "REJECTD"
+	ErrCodeRejected
+
+	// ErrCodeUnknown is "UNKNOWN" or "DELETED"
+	ErrCodeUnknown
+
+	// ErrCodeGateway is "MK:xxxx", returned by the carrier sms gateway
+	ErrCodeGateway
+
+	// ErrCodeNetwork is "MN:xxxx", returned by the carrier network or handset
+	ErrCodeNetwork
+
+	// ErrCodeInterGateway is "MI:xxxx", returned between carrier gateways
+	ErrCodeInterGateway
+
+	// ErrCodeBlacklist is "DB:xxxx", the number is blocked by carrier
+	ErrCodeBlacklist
+
+	// ErrCodeISMG is "IA:xxxx", "IB:xxxx", "IC:xxxx" or "ID:xxxx", returned by the ISMG
+	ErrCodeISMG
+
+	// ErrCodePlatform is "isv.xxx", returned by aliyun sms platform
+	ErrCodePlatform
+
+	// ErrCodeOther is any other ErrCode
+	ErrCodeOther
+)
+
+var errCodeFamilyNames = [...]string{
+	ErrCodeNone:         "none",
+	ErrCodeDelivered:    "delivered",
+	ErrCodeUndelivered:  "undelivered",
+	ErrCodeExpired:      "expired",
+	ErrCodeRejected:     "rejected",
+	ErrCodeUnknown:      "unknown",
+	ErrCodeGateway:      "gateway",
+	ErrCodeNetwork:      "network",
+	ErrCodeInterGateway: "inter-gateway",
+	ErrCodeBlacklist:    "blacklist",
+	ErrCodeISMG:         "ismg",
+	ErrCodePlatform:     "platform",
+	ErrCodeOther:        "other",
+}
+
+// String returns the name of ErrCodeFamily
+func (f ErrCodeFamily) String() string {
+	if f < 0 || int(f) >= len(errCodeFamilyNames) {
+		return "ErrCodeFamily(" + strconv.Itoa(int(f)) + ")"
+	}
+	return errCodeFamilyNames[f]
+}
+
+// ClassifyErrCode returns the ErrCodeFamily of "ErrCode" in SendDetailDTO
+func ClassifyErrCode(code string) ErrCodeFamily {
+	code = strings.TrimSpace(code)
+	switch strings.ToUpper(code) {
+	case "":
+		return ErrCodeNone
+	case "DELIVRD":
+		return ErrCodeDelivered
+	case "UNDELIV":
+		return ErrCodeUndelivered
+	case "EXPIRED":
+		return ErrCodeExpired
+	case "REJECTD":
+		return ErrCodeRejected
+	case "UNKNOWN", "DELETED":
+		return ErrCodeUnknown
+	}
+
+	if strings.HasPrefix(code, "isv.") {
+		return ErrCodePlatform
+	}
+
+	if i := strings.Index(code, ":"); i == 2 {
+		switch strings.ToUpper(code[:i]) {
+		case "MK":
+			return ErrCodeGateway
+		case "MN":
+			return ErrCodeNetwork
+		case "MI":
+			return ErrCodeInterGateway
+		case "DB":
+			return ErrCodeBlacklist
+		case "IA", "IB", "IC", "ID":
+			return ErrCodeISMG
+		}
+	}
+	return ErrCodeOther
+}
+
+func parseDetailTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(DetailTimeLayout, value, ChinaStandardTime)
+}
+
+// SendTime returns "SendDate" in ChinaStandardTime
+// zero time is returned if "SendDate" is empty
+func (d SendDetailDTO) SendTime() (time.Time, error) {
+	return parseDetailTime(d.SendDate)
+}
+
+// ReceiveTime returns "ReceiveDate" in ChinaStandardTime
+// zero time is returned if "ReceiveDate" is empty, e.g. the sms is still waiting
+func (d SendDetailDTO) ReceiveTime() (time.Time, error) {
+	return parseDetailTime(d.ReceiveDate)
+}
+
+// Latency returns the duration between "SendDate" and "ReceiveDate"
+// ok is false if either of them is empty or malformed
+func (d SendDetailDTO) Latency() (latency time.Duration, ok bool) {
+	sent, err := d.SendTime()
+	if err != nil || sent.IsZero() {
+		return 0, false
+	}
+	received, err := d.ReceiveTime()
+	if err != nil || received.IsZero() {
+		return 0, false
+	}
+	return received.Sub(sent), true
+}
+
+// ErrCodeFamily returns the classification of "ErrCode"
+func (d SendDetailDTO) ErrCodeFamily() ErrCodeFamily {
+	return ClassifyErrCode(d.ErrCode)
+}
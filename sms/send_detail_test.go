@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestSendStatus_Unmarshal(t *testing.T) {
+	jsonCases := map[string]SendStatus{
+		`{"SendStatus":3}`:    SendStatusDelivered,
+		`{"SendStatus":"2"}`:  SendStatusFailed,
+		`{"SendStatus":""}`:   0,
+		`{"SendStatus":null}`: 0,
+		`{}`:                  0,
+	}
+	for data, want := range jsonCases {
+		var d SendDetailDTO
+		if err := json.Unmarshal([]byte(data), &d); err != nil {
+			t.Errorf("json %s err: %v", data, err)
+			continue
+		}
+		if d.SendStatus != want {
+			t.Errorf("json %s: %v != %v", data, d.SendStatus, want)
+		}
+	}
+
+	xmlCases := map[string]SendStatus{
+		`<SmsSendDetailDTO><SendStatus>1</SendStatus></SmsSendDetailDTO>`:   SendStatusWaiting,
+		`<SmsSendDetailDTO><SendStatus> 3 </SendStatus></SmsSendDetailDTO>`: SendStatusDelivered,
+		`<SmsSendDetailDTO><SendStatus></SendStatus></SmsSendDetailDTO>`:    0,
+	}
+	for data, want := range xmlCases {
+		var d SendDetailDTO
+		if err := xml.Unmarshal([]byte(data), &d); err != nil {
+			t.Errorf("xml %s err: %v", data, err)
+			continue
+		}
+		if d.SendStatus != want {
+			t.Errorf("xml %s: %v != %v", data, d.SendStatus, want)
+		}
+	}
+
+	var d SendDetailDTO
+	if err := json.Unmarshal([]byte(`{"SendStatus":"x"}`), &d); err == nil {
+		t.Error("invalid SendStatus should return an err")
+	}
+}
+
+func TestSendStatus_String(t *testing.T) {
+	if s := SendStatusDelivered.String(); s != "delivered" {
+		t.Errorf("SendStatus string: %s != %s", s, "delivered")
+	}
+	if s := SendStatus(9).String(); s != "SendStatus(9)" {
+		t.Errorf("SendStatus string: %s != %s", s, "SendStatus(9)")
+	}
+	if SendStatusWaiting.IsTerminal() || !SendStatusFailed.IsTerminal() || !SendStatusDelivered.IsTerminal() {
+		t.Error("SendStatus IsTerminal mismatch")
+	}
+}
+
+func TestSendDetailDTO_Time(t *testing.T) {
+	d := rightQuerySendDetailsRes.SmsSendDetailDTOs.SmsSendDetailDTO[0]
+
+	sent, err := d.SendTime()
+	if err != nil {
+		t.Fatalf("SendTime err: %v", err)
+	}
+	if want := time.Date(2018, 4, 27, 6, 19, 30, 0, time.UTC); !sent.Equal(want) {
+		t.Errorf("SendTime: %v != %v", sent, want)
+	}
+
+	if latency, ok := d.Latency(); !ok || latency != 5*time.Second {
+		t.Errorf("Latency: %v, %v != %v", latency, ok, 5*time.Second)
+	}
+
+	d.ReceiveDate = ""
+	if received, err := d.ReceiveTime(); err != nil || !received.IsZero() {
+		t.Errorf("ReceiveTime of empty ReceiveDate: %v, %v", received, err)
+	}
+	if _, ok := d.Latency(); ok {
+		t.Error("Latency of empty ReceiveDate should not be ok")
+	}
+}
+
+func TestClassifyErrCode(t *testing.T) {
+	cases := map[string]ErrCodeFamily{
+		"":                          ErrCodeNone,
+		"DELIVRD":                   ErrCodeDelivered,
+		"UNDELIV":                   ErrCodeUndelivered,
+		"EXPIRED":                   ErrCodeExpired,
+		"REJECTD":                   ErrCodeRejected,
+		"MK:0001":                   ErrCodeGateway,
+		"MN:0017":                   ErrCodeNetwork,
+		"MI:0024":                   ErrCodeInterGateway,
+		"DB:0141":                   ErrCodeBlacklist,
+		"IC:0151":                   ErrCodeISMG,
+		"isv.MOBILE_NUMBER_ILLEGAL": ErrCodePlatform,
+		"SGIP:2":                    ErrCodeOther,
+	}
+	for code, want := range cases {
+		if f := ClassifyErrCode(code); f != want {
+			t.Errorf("ClassifyErrCode(%q): %v != %v", code, f, want)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package sms
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"reflect"
+
+	"github.com/scistack/aliyun-sms-go/phone"
 )
 
 // TemplateParam is type of business param "TemplateParam"
@@ -10,11 +15,17 @@ type TemplateParam map[string]string
 
 // String returns the TemplateParam of type JSON string
 func (tp TemplateParam) String() string {
+	s, _ := tp.EncodeParam()
+	return s
+}
+
+// EncodeParam returns the TemplateParam of type JSON string
+func (tp TemplateParam) EncodeParam() (string, error) {
 	data, err := json.Marshal(tp)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
-	return string(data)
+	return string(data), nil
 }
 
 // SendSmsParams is business param of action "SendSms"
@@ -25,6 +36,54 @@ type SendSmsParams struct {
 	TemplateCode  string        `param:"TemplateCode"`
 	TemplateParam TemplateParam `param:"TemplateParam,omitempty"`
 	OutID         string        `param:"OutId,omitempty"`
+
+	// Recipients are typed phone numbers, they are joined into PhoneNumbers
+	// along with the numbers already in PhoneNumbers
+	Recipients []phone.Number `param:"-"`
+
+	// TemplateData is an alternative of TemplateParam,
+	// a struct with "sms" tags or a map with string keys,
+	// it's encoded into TemplateParam by EncodeTemplateParam
+	TemplateData interface{} `param:"-"`
+}
+
+// cleanParams parses and normalizes PhoneNumbers and Recipients into PhoneNumbers,
+// and encodes TemplateData into TemplateParam
+// invalid numbers, duplicates and invalid template params are returned as ValidationError
+func (p *SendSmsParams) cleanParams() error {
+	var errs ValidationError
+
+	var numbers []phone.Number
+	if p.PhoneNumbers != "" {
+		parsed, err := phone.ParseList(p.PhoneNumbers)
+		if err != nil {
+			errs = errs.add("PhoneNumbers", err)
+		}
+		numbers = parsed
+	}
+	numbers = append(numbers, p.Recipients...)
+
+	joined, err := phone.Join(numbers)
+	if err != nil {
+		errs = errs.add("PhoneNumbers", err)
+	}
+	p.PhoneNumbers = joined
+
+	if p.TemplateData != nil {
+		if p.TemplateParam != nil {
+			errs = errs.add("TemplateData", errors.New("both TemplateParam and TemplateData are set"))
+		} else if tp, err := EncodeTemplateParam(p.TemplateData); err != nil {
+			errs = errs.add("TemplateData", err)
+		} else {
+			p.TemplateParam = tp
+		}
+	}
+	errs = append(errs, p.TemplateParam.check()...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
 type sendSmsParams struct {
@@ -45,11 +104,15 @@ type SendSmsOptions interface {
 	TemplateParam() TemplateParam
 	OutID() string
 
+	// Suppressed returns numbers filtered out by Config.Suppression
+	Suppressed() []phone.Number
+
 	Response() *SendSmsResponse
 }
 
 type sendOptions struct {
 	*options
+	suppressed []phone.Number
 }
 
 func (s *sendOptions) Action() ActionType {
@@ -84,6 +147,10 @@ func (s *sendOptions) OutID() string {
 	return s.businessParams.(*sendSmsParams).OutID
 }
 
+func (s *sendOptions) Suppressed() []phone.Number {
+	return s.suppressed
+}
+
 func (s *sendOptions) Response() *SendSmsResponse {
 	return s.res.(*SendSmsResponse)
 }
@@ -96,20 +163,70 @@ type SendSmsAction interface {
 
 type sendAction struct {
 	baseAction
+	params *SendSmsParams
+	err    error
+}
+
+// suppress returns the action of numbers not suppressed by Config.Suppression and the suppressed ones,
+// it's checked on every request, so that a number opted out after the action is built is not sent
+func (a *sendAction) suppress() (*baseAction, []phone.Number) {
+	if a.err != nil || a.c.conf.Suppression == nil {
+		return &a.baseAction, nil
+	}
+	kept, suppressed, err := a.c.conf.Suppression.Filter(*a.params, a.c.conf.Templates)
+	if err == nil && len(suppressed) == 0 {
+		return &a.baseAction, nil
+	}
+
+	params := *a.params
+	if err == nil {
+		if len(kept) == 0 {
+			err = &SuppressedError{Numbers: suppressed}
+		}
+		params.PhoneNumbers, _ = phone.Join(kept)
+	}
+	b := a.baseAction
+	b.businessParams = &sendSmsParams{Action: SendSms, Version: DefaultVersion, SendSmsParams: &params}
+	b.validate = func(opts Options) error {
+		return err
+	}
+	return &b, suppressed
 }
 
 // Do the send action
 func (a *sendAction) Do(extOpts ...Option) (SendSmsOptions, error) {
-	opts, err := a.baseAction.doAction(extOpts...)
+	b, suppressed := a.suppress()
+	if len(suppressed) > 0 {
+		// idempotency keys are of the params of the caller
+		extOpts = append(append([]Option{}, extOpts...), requestedNumbersOption(a.params.PhoneNumbers))
+	}
+	opts, err := b.doAction(extOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return &sendOptions{opts}, nil
+	return &sendOptions{opts, suppressed}, nil
+}
+
+// Build the signed request of numbers not suppressed, see Do
+func (a *sendAction) Build(ctx context.Context, extOpts ...Option) (*http.Request, error) {
+	b, _ := a.suppress()
+	return b.Build(ctx, extOpts...)
+}
+
+// Presign the url of numbers not suppressed, see Do
+func (a *sendAction) Presign(extOpts ...Option) (PresignedURL, error) {
+	b, _ := a.suppress()
+	return b.Presign(extOpts...)
 }
 
 // NewSendAction init an action "SendSms"
 // can be used concurrently
+// params are validated, if there are any problems, the ValidationError is returned from Do
+// numbers suppressed by Config.Suppression are filtered out on every Do, a *SuppressedError
+// is returned from Do if all numbers are suppressed
 func NewSendAction(c Client, params SendSmsParams) SendSmsAction {
+	err := params.validate(c.conf.Templates)
+
 	return &sendAction{
 		baseAction{
 			&c,
@@ -119,8 +236,13 @@ func NewSendAction(c Client, params SendSmsParams) SendSmsAction {
 				SendSmsParams: &params,
 			},
 			reflect.TypeOf(SendSmsResponse{}),
-			defaultReqHandler{},
+			HTTPReqHandler{},
+			func(opts Options) error {
+				return err
+			},
 		},
+		&params,
+		err,
 	}
 }
 
@@ -1,8 +1,11 @@
 package sms
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
+
+	"github.com/scistack/aliyun-sms-go/phone"
 )
 
 type testSendHandler struct {
@@ -16,7 +19,7 @@ var rightSendSmsRes = SendSmsResponse{
 	"199303724724900469^0",
 }
 
-func (h testSendHandler) DoReq(opts Options) ([]byte, error) {
+func (h testSendHandler) DoReq(opts Options) (*HTTPResponse, error) {
 	var body []byte
 	switch opts.Format() {
 	case JSON:
@@ -24,19 +27,19 @@ func (h testSendHandler) DoReq(opts Options) ([]byte, error) {
 	case XML:
 		body = []byte(`<?xml version='1.0' encoding='UTF-8'?><SendSmsResponse><Message>OK</Message><RequestId>6EE2B27D-6833-4D5F-9B9B-CE7FA0A85CC7</RequestId><BizId>199303724724900469^0</BizId><Code>OK</Code></SendSmsResponse>`)
 	}
-	return body, nil
+	return bodyResponse(body), nil
 }
 
 func testSendActionDo(t *testing.T, rightURL string, templateParam TemplateParam, outID string, extOpts ...Option) {
 	extOpts = append(extOpts, SignatureNonce(u4), Timestamp(ts), ReqHandlerOption(testSendHandler{}))
 
 	a := NewSendAction(c, SendSmsParams{
-		"cn-hangzhou",
-		"15300000001",
-		"阿里云短信测试专用",
-		"SMS_71390007",
-		templateParam,
-		outID})
+		RegionID:      "cn-hangzhou",
+		PhoneNumbers:  "15300000001",
+		SignName:      "阿里云短信测试专用",
+		TemplateCode:  "SMS_71390007",
+		TemplateParam: templateParam,
+		OutID:         outID})
 	opts, err := a.Do(extOpts...)
 	if err != nil {
 		t.Errorf("Do \"SendSms\" action err: %v", err)
@@ -75,6 +78,31 @@ func TestSendAction_Do(t *testing.T) {
 		nil, "", XML)
 }
 
+func TestSendAction_DoRecipients(t *testing.T) {
+	a := NewSendAction(c, SendSmsParams{
+		PhoneNumbers: "+86 153-0000-0001",
+		SignName:     "阿里云短信测试专用",
+		TemplateCode: "SMS_71390007",
+		Recipients:   []phone.Number{phone.MustParse("15300000002"), phone.MustParse("+85261234567")},
+	})
+	opts, err := a.Do(ReqHandlerOption(testSendHandler{}))
+	if err != nil {
+		t.Fatalf("Do \"SendSms\" action err: %v", err)
+	}
+	if want := "15300000001,15300000002,85261234567"; opts.PhoneNumbers() != want {
+		t.Errorf("PhoneNumbers: %s != %s", opts.PhoneNumbers(), want)
+	}
+
+	a = NewSendAction(c, SendSmsParams{
+		PhoneNumbers: "15300000001,1530000000",
+		Recipients:   []phone.Number{phone.MustParse("15300000001")},
+	})
+	_, err = a.Do(ReqHandlerOption(testSendHandler{}))
+	if e, ok := err.(ValidationError); !ok || len(e) != 3 || e.Fields()[0] != "PhoneNumbers" {
+		t.Errorf("invalid PhoneNumbers err: %v", err)
+	}
+}
+
 func TestTemplateParam_String(t *testing.T) {
 	data := TemplateParam{"version": "v1.0"}
 	if ds := data.String(); ds != `{"version":"v1.0"}` {
@@ -85,12 +113,12 @@ func TestTemplateParam_String(t *testing.T) {
 // Use test request Handler, no network latency
 func BenchmarkSendAction_Do(b *testing.B) {
 	a := NewSendAction(c, SendSmsParams{
-		"cn-hangzhou",
-		"15300000001",
-		"阿里云短信测试专用",
-		"SMS_71390007",
-		templateParam,
-		outID})
+		RegionID:      "cn-hangzhou",
+		PhoneNumbers:  "15300000001",
+		SignName:      "阿里云短信测试专用",
+		TemplateCode:  "SMS_71390007",
+		TemplateParam: templateParam,
+		OutID:         outID})
 
 	for i := 0; i < b.N; i++ {
 		_, err := a.Do(ReqHandlerOption(testSendHandler{}))
@@ -99,3 +127,9 @@ func BenchmarkSendAction_Do(b *testing.B) {
 		}
 	}
 }
+
+// bodyResponse returns a 200 response of body without Content-Type,
+// it's decoded by Format of the request
+func bodyResponse(body []byte) *HTTPResponse {
+	return &HTTPResponse{StatusCode: http.StatusOK, Body: body}
+}
@@ -0,0 +1,164 @@
+package sms
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// MaxPhoneNumbersPerSend is upper limit of phone numbers in one action "SendSms"
+const MaxPhoneNumbersPerSend = 1000
+
+// DefaultSendConcurrency is default number of concurrent actions of ManySender
+const DefaultSendConcurrency = 4
+
+// ChunkResult is the result of a chunk of phone numbers sent in one action "SendSms"
+type ChunkResult struct {
+	Numbers   []phone.Number
+	BizID     string
+	RequestID string
+
+	// OutID sent with the chunk, OutID of params with suffix "-" and number of the chunk
+	// from 1 if there are more chunks, so that sms of chunks are told apart
+	OutID string
+
+	// Err is the err returned from Do, an *Error if Code is not "OK",
+	// or err of the context if the chunk is not sent
+	Err error
+}
+
+// SendToManyResult is the aggregated result of ManySender
+type SendToManyResult struct {
+	Chunks []*ChunkResult
+
+	byNumber map[phone.Number]*ChunkResult
+}
+
+// Result returns the ChunkResult which n belongs to, nil if n is not sent
+func (r *SendToManyResult) Result(n phone.Number) *ChunkResult {
+	return r.byNumber[n]
+}
+
+// Failed returns phone numbers of all failed chunks,
+// they can be retried without re-sending the successful ones
+func (r *SendToManyResult) Failed() []phone.Number {
+	var numbers []phone.Number
+	for _, chunk := range r.Chunks {
+		if chunk.Err != nil {
+			numbers = append(numbers, chunk.Numbers...)
+		}
+	}
+	return numbers
+}
+
+// Err returns the first err of chunks, nil if all chunks are sent
+func (r *SendToManyResult) Err() error {
+	for _, chunk := range r.Chunks {
+		if chunk.Err != nil {
+			return chunk.Err
+		}
+	}
+	return nil
+}
+
+// ManySender sends one template to any number of phone numbers,
+// the numbers are split into chunks and sent with bounded concurrency
+type ManySender struct {
+	Client Client
+
+	// ChunkSize is number of phone numbers in one action "SendSms",
+	// default and upper limit is MaxPhoneNumbersPerSend
+	ChunkSize int
+
+	// Concurrency is upper limit of concurrent actions, default DefaultSendConcurrency
+	Concurrency int
+
+	// Options are applied to every action "SendSms"
+	Options []Option
+}
+
+// SendToMany sends params to numbers with a default ManySender
+func SendToMany(ctx context.Context, c Client, numbers []phone.Number, params SendSmsParams, extOpts ...Option) (*SendToManyResult, error) {
+	return ManySender{Client: c, Options: extOpts}.SendToMany(ctx, numbers, params)
+}
+
+// SendToMany sends SignName, TemplateCode, TemplateParam and OutID of params to numbers,
+// PhoneNumbers and Recipients of params are ignored, OutID of chunks are in ChunkResult
+// an err is returned only if numbers are invalid, e.g. there are duplicates,
+// errs of chunks are in SendToManyResult
+func (s ManySender) SendToMany(ctx context.Context, numbers []phone.Number, params SendSmsParams) (*SendToManyResult, error) {
+	if _, err := phone.Join(numbers); err != nil {
+		return nil, err
+	}
+
+	size := s.ChunkSize
+	if size <= 0 || size > MaxPhoneNumbersPerSend {
+		size = MaxPhoneNumbersPerSend
+	}
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultSendConcurrency
+	}
+
+	res := &SendToManyResult{byNumber: make(map[phone.Number]*ChunkResult, len(numbers))}
+	for start := 0; start < len(numbers); start += size {
+		end := start + size
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		chunk := &ChunkResult{Numbers: numbers[start:end:end]}
+		res.Chunks = append(res.Chunks, chunk)
+		for _, n := range chunk.Numbers {
+			res.byNumber[n] = chunk
+		}
+	}
+	for i, chunk := range res.Chunks {
+		chunk.OutID = params.OutID
+		if params.OutID != "" && len(res.Chunks) > 1 {
+			chunk.OutID += "-" + strconv.Itoa(i+1)
+		}
+	}
+
+	extOpts := append(append([]Option{}, s.Options...), ContextOption(ctx))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, chunk := range res.Chunks {
+		if err := ctx.Err(); err != nil {
+			chunk.Err = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			chunk.Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(chunk *ChunkResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			p := params
+			p.PhoneNumbers = ""
+			p.Recipients = chunk.Numbers
+			p.OutID = chunk.OutID
+			opts, err := NewSendAction(s.Client, p).Do(extOpts...)
+			if err != nil {
+				chunk.Err = err
+				return
+			}
+			r := opts.Response()
+			chunk.BizID = r.BizID
+			chunk.RequestID = r.RequestID
+			chunk.Err = r.Err()
+		}(chunk)
+	}
+	wg.Wait()
+
+	return res, nil
+}
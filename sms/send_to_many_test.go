@@ -0,0 +1,93 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// testSendToManyHandler fails chunks contain a phone number of failNumber
+type testSendToManyHandler struct {
+	mu         sync.Mutex
+	chunks     []string
+	outIDs     []string
+	failNumber string
+}
+
+func (h *testSendToManyHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	numbers := u.Query().Get("PhoneNumbers")
+
+	h.mu.Lock()
+	h.chunks = append(h.chunks, numbers)
+	h.outIDs = append(h.outIDs, u.Query().Get("OutId"))
+	h.mu.Unlock()
+
+	if strings.Contains(numbers, h.failNumber) {
+		return bodyResponse([]byte(`{"Message":"触发分钟级流控Permits:1","RequestId":"R","Code":"isv.BUSINESS_LIMIT_CONTROL"}`)), nil
+	}
+	first := strings.SplitN(numbers, ",", 2)[0]
+	return bodyResponse([]byte(fmt.Sprintf(`{"Message":"OK","RequestId":"R","BizId":"%s^0","Code":"OK"}`, first))), nil
+}
+
+func TestManySender_SendToMany(t *testing.T) {
+	numbers := make([]phone.Number, 25)
+	for i := range numbers {
+		numbers[i] = phone.MustParse(fmt.Sprintf("153%08d", i))
+	}
+
+	h := &testSendToManyHandler{failNumber: "15300000012"}
+	s := ManySender{Client: c, ChunkSize: 10, Concurrency: 2, Options: []Option{ReqHandlerOption(h)}}
+	res, err := s.SendToMany(context.Background(), numbers, SendSmsParams{SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007", OutID: "o"})
+	if err != nil {
+		t.Fatalf("SendToMany err: %v", err)
+	}
+
+	if len(res.Chunks) != 3 || len(h.chunks) != 3 {
+		t.Fatalf("SendToMany chunks: %d, %d != %d", len(res.Chunks), len(h.chunks), 3)
+	}
+	if r := res.Result(numbers[21]); r.Err != nil || r.BizID != "15300000020^0" {
+		t.Errorf("Result: %+v", r)
+	}
+	if e, ok := res.Err().(*Error); !ok || e.Code != "isv.BUSINESS_LIMIT_CONTROL" {
+		t.Errorf("SendToMany err: %v", res.Err())
+	}
+	// every chunk has its own OutID
+	outIDs := make(map[string]bool)
+	for _, id := range h.outIDs {
+		outIDs[id] = true
+	}
+	if len(outIDs) != 3 || !outIDs["o-1"] || !outIDs["o-3"] || res.Chunks[1].OutID != "o-2" {
+		t.Errorf("OutIDs: %v, %+v", h.outIDs, res.Chunks[1])
+	}
+	failed := res.Failed()
+	if len(failed) != 10 || failed[0] != numbers[10] {
+		t.Errorf("Failed: %v", failed)
+	}
+
+	if _, err := SendToMany(context.Background(), c, []phone.Number{numbers[0], numbers[0]}, SendSmsParams{}); err == nil {
+		t.Error("duplicate numbers should return an err")
+	}
+}
+
+func TestManySender_SendToManyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	numbers := []phone.Number{phone.MustParse("15300000001")}
+	res, err := SendToMany(ctx, c, numbers, SendSmsParams{}, ReqHandlerOption(&testSendToManyHandler{}))
+	if err != nil {
+		t.Fatalf("SendToMany err: %v", err)
+	}
+	if res.Err() != context.Canceled {
+		t.Errorf("SendToMany err: %v != %v", res.Err(), context.Canceled)
+	}
+}
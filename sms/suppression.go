@@ -0,0 +1,308 @@
+package sms
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// OptOutKeywords are replies of recipients to unsubscribe, e.g. "回复TD退订"
+// a reply equals to "TD" or "T" ignoring case, or contains "退订", is an opt-out
+var OptOutKeywords = []string{"TD", "T"}
+
+// optOutPhrase is an opt-out if it's anywhere in the reply
+const optOutPhrase = "退订"
+
+// IsOptOut reports whether the content of an inbound message is an opt-out
+func IsOptOut(content string) bool {
+	content = strings.TrimSpace(content)
+	if strings.Contains(content, optOutPhrase) {
+		return true
+	}
+	for _, k := range OptOutKeywords {
+		if strings.EqualFold(content, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressionScope is the sends a suppression applies to
+type SuppressionScope struct {
+	// SignName the suppression applies to, all signs if it's ""
+	SignName string
+
+	// Types of templates the suppression applies to, all types if it's empty
+	Types []TemplateType
+}
+
+// Suppression is a phone number should not receive sms of Scope
+type Suppression struct {
+	Number    phone.Number
+	Scope     SuppressionScope
+	Reason    string
+	CreatedAt time.Time
+}
+
+// SuppressionStore persists suppressions
+// implementations must be concurrent safe
+type SuppressionStore interface {
+	// Add s, it replaces the suppression of the same Number and Scope
+	Add(s Suppression) error
+
+	// Remove the suppression of the same Number and Scope
+	Remove(n phone.Number, scope SuppressionScope) error
+
+	// List suppressions of n
+	List(n phone.Number) ([]Suppression, error)
+}
+
+// SuppressionList is checked before actions "SendSms" and "SendBatchSms" if it's set to Config.Suppression,
+// suppressed numbers are filtered out of PhoneNumbers, or messages of them are filtered out of Messages
+//
+// types of templates are looked up in Config.Templates of the Client,
+// templates not registered are of unknown type, they are suppressed only by suppressions
+// of all types, so register promotion templates to apply suppressions of promotion,
+// e.g. opt-outs of inbound messages,
+// if Config.Templates is nil, types are unknown and suppressions of any types apply to all templates
+type SuppressionList struct {
+	store SuppressionStore
+
+	// InboundScope is the scope of opt-outs of inbound messages, SignName of the scope
+	// is replaced by the one of the message, default promotion templates only,
+	// or all templates of a Client without Config.Templates
+	InboundScope SuppressionScope
+}
+
+// NewSuppressionList init a SuppressionList of store,
+// a MemorySuppressionStore is used if store is nil
+func NewSuppressionList(store SuppressionStore) *SuppressionList {
+	if store == nil {
+		store = NewMemorySuppressionStore()
+	}
+	return &SuppressionList{
+		store:        store,
+		InboundScope: SuppressionScope{Types: []TemplateType{TemplatePromotion}},
+	}
+}
+
+// Add a suppression of n
+func (l *SuppressionList) Add(n phone.Number, scope SuppressionScope, reason string) error {
+	return l.store.Add(Suppression{Number: n, Scope: scope, Reason: reason, CreatedAt: time.Now()})
+}
+
+// Remove the suppression of n and scope
+func (l *SuppressionList) Remove(n phone.Number, scope SuppressionScope) error {
+	return l.store.Remove(n, scope)
+}
+
+// Suppressed reports whether n should not receive sms of signName and template type,
+// typ is nil if the type of template is unknown, it matches only scopes without Types
+func (l *SuppressionList) Suppressed(n phone.Number, signName string, typ *TemplateType) (bool, error) {
+	return l.suppressed(n, signName, typ, false)
+}
+
+// suppressed is Suppressed, Types of scopes are ignored if anyType
+func (l *SuppressionList) suppressed(n phone.Number, signName string, typ *TemplateType, anyType bool) (bool, error) {
+	suppressions, err := l.store.List(n)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range suppressions {
+		if s.Scope.matches(signName, typ, anyType) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s SuppressionScope) matches(signName string, typ *TemplateType, anyType bool) bool {
+	if s.SignName != "" && s.SignName != signName {
+		return false
+	}
+	if len(s.Types) == 0 || anyType {
+		return true
+	}
+	if typ == nil {
+		return false
+	}
+	for _, t := range s.Types {
+		if t == *typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns numbers kept and dropped for params,
+// PhoneNumbers must be normalized, e.g. by Validate,
+// suppressions of any types apply if templates is nil
+func (l *SuppressionList) Filter(params SendSmsParams, templates *TemplateRegistry) (kept, dropped []phone.Number, err error) {
+	numbers, err := phone.ParseList(params.PhoneNumbers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typ := templateType(templates, params.TemplateCode)
+	for _, n := range numbers {
+		suppressed, err := l.suppressed(n, params.SignName, typ, templates == nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if suppressed {
+			dropped = append(dropped, n)
+		} else {
+			kept = append(kept, n)
+		}
+	}
+	return kept, dropped, nil
+}
+
+// FilterBatch returns messages kept and numbers dropped for params,
+// PhoneNumber of messages must be normalized, e.g. by Validate,
+// suppressions of any types apply if templates is nil
+func (l *SuppressionList) FilterBatch(params SendBatchSmsParams, templates *TemplateRegistry) (kept []BatchMessage, dropped []phone.Number, err error) {
+	typ := templateType(templates, params.TemplateCode)
+	for _, m := range params.Messages {
+		n, err := phone.Parse(m.PhoneNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		suppressed, err := l.suppressed(n, m.SignName, typ, templates == nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if suppressed {
+			dropped = append(dropped, n)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+	return kept, dropped, nil
+}
+
+// templateType returns the type of the template of code, nil if it's not registered
+func templateType(templates *TemplateRegistry, code string) *TemplateType {
+	if templates == nil {
+		return nil
+	}
+	if t, ok := templates.Lookup(code); ok {
+		return &t.Type
+	}
+	return nil
+}
+
+// InboundMessage is an inbound sms of message type "SmsUp" of aliyun sms
+type InboundMessage struct {
+	PhoneNumber string `json:"phone_number"`
+	SendTime    string `json:"send_time"`
+	Content     string `json:"content"`
+	SignName    string `json:"sign_name"`
+	DestCode    string `json:"dest_code"`
+	SequenceID  int64  `json:"sequence_id"`
+}
+
+// ParseInboundMessage parses the JSON body of a "SmsUp" message
+func ParseInboundMessage(data []byte) (InboundMessage, error) {
+	var m InboundMessage
+	err := json.Unmarshal(data, &m)
+	return m, err
+}
+
+// HandleInbound adds a suppression of InboundScope for m if it's an opt-out,
+// returns whether it's an opt-out
+func (l *SuppressionList) HandleInbound(m InboundMessage) (bool, error) {
+	if !IsOptOut(m.Content) {
+		return false, nil
+	}
+	n, err := phone.Parse(m.PhoneNumber)
+	if err != nil {
+		return false, err
+	}
+	scope := l.InboundScope
+	scope.SignName = m.SignName
+	return true, l.Add(n, scope, "inbound: "+strings.TrimSpace(m.Content))
+}
+
+// SuppressedError is returned from Do of action "SendSms" if all phone numbers are suppressed
+type SuppressedError struct {
+	Numbers []phone.Number
+}
+
+func (e *SuppressedError) Error() string {
+	numbers := make([]string, len(e.Numbers))
+	for i, n := range e.Numbers {
+		numbers[i] = n.String()
+	}
+	return "sms: all phone numbers are suppressed: " + strings.Join(numbers, ",")
+}
+
+// MemorySuppressionStore is an in-memory SuppressionStore
+type MemorySuppressionStore struct {
+	mu           sync.RWMutex
+	suppressions map[phone.Number][]Suppression
+}
+
+// NewMemorySuppressionStore init a MemorySuppressionStore
+func NewMemorySuppressionStore() *MemorySuppressionStore {
+	return &MemorySuppressionStore{suppressions: make(map[phone.Number][]Suppression)}
+}
+
+func (s SuppressionScope) equal(o SuppressionScope) bool {
+	if s.SignName != o.SignName || len(s.Types) != len(o.Types) {
+		return false
+	}
+	for i := range s.Types {
+		if s.Types[i] != o.Types[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Add implements SuppressionStore
+func (s *MemorySuppressionStore) Add(sup Suppression) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.suppressions[sup.Number]
+	for i := range list {
+		if list[i].Scope.equal(sup.Scope) {
+			list[i] = sup
+			return nil
+		}
+	}
+	s.suppressions[sup.Number] = append(list, sup)
+	return nil
+}
+
+// Remove implements SuppressionStore
+func (s *MemorySuppressionStore) Remove(n phone.Number, scope SuppressionScope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.suppressions[n]
+	for i := range list {
+		if list[i].Scope.equal(scope) {
+			list = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(s.suppressions, n)
+	} else {
+		s.suppressions[n] = list
+	}
+	return nil
+}
+
+// List implements SuppressionStore
+func (s *MemorySuppressionStore) List(n phone.Number) ([]Suppression, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Suppression(nil), s.suppressions[n]...), nil
+}
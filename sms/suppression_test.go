@@ -0,0 +1,147 @@
+package sms
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+func TestIsOptOut(t *testing.T) {
+	cases := map[string]bool{
+		"TD":     true,
+		" td ":   true,
+		"t":      true,
+		"退订":     true,
+		"回复TD退订": true,
+		"TDD":    false,
+		"1234":   false,
+		"":       false,
+	}
+	for content, want := range cases {
+		if IsOptOut(content) != want {
+			t.Errorf("IsOptOut(%q) != %v", content, want)
+		}
+	}
+}
+
+func TestSuppressionList_HandleInbound(t *testing.T) {
+	l := NewSuppressionList(nil)
+	m, err := ParseInboundMessage([]byte(`{"phone_number":"15300000001","send_time":"2018-04-27 14:19:30","content":"TD","sign_name":"阿里云短信测试专用","dest_code":"1234","sequence_id":1}`))
+	if err != nil {
+		t.Fatalf("ParseInboundMessage err: %v", err)
+	}
+	if ok, err := l.HandleInbound(m); !ok || err != nil {
+		t.Fatalf("HandleInbound: %v, %v", ok, err)
+	}
+	m.PhoneNumber, m.Content = "15300000002", "好的"
+	if ok, _ := l.HandleInbound(m); ok {
+		t.Errorf("HandleInbound is not opt-out")
+	}
+
+	n := phone.MustParse("15300000001")
+	promotion, verification := TemplatePromotion, TemplateVerification
+	cases := []struct {
+		signName string
+		typ      *TemplateType
+		want     bool
+	}{
+		{"阿里云短信测试专用", &promotion, true},
+		// unknown type does not match a scope of promotion
+		{"阿里云短信测试专用", nil, false},
+		{"阿里云短信测试专用", &verification, false},
+		{"其他签名", &promotion, false},
+	}
+	for _, cs := range cases {
+		if ok, _ := l.Suppressed(n, cs.signName, cs.typ); ok != cs.want {
+			t.Errorf("Suppressed(%s, %v) != %v", cs.signName, cs.typ, cs.want)
+		}
+	}
+
+	l.Remove(n, SuppressionScope{SignName: "阿里云短信测试专用", Types: []TemplateType{TemplatePromotion}})
+	if ok, _ := l.Suppressed(n, "阿里云短信测试专用", &promotion); ok {
+		t.Errorf("Suppressed after Remove")
+	}
+}
+
+type testSuppressionHandler struct {
+	numbers string
+}
+
+func (h *testSuppressionHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+	h.numbers = u.Query().Get("PhoneNumbers")
+	return testSendHandler{}.DoReq(opts)
+}
+
+func TestSendAction_DoSuppressed(t *testing.T) {
+	l := NewSuppressionList(nil)
+	l.Add(phone.MustParse("15300000002"), SuppressionScope{}, "manual")
+	l.Add(phone.MustParse("15300000003"), SuppressionScope{Types: []TemplateType{TemplatePromotion}}, "manual")
+	sc := NewClient(Config{
+		AccessKeyID:  "testId",
+		AccessSecret: "testSecret",
+		Templates: NewTemplateRegistry(PriceTable{},
+			Template{Code: "SMS_1", Type: TemplateVerification},
+			Template{Code: "SMS_2", Type: TemplatePromotion},
+		),
+		Suppression: l,
+	})
+
+	cases := []struct {
+		code, numbers string
+		suppressed    int
+	}{
+		{"SMS_1", "15300000001,15300000003", 1},
+		{"SMS_2", "15300000001", 2},
+		// not registered
+		{"SMS_3", "15300000001,15300000003", 1},
+	}
+	for _, cs := range cases {
+		h := &testSuppressionHandler{}
+		params := SendSmsParams{PhoneNumbers: "15300000001,15300000002,15300000003", SignName: "阿里云短信测试专用", TemplateCode: cs.code}
+		opts, err := NewSendAction(sc, params).Do(ReqHandlerOption(h))
+		if err != nil {
+			t.Fatalf("Do err: %v", err)
+		}
+		if h.numbers != cs.numbers || len(opts.Suppressed()) != cs.suppressed || opts.PhoneNumbers() != cs.numbers {
+			t.Errorf("Do(%s): %s, %v", cs.code, h.numbers, opts.Suppressed())
+		}
+	}
+
+	params := SendSmsParams{PhoneNumbers: "15300000002", SignName: "阿里云短信测试专用", TemplateCode: "SMS_1"}
+	_, err := NewSendAction(sc, params).Do(ReqHandlerOption(&testSuppressionHandler{}))
+	if e, ok := err.(*SuppressedError); !ok || len(e.Numbers) != 1 || IsTemporary(err) {
+		t.Errorf("Do err: %v", err)
+	}
+
+	// suppressions added after the action is built are applied
+	a := NewSendAction(sc, SendSmsParams{PhoneNumbers: "15300000001,15300000004", SignName: "阿里云短信测试专用", TemplateCode: "SMS_1"})
+	l.Add(phone.MustParse("15300000004"), SuppressionScope{}, "manual")
+	h := &testSuppressionHandler{}
+	if opts, err := a.Do(ReqHandlerOption(h)); err != nil || h.numbers != "15300000001" || len(opts.Suppressed()) != 1 {
+		t.Errorf("Do after Add: %s, %v", h.numbers, err)
+	}
+	if req, err := a.Build(context.Background()); err != nil || req.URL.Query().Get("PhoneNumbers") != "15300000001" {
+		t.Errorf("Build after Add: %v", err)
+	}
+}
+
+func TestSendAction_DoSuppressedWithoutTemplates(t *testing.T) {
+	l := NewSuppressionList(nil)
+	if ok, err := l.HandleInbound(InboundMessage{PhoneNumber: "15300000002", Content: "TD", SignName: "阿里云短信测试专用"}); !ok || err != nil {
+		t.Fatalf("HandleInbound: %v, %v", ok, err)
+	}
+	sc := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Suppression: l})
+
+	// types of templates are unknown, the opt-out of promotion applies to all templates
+	h := &testSuppressionHandler{}
+	params := SendSmsParams{PhoneNumbers: "15300000001,15300000002", SignName: "阿里云短信测试专用", TemplateCode: "SMS_1"}
+	if opts, err := NewSendAction(sc, params).Do(ReqHandlerOption(h)); err != nil || h.numbers != "15300000001" || len(opts.Suppressed()) != 1 {
+		t.Errorf("Do: %s, %v", h.numbers, err)
+	}
+}
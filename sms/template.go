@@ -0,0 +1,301 @@
+package sms
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// TemplateType is type of sms template
+type TemplateType int
+
+const (
+	// TemplateVerification is type of verification code template
+	TemplateVerification TemplateType = 0
+
+	// TemplateNotification is type of notification template
+	TemplateNotification TemplateType = 1
+
+	// TemplatePromotion is type of promotion template
+	TemplatePromotion TemplateType = 2
+
+	// TemplateInternational is type of international template
+	TemplateInternational TemplateType = 3
+)
+
+// String returns the name of TemplateType
+func (t TemplateType) String() string {
+	switch t {
+	case TemplateVerification:
+		return "verification"
+	case TemplateNotification:
+		return "notification"
+	case TemplatePromotion:
+		return "promotion"
+	case TemplateInternational:
+		return "international"
+	}
+	return "TemplateType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// EncodeParam returns the number of TemplateType, e.g. business param "TemplateType"
+func (t TemplateType) EncodeParam() (string, error) {
+	return strconv.Itoa(int(t)), nil
+}
+
+const (
+	// SingleSegmentChars is upper limit of characters of a single sms in UCS-2
+	SingleSegmentChars = 70
+
+	// ConcatSegmentChars is characters of every segment of a concatenated sms in UCS-2
+	ConcatSegmentChars = 67
+
+	// SingleSegmentSeptets is upper limit of septets of a single sms in GSM-7
+	SingleSegmentSeptets = 160
+
+	// ConcatSegmentSeptets is septets of every segment of a concatenated sms in GSM-7
+	ConcatSegmentSeptets = 153
+)
+
+// Encoding of sms content
+type Encoding int
+
+const (
+	// UCS2 encoding, used by all mainland sms and international sms with non GSM-7 characters
+	UCS2 Encoding = iota
+
+	// GSM7 encoding, used by international sms with only GSM-7 characters
+	GSM7
+)
+
+// String returns the name of Encoding
+func (e Encoding) String() string {
+	if e == GSM7 {
+		return "GSM-7"
+	}
+	return "UCS-2"
+}
+
+var placeholderRegexp = regexp.MustCompile(`\$\{([^${}]*)\}`)
+
+// Template is a local definition of a sms template
+type Template struct {
+	Code string
+
+	// Content with placeholders of variables, e.g. "您的验证码为：${code}"
+	Content string
+
+	SignName string
+	Type     TemplateType
+}
+
+// Placeholders returns distinct names of variables in Content in order
+func (t Template) Placeholders() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRegexp.FindAllStringSubmatch(t.Content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// PriceTable is price of one segment, used to estimate the cost of sms
+type PriceTable struct {
+	// Domestic is price of one segment sent to mainland numbers
+	Domestic float64
+
+	// International is price of one segment sent to international numbers by country code
+	International map[string]float64
+
+	// InternationalDefault is price of one segment sent to country codes not in International
+	InternationalDefault float64
+}
+
+func (pt PriceTable) price(n phone.Number) float64 {
+	if n.IsMainland() {
+		return pt.Domestic
+	}
+	if p, ok := pt.International[n.CountryCode]; ok {
+		return p
+	}
+	return pt.InternationalDefault
+}
+
+// Preview is the rendered sms of SendSmsParams
+type Preview struct {
+	// Text the handset will see, e.g. "【SignName】content",
+	// or "[SignName]content" of TemplateInternational
+	Text string
+
+	// Chars is number of characters of Text in runes, segments are counted
+	// in UTF-16 code units of UCS-2 or septets of GSM-7 instead, see CountSegments
+	Chars int
+
+	// Encoding of Text, it's always UCS2 unless the template is TemplateInternational
+	Encoding Encoding
+
+	// SegmentsPerMessage is number of billed segments of Text sent to one recipient
+	SegmentsPerMessage int
+
+	// Recipients is number of phone numbers
+	Recipients int
+
+	// Segments is number of billed segments of all recipients
+	Segments int
+
+	// Cost is estimated by the PriceTable of TemplateRegistry
+	Cost float64
+}
+
+// ErrTemplateNotFound is returned if the template code is not registered
+var ErrTemplateNotFound = errors.New("sms: template not found")
+
+// TemplateRegistry have local definitions of templates
+// it's concurrent safe
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]Template
+	prices    PriceTable
+}
+
+// NewTemplateRegistry init a TemplateRegistry with templates
+func NewTemplateRegistry(prices PriceTable, templates ...Template) *TemplateRegistry {
+	r := &TemplateRegistry{templates: make(map[string]Template), prices: prices}
+	for _, t := range templates {
+		r.Register(t)
+	}
+	return r
+}
+
+// Register a template, the template of the same code is replaced
+func (r *TemplateRegistry) Register(t Template) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[t.Code] = t
+}
+
+// Lookup the template of code
+func (r *TemplateRegistry) Lookup(code string) (Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.templates[code]
+	return t, ok
+}
+
+// Render the sms of params, the template is looked up by TemplateCode of params
+// SignName of params is preferred over SignName of the template
+// segments are counted by international rules if the template is TemplateInternational,
+// whose sign name is wrapped in ASCII brackets instead of 【】, which are not in GSM-7
+func (r *TemplateRegistry) Render(params SendSmsParams) (*Preview, error) {
+	t, ok := r.Lookup(params.TemplateCode)
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+	if err := params.cleanParams(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	content := placeholderRegexp.ReplaceAllStringFunc(t.Content, func(s string) string {
+		name := s[2 : len(s)-1]
+		value, ok := params.TemplateParam[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return nil, &TemplateParamError{Key: missing[0], Reason: "missing value of template " + t.Code}
+	}
+
+	signName := params.SignName
+	if signName == "" {
+		signName = t.SignName
+	}
+	international := t.Type == TemplateInternational
+	text := "【" + signName + "】" + content
+	if international {
+		text = "[" + signName + "]" + content
+	}
+
+	var numbers []phone.Number
+	if params.PhoneNumbers != "" {
+		numbers, _ = phone.ParseList(params.PhoneNumbers)
+	}
+
+	p := &Preview{
+		Text:               text,
+		Chars:              utf8.RuneCountInString(text),
+		Encoding:           UCS2,
+		SegmentsPerMessage: CountSegments(text, international),
+		Recipients:         len(numbers),
+	}
+	if international {
+		p.Encoding = DetectEncoding(text)
+	}
+	p.Segments = p.SegmentsPerMessage * p.Recipients
+	for _, n := range numbers {
+		p.Cost += float64(p.SegmentsPerMessage) * r.prices.price(n)
+	}
+	return p, nil
+}
+
+// CountSegments returns the number of billed segments of text
+// sms to mainland numbers are always counted in UCS-2 characters,
+// sms to international numbers are counted in GSM-7 septets if possible,
+// UCS-2 characters are UTF-16 code units, e.g. an emoji is 2 characters
+func CountSegments(text string, international bool) int {
+	if international {
+		if septets, ok := gsm7Septets(text); ok {
+			return segments(septets, SingleSegmentSeptets, ConcatSegmentSeptets)
+		}
+	}
+	return segments(len(utf16.Encode([]rune(text))), SingleSegmentChars, ConcatSegmentChars)
+}
+
+// DetectEncoding returns the Encoding of text sent to international numbers
+func DetectEncoding(text string) Encoding {
+	if _, ok := gsm7Septets(text); ok {
+		return GSM7
+	}
+	return UCS2
+}
+
+func segments(n, single, concat int) int {
+	if n <= single {
+		return 1
+	}
+	return (n + concat - 1) / concat
+}
+
+const (
+	gsm7Basic     = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsm7Extension = "\f^{}\\[~]|€"
+)
+
+// gsm7Septets returns the number of septets of text in GSM-7,
+// ok is false if text contains characters not in GSM-7
+func gsm7Septets(text string) (septets int, ok bool) {
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			septets++
+		case strings.ContainsRune(gsm7Extension, r):
+			septets += 2
+		default:
+			return 0, false
+		}
+	}
+	return septets, true
+}
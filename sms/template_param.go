@@ -0,0 +1,181 @@
+package sms
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTemplateParamValueLength is upper limit of characters of a template param value
+const MaxTemplateParamValueLength = 35
+
+// TemplateParamError is returned if a template param cannot be encoded or is invalid
+type TemplateParamError struct {
+	Key    string
+	Reason string
+}
+
+func (e *TemplateParamError) Error() string {
+	return "sms: template param " + strconv.Quote(e.Key) + ": " + e.Reason
+}
+
+// check the keys and the length of values, keys are checked in order
+func (tp TemplateParam) check() ValidationError {
+	keys := make([]string, 0, len(tp))
+	for k := range tp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs ValidationError
+	for _, k := range keys {
+		if k == "" {
+			errs = errs.add("TemplateParam", &TemplateParamError{Key: k, Reason: "empty key"})
+			continue
+		}
+		if n := utf8.RuneCountInString(tp[k]); n > MaxTemplateParamValueLength {
+			errs = errs.add("TemplateParam", &TemplateParamError{
+				Key:    k,
+				Reason: fmt.Sprintf("value has %d characters, upper limit is %d", n, MaxTemplateParamValueLength),
+			})
+		}
+	}
+	return errs
+}
+
+// EncodeTemplateParam encodes v into TemplateParam, v can be
+// a map with string keys, e.g. map[string]interface{},
+// or a struct or a pointer to struct, whose fields are encoded as
+//
+//	Code   string  `sms:"code"`
+//	Amount float64 `sms:"amount,format=%.2f"`
+//	Remark string  `sms:"remark,omitempty"`
+//	Secret string  `sms:"-"`
+//
+// fields without "sms" tag use the field name as key
+// values can be strings, bools, numbers, or fmt.Stringer, or pointers to them,
+// format is applied to the value pointed to, an error is returned if it doesn't fit the value
+func EncodeTemplateParam(v interface{}) (TemplateParam, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Map:
+		return encodeTemplateMap(rv)
+	case reflect.Struct:
+		return encodeTemplateStruct(rv)
+	}
+	return nil, &TemplateParamError{Reason: "unsupported type " + rv.Type().String()}
+}
+
+func encodeTemplateMap(rv reflect.Value) (TemplateParam, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return nil, &TemplateParamError{Reason: "unsupported map key type " + rv.Type().Key().String()}
+	}
+
+	tp := make(TemplateParam, rv.Len())
+	for _, k := range rv.MapKeys() {
+		value, err := formatTemplateValue(rv.MapIndex(k), "")
+		if err != nil {
+			return nil, &TemplateParamError{Key: k.String(), Reason: err.Error()}
+		}
+		tp[k.String()] = value
+	}
+	return tp, nil
+}
+
+func encodeTemplateStruct(rv reflect.Value) (TemplateParam, error) {
+	tp := make(TemplateParam, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		fieldInfo := rv.Type().Field(i)
+		if fieldInfo.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		key, tagOptions := parseTag(fieldInfo.Tag.Get("sms"))
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = fieldInfo.Name
+		}
+
+		field := rv.Field(i)
+		if tagOptions.contains("omitempty") &&
+			reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+			continue
+		}
+
+		value, err := formatTemplateValue(field, tagOptions.value("format"))
+		if err != nil {
+			return nil, &TemplateParamError{Key: key, Reason: err.Error()}
+		}
+		tp[key] = value
+	}
+	return tp, nil
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// formatTemplateValue formats v, pointers and interfaces are dereferenced before format is applied,
+// nil is formatted as ""
+func formatTemplateValue(v reflect.Value, format string) (string, error) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if format != "" {
+		s := fmt.Sprintf(format, v.Interface())
+		if strings.Contains(s, "%!") {
+			return "", fmt.Errorf("bad format %q of type %s: %s", format, v.Type(), s)
+		}
+		return s, nil
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String(), nil
+	}
+	// String of a pointer receiver
+	if v.CanAddr() && v.Addr().Type().Implements(stringerType) {
+		return v.Addr().Interface().(fmt.Stringer).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported value type %s", v.Type())
+}
+
+// value returns the value of option "name=value", or "" if not found
+func (o tagOptions) value(optionName string) string {
+	for _, option := range strings.Split(string(o), ",") {
+		if strings.HasPrefix(option, optionName+"=") {
+			return option[len(optionName)+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testTemplateData struct {
+	Code    string        `sms:"code"`
+	Amount  float64       `sms:"amount,format=%.2f"`
+	Count   int           `sms:"count"`
+	Remark  string        `sms:"remark,omitempty"`
+	Expires time.Duration `sms:"expires"`
+	Secret  string        `sms:"-"`
+	Name    string
+	ignored string
+}
+
+func TestEncodeTemplateParam(t *testing.T) {
+	tp, err := EncodeTemplateParam(&testTemplateData{
+		Code: "123456", Amount: 12.5, Count: 3, Expires: 5 * time.Minute, Secret: "s", Name: "n", ignored: "i",
+	})
+	if err != nil {
+		t.Fatalf("EncodeTemplateParam struct err: %v", err)
+	}
+	want := TemplateParam{"code": "123456", "amount": "12.50", "count": "3", "expires": "5m0s", "Name": "n"}
+	if !reflect.DeepEqual(tp, want) {
+		t.Errorf("EncodeTemplateParam struct: %v != %v", tp, want)
+	}
+
+	tp, err = EncodeTemplateParam(map[string]interface{}{"price": 9.9, "vip": true, "n": uint8(7), "nil": nil})
+	if err != nil {
+		t.Fatalf("EncodeTemplateParam map err: %v", err)
+	}
+	want = TemplateParam{"price": "9.9", "vip": "true", "n": "7", "nil": ""}
+	if !reflect.DeepEqual(tp, want) {
+		t.Errorf("EncodeTemplateParam map: %v != %v", tp, want)
+	}
+
+	if tp, err := EncodeTemplateParam(nil); tp != nil || err != nil {
+		t.Errorf("EncodeTemplateParam nil: %v, %v", tp, err)
+	}
+
+	n, at := 7, time.Date(2018, 5, 20, 13, 14, 0, 0, time.UTC)
+	tp, err = EncodeTemplateParam(struct {
+		N    *int       `sms:"n,format=%03d"`
+		At   *time.Time `sms:"at,format=%02d:%02d"`
+		Date *time.Time `sms:"date"`
+		Nil  *int       `sms:"nil,format=%d"`
+	}{N: &n, Date: &at})
+	if err != nil {
+		t.Fatalf("EncodeTemplateParam pointers err: %v", err)
+	}
+	if want = (TemplateParam{"n": "007", "at": "", "date": at.String(), "nil": ""}); !reflect.DeepEqual(tp, want) {
+		t.Errorf("EncodeTemplateParam pointers: %v != %v", tp, want)
+	}
+
+	errCases := []interface{}{
+		"string",
+		struct {
+			Code string `sms:"code,format=%d"`
+		}{"123456"},
+		struct {
+			At *time.Time `sms:"at,format=%02d:%02d"`
+		}{&at},
+		map[int]string{1: "a"},
+		map[string]interface{}{"nested": []string{"a"}},
+		struct{ Nested struct{} }{},
+	}
+	for _, v := range errCases {
+		if _, err := EncodeTemplateParam(v); err == nil {
+			t.Errorf("EncodeTemplateParam(%#v) should return an err", v)
+		}
+	}
+}
+
+func TestSendAction_DoTemplateData(t *testing.T) {
+	a := NewSendAction(c, SendSmsParams{
+		PhoneNumbers: "15300000001",
+		SignName:     "阿里云短信测试专用",
+		TemplateCode: "SMS_71390007",
+		TemplateData: struct {
+			Version string `sms:"version"`
+		}{"v1.0"},
+	})
+	opts, err := a.Do(ReqHandlerOption(testSendHandler{}))
+	if err != nil {
+		t.Fatalf("Do \"SendSms\" action err: %v", err)
+	}
+	if tp := opts.TemplateParam().String(); tp != `{"version":"v1.0"}` {
+		t.Errorf("TemplateParam: %s != %s", tp, `{"version":"v1.0"}`)
+	}
+
+	// encoding errs are returned from Do
+	errCases := []SendSmsParams{
+		{PhoneNumbers: "15300000001", TemplateData: map[string]interface{}{"a": []int{1}}},
+		{PhoneNumbers: "15300000001", TemplateData: map[string]string{"a": "1"}, TemplateParam: TemplateParam{"a": "1"}},
+		{PhoneNumbers: "15300000001", TemplateParam: TemplateParam{"a": strings.Repeat("长", MaxTemplateParamValueLength+1)}},
+	}
+	for _, params := range errCases {
+		if _, err := NewSendAction(c, params).Do(ReqHandlerOption(testSendHandler{})); err == nil {
+			t.Errorf("Do %+v should return an err", params)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package sms
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testTemplates = NewTemplateRegistry(
+	PriceTable{Domestic: 0.045, International: map[string]float64{"852": 0.3}, InternationalDefault: 0.5},
+	Template{Code: "SMS_132940015", Content: "正在使用Go SDK，版本号：${version}。", SignName: "可乐贩售机", Type: TemplateNotification},
+	Template{Code: "SMS_1", Content: "Your code is ${code}, valid for ${minutes} minutes. ${code}", Type: TemplateInternational},
+)
+
+func TestTemplate_Placeholders(t *testing.T) {
+	tpl, _ := testTemplates.Lookup("SMS_1")
+	if names := tpl.Placeholders(); !reflect.DeepEqual(names, []string{"code", "minutes"}) {
+		t.Errorf("Placeholders: %v", names)
+	}
+}
+
+func TestTemplateRegistry_Render(t *testing.T) {
+	p, err := testTemplates.Render(SendSmsParams{
+		PhoneNumbers:  "15300000001,15300000002",
+		TemplateCode:  "SMS_132940015",
+		TemplateParam: TemplateParam{"version": "v1.0"},
+	})
+	if err != nil {
+		t.Fatalf("Render err: %v", err)
+	}
+	// same as Content of SendDetailDTO
+	if want := rightQuerySendDetailsRes.SmsSendDetailDTOs.SmsSendDetailDTO[0].Content; p.Text != want {
+		t.Errorf("Render text: %s != %s", p.Text, want)
+	}
+	if p.Chars != 27 || p.Encoding != UCS2 || p.SegmentsPerMessage != 1 || p.Recipients != 2 || p.Segments != 2 {
+		t.Errorf("Render preview: %+v", p)
+	}
+	if p.Cost != 0.09 {
+		t.Errorf("Render cost: %v != %v", p.Cost, 0.09)
+	}
+
+	if _, err := testTemplates.Render(SendSmsParams{TemplateCode: "SMS_0"}); err != ErrTemplateNotFound {
+		t.Errorf("Render err: %v != %v", err, ErrTemplateNotFound)
+	}
+	_, err = testTemplates.Render(SendSmsParams{TemplateCode: "SMS_1", TemplateParam: TemplateParam{"code": "1234"}})
+	if e, ok := err.(*TemplateParamError); !ok || e.Key != "minutes" {
+		t.Errorf("Render err: %v", err)
+	}
+}
+
+func TestTemplateRegistry_RenderInternational(t *testing.T) {
+	p, err := testTemplates.Render(SendSmsParams{
+		PhoneNumbers:  "+85261234567,+6591234567",
+		SignName:      "Cola",
+		TemplateCode:  "SMS_1",
+		TemplateParam: TemplateParam{"code": "1234", "minutes": "5"},
+	})
+	if err != nil {
+		t.Fatalf("Render err: %v", err)
+	}
+	if want := "[Cola]Your code is 1234, valid for 5 minutes. 1234"; p.Text != want {
+		t.Errorf("Render text: %s != %s", p.Text, want)
+	}
+	if p.Encoding != GSM7 || p.SegmentsPerMessage != 1 || p.Cost != 0.8 {
+		t.Errorf("Render preview: %+v", p)
+	}
+
+	// more than 70 characters, 1 segment in GSM-7 and 2 in UCS-2
+	p, err = testTemplates.Render(SendSmsParams{
+		PhoneNumbers:  "+85261234567",
+		SignName:      "Cola",
+		TemplateCode:  "SMS_1",
+		TemplateParam: TemplateParam{"code": strings.Repeat("1", 20), "minutes": "10"},
+	})
+	if err != nil {
+		t.Fatalf("Render err: %v", err)
+	}
+	if p.Chars <= SingleSegmentChars || p.Encoding != GSM7 || p.SegmentsPerMessage != 1 {
+		t.Errorf("Render preview: %+v", p)
+	}
+}
+
+func TestCountSegments(t *testing.T) {
+	cases := []struct {
+		text          string
+		international bool
+		want          int
+	}{
+		{strings.Repeat("短", 70), false, 1},
+		{strings.Repeat("短", 71), false, 2},
+		{strings.Repeat("a", 71), false, 2},
+		{strings.Repeat("短", 134), false, 2},
+		{strings.Repeat("短", 135), false, 3},
+		{strings.Repeat("😀", 35), false, 1},
+		{strings.Repeat("😀", 36), false, 2},
+		{strings.Repeat("a", 160), true, 1},
+		{strings.Repeat("a", 161), true, 2},
+		{strings.Repeat("€", 80), true, 1},
+		{strings.Repeat("€", 81), true, 2},
+		{strings.Repeat("a", 70) + "短", true, 2},
+		{strings.Repeat("😀", 35), true, 1},
+		{strings.Repeat("😀", 36), true, 2},
+	}
+	for _, cs := range cases {
+		if n := CountSegments(cs.text, cs.international); n != cs.want {
+			t.Errorf("CountSegments(%d runes, %v): %d != %d", len([]rune(cs.text)), cs.international, n, cs.want)
+		}
+	}
+
+	if DetectEncoding("Hello {world}") != GSM7 || DetectEncoding("你好") != UCS2 {
+		t.Error("DetectEncoding mismatch")
+	}
+}
@@ -0,0 +1,380 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTrackTimeout is the Err of TrackResult if the sms does not reach a final state before timeout
+var ErrTrackTimeout = errors.New("sms: track timeout")
+
+const (
+	// DefaultTrackInterval is default first interval between two polls of Tracker
+	DefaultTrackInterval = 5 * time.Second
+
+	// DefaultTrackMaxInterval is default upper limit of interval between two polls of Tracker
+	DefaultTrackMaxInterval = time.Minute
+
+	// DefaultTrackTimeout is default timeout of tracking a single sms
+	DefaultTrackTimeout = 10 * time.Minute
+)
+
+// TrackerConfig of Tracker
+type TrackerConfig struct {
+	// Interval is the first interval between two polls, default DefaultTrackInterval
+	Interval time.Duration
+
+	// MaxInterval is the upper limit of interval, default DefaultTrackMaxInterval
+	MaxInterval time.Duration
+
+	// Multiplier is the backoff factor of interval, default 2
+	Multiplier float64
+
+	// Timeout of tracking a single sms, default DefaultTrackTimeout
+	Timeout time.Duration
+}
+
+func (conf *TrackerConfig) clean() {
+	if conf.Interval <= 0 {
+		conf.Interval = DefaultTrackInterval
+	}
+	if conf.MaxInterval < conf.Interval {
+		conf.MaxInterval = DefaultTrackMaxInterval
+		if conf.MaxInterval < conf.Interval {
+			conf.MaxInterval = conf.Interval
+		}
+	}
+	if conf.Multiplier < 1 {
+		conf.Multiplier = 2
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultTrackTimeout
+	}
+}
+
+// TrackRequest identifies a sent sms to track
+type TrackRequest struct {
+	BizID       string
+	PhoneNumber string
+	SendDate    Date
+
+	// OutID is optional, if it's set, the sms is matched in one query of
+	// all sms sent to PhoneNumber in SendDate, so that requests of the same
+	// PhoneNumber and SendDate are batched into one api call,
+	// requests without it are queried by BizID until their sms are found,
+	// then they are matched by SendDate, TemplateCode and Content in the same query
+	OutID string
+
+	// TemplateCode is optional, it's used to match the sms along with OutID
+	TemplateCode string
+}
+
+// TrackResult is the result of tracking a sms
+type TrackResult struct {
+	TrackRequest
+
+	// Detail is the last SendDetailDTO found, nil if not found yet
+	Detail *SendDetailDTO
+
+	// Err is nil if the sms reaches a final state,
+	// otherwise it's ErrTrackTimeout or err of the context
+	Err error
+
+	// LastErr is the last err returned from api, if any
+	LastErr error
+}
+
+// Tracker polls action "QuerySendDetails" with backoff
+// until tracked sms reach a final state
+// requests of the same PhoneNumber and SendDate share one poll loop,
+// every request backs off from its own Watch, requests due together are polled together
+// it's concurrent safe
+type Tracker struct {
+	c       Client
+	conf    TrackerConfig
+	extOpts []Option
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	groups map[trackKey]*trackGroup
+}
+
+type trackKey struct {
+	phoneNumber string
+	sendDate    string
+}
+
+type trackGroup struct {
+	entries map[*trackEntry]struct{}
+	wake    chan struct{}
+}
+
+type trackEntry struct {
+	req     TrackRequest
+	fn      func(TrackResult)
+	cancel  context.CancelFunc
+	detail  *SendDetailDTO
+	lastErr error
+
+	// interval and time of the next poll, they are of the poll loop
+	interval time.Duration
+	next     time.Time
+}
+
+// NewTracker init a Tracker
+// extOpts are applied to every action "QuerySendDetails"
+func NewTracker(c Client, conf TrackerConfig, extOpts ...Option) *Tracker {
+	conf.clean()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Tracker{
+		c:       c,
+		conf:    conf,
+		extOpts: extOpts,
+		ctx:     ctx,
+		cancel:  cancel,
+		groups:  make(map[trackKey]*trackGroup),
+	}
+}
+
+// Track the sms returned by action "SendSms"
+// the result is sent to the returned channel once
+func (t *Tracker) Track(ctx context.Context, bizID, phoneNumber string, sendDate Date) <-chan TrackResult {
+	ch := make(chan TrackResult, 1)
+	t.Watch(ctx, TrackRequest{BizID: bizID, PhoneNumber: phoneNumber, SendDate: sendDate}, func(res TrackResult) {
+		ch <- res
+	})
+	return ch
+}
+
+// Watch tracks the sms of req, fn is called once with the result
+func (t *Tracker) Watch(ctx context.Context, req TrackRequest, fn func(TrackResult)) {
+	ctx, cancel := context.WithTimeout(ctx, t.conf.Timeout)
+	e := &trackEntry{req: req, fn: fn, cancel: cancel, interval: t.conf.Interval, next: time.Now().Add(t.conf.Interval)}
+	key := trackKey{phoneNumber: req.PhoneNumber, sendDate: req.SendDate.String()}
+
+	t.mu.Lock()
+	g, ok := t.groups[key]
+	if !ok {
+		g = &trackGroup{entries: make(map[*trackEntry]struct{}), wake: make(chan struct{}, 1)}
+		t.groups[key] = g
+		go t.run(key, g)
+	}
+	g.entries[e] = struct{}{}
+	t.mu.Unlock()
+
+	select {
+	case g.wake <- struct{}{}:
+	default:
+	}
+
+	go func() {
+		<-ctx.Done()
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			err = ErrTrackTimeout
+		}
+		t.finish(key, g, e, err)
+	}()
+}
+
+// Stop the Tracker, all tracking sms are finished with context.Canceled
+func (t *Tracker) Stop() {
+	t.cancel()
+}
+
+func (t *Tracker) run(key trackKey, g *trackGroup) {
+	timer := time.NewTimer(t.conf.Interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			for _, e := range t.entries(key, g) {
+				t.finish(key, g, e, t.ctx.Err())
+			}
+			return
+		case <-g.wake:
+			// a new sms joins, it's polled by its own interval
+		case <-timer.C:
+		}
+
+		entries := t.entries(key, g)
+		if len(entries) == 0 {
+			t.mu.Lock()
+			if len(g.entries) == 0 {
+				delete(t.groups, key)
+				t.mu.Unlock()
+				return
+			}
+			t.mu.Unlock()
+			entries = t.entries(key, g)
+		}
+
+		// entries due soon are polled along with entries due
+		now := time.Now()
+		var due []*trackEntry
+		for _, e := range entries {
+			if !e.next.After(now.Add(t.conf.Interval / 2)) {
+				due = append(due, e)
+			}
+		}
+		if len(due) > 0 {
+			t.poll(key, due)
+			now = time.Now()
+			for _, e := range due {
+				if e.detail != nil && e.detail.SendStatus.IsTerminal() {
+					t.finish(key, g, e, nil)
+					continue
+				}
+				e.interval = time.Duration(float64(e.interval) * t.conf.Multiplier)
+				if e.interval > t.conf.MaxInterval {
+					e.interval = t.conf.MaxInterval
+				}
+				e.next = now.Add(e.interval)
+			}
+		}
+
+		// wait for the earliest entry
+		d := t.conf.Interval
+		for i, e := range entries {
+			if i == 0 || e.next.Sub(now) < d {
+				d = e.next.Sub(now)
+			}
+		}
+		if d < 0 {
+			d = 0
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(d)
+	}
+}
+
+func (t *Tracker) entries(key trackKey, g *trackGroup) []*trackEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries := make([]*trackEntry, 0, len(g.entries))
+	for e := range g.entries {
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// finish removes e from g and calls its fn, it's a no-op if e is already finished
+func (t *Tracker) finish(key trackKey, g *trackGroup, e *trackEntry, err error) {
+	t.mu.Lock()
+	if _, ok := g.entries[e]; !ok {
+		t.mu.Unlock()
+		return
+	}
+	delete(g.entries, e)
+	res := TrackResult{TrackRequest: e.req, Detail: e.detail, Err: err, LastErr: e.lastErr}
+	t.mu.Unlock()
+
+	e.cancel()
+	e.fn(res)
+}
+
+// poll queries details of entries, all entries are of the same key
+func (t *Tracker) poll(key trackKey, entries []*trackEntry) {
+	extOpts := append(append([]Option{}, t.extOpts...), ContextOption(t.ctx))
+	params := QuerySendDetailsParams{PhoneNumber: key.phoneNumber, SendDate: entries[0].req.SendDate}
+
+	// entries with OutID, or of sms found by BizID before, are matched in
+	// one query of all sms of the key, the others are queried by BizID
+	var rest []*trackEntry
+	var batched []*trackEntry
+	for _, e := range entries {
+		if e.req.OutID != "" || e.detail != nil {
+			batched = append(batched, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+
+	if len(batched) > 0 {
+		details, err := QueryAllSendDetails(t.c, params, extOpts...)
+		for _, e := range batched {
+			if err != nil {
+				t.setResult(e, nil, err)
+				continue
+			}
+			var d *SendDetailDTO
+			if e.req.OutID != "" {
+				d = matchOutID(details, e.req)
+			} else {
+				d = matchDetail(details, e.detail)
+			}
+			if d != nil {
+				t.setResult(e, d, nil)
+			} else if e.req.BizID != "" {
+				rest = append(rest, e)
+			}
+		}
+	}
+
+	// one query per BizID for the others
+	byBizID := make(map[string][]*trackEntry)
+	for _, e := range rest {
+		byBizID[e.req.BizID] = append(byBizID[e.req.BizID], e)
+	}
+	for bizID, es := range byBizID {
+		p := params
+		p.BizID = bizID
+		details, err := QueryAllSendDetails(t.c, p, extOpts...)
+		var d *SendDetailDTO
+		if err == nil && len(details) > 0 {
+			d = &details[len(details)-1]
+		}
+		for _, e := range es {
+			t.setResult(e, d, err)
+		}
+	}
+}
+
+func (t *Tracker) setResult(e *trackEntry, d *SendDetailDTO, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if d != nil {
+		e.detail = d
+	}
+	if err != nil {
+		e.lastErr = err
+	}
+}
+
+func matchOutID(details []SendDetailDTO, req TrackRequest) *SendDetailDTO {
+	for i := len(details) - 1; i >= 0; i-- {
+		d := details[i]
+		if d.OutID == req.OutID && (req.TemplateCode == "" || d.TemplateCode == req.TemplateCode) {
+			return &d
+		}
+	}
+	return nil
+}
+
+// matchDetail returns the detail of the same sms as found, it's nil unless
+// exactly one detail is of the same SendDate, TemplateCode and Content
+func matchDetail(details []SendDetailDTO, found *SendDetailDTO) *SendDetailDTO {
+	var match *SendDetailDTO
+	for i := range details {
+		d := details[i]
+		if d.SendDate == found.SendDate && d.TemplateCode == found.TemplateCode && d.Content == found.Content {
+			if match != nil {
+				return nil
+			}
+			match = &d
+		}
+	}
+	return match
+}
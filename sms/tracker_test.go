@@ -0,0 +1,195 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testTrackHandler returns status waiting for the first `waiting` calls, then delivered
+type testTrackHandler struct {
+	mu      sync.Mutex
+	calls   int
+	waiting int
+	queries []url.Values
+}
+
+func (h *testTrackHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	h.queries = append(h.queries, u.Query())
+
+	status, receiveDate := SendStatusWaiting, ""
+	if h.calls > h.waiting {
+		status, receiveDate = SendStatusDelivered, "2018-04-27 14:19:35"
+	}
+	body := fmt.Sprintf(`{"TotalCount":2,"Message":"OK","RequestId":"R","Code":"OK","SmsSendDetailDTOs":{"SmsSendDetailDTO":[`+
+		`{"OutId":"1","SendDate":"2018-04-27 14:19:30","SendStatus":%d,"ReceiveDate":"%s","PhoneNum":"15300000001"},`+
+		`{"OutId":"2","SendDate":"2018-04-27 14:19:30","SendStatus":%d,"ReceiveDate":"%s","PhoneNum":"15300000001"}]}}`,
+		status, receiveDate, status, receiveDate)
+	return bodyResponse([]byte(body)), nil
+}
+
+func TestTracker_Track(t *testing.T) {
+	h := &testTrackHandler{waiting: 2}
+	tr := NewTracker(c, TrackerConfig{Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond}, ReqHandlerOption(h))
+	defer tr.Stop()
+
+	res := <-tr.Track(context.Background(), "199303724724900469^0", "15300000001", Date(time.Now()))
+	if res.Err != nil {
+		t.Fatalf("Track err: %v", res.Err)
+	}
+	if res.Detail == nil || res.Detail.SendStatus != SendStatusDelivered {
+		t.Fatalf("Track detail: %v", res.Detail)
+	}
+	if h.calls != 3 {
+		t.Errorf("Track calls: %d != %d", h.calls, 3)
+	}
+	if bizID := h.queries[0].Get("BizId"); bizID != "199303724724900469^0" {
+		t.Errorf("Track BizId: %s != %s", bizID, "199303724724900469^0")
+	}
+}
+
+func TestTracker_WatchBatched(t *testing.T) {
+	h := &testTrackHandler{waiting: 1}
+	tr := NewTracker(c, TrackerConfig{Interval: 10 * time.Millisecond}, ReqHandlerOption(h))
+	defer tr.Stop()
+
+	var wg sync.WaitGroup
+	results := make([]TrackResult, 2)
+	for i := range results {
+		wg.Add(1)
+		i := i
+		req := TrackRequest{PhoneNumber: "15300000001", SendDate: Date(time.Now()), OutID: fmt.Sprint(i + 1)}
+		tr.Watch(context.Background(), req, func(res TrackResult) {
+			results[i] = res
+			wg.Done()
+		})
+	}
+	wg.Wait()
+
+	for i, res := range results {
+		if res.Err != nil || res.Detail == nil || res.Detail.OutID != fmt.Sprint(i+1) {
+			t.Errorf("Watch result %d: %+v", i, res)
+		}
+	}
+	// both sms share one query per poll
+	if h.calls != 2 {
+		t.Errorf("Watch calls: %d != %d", h.calls, 2)
+	}
+	if bizID := h.queries[0].Get("BizId"); bizID != "" {
+		t.Errorf("batched query should not filter by BizId: %s", bizID)
+	}
+}
+
+func TestTracker_Timeout(t *testing.T) {
+	h := &testTrackHandler{waiting: 1 << 30}
+	tr := NewTracker(c, TrackerConfig{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}, ReqHandlerOption(h))
+	defer tr.Stop()
+
+	res := <-tr.Track(context.Background(), "1^0", "15300000001", Date(time.Now()))
+	if res.Err != ErrTrackTimeout {
+		t.Errorf("Track err: %v != %v", res.Err, ErrTrackTimeout)
+	}
+	if res.Detail == nil || res.Detail.SendStatus != SendStatusWaiting {
+		t.Errorf("Track detail: %v", res.Detail)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res := <-tr.Track(ctx, "1^0", "15300000001", Date(time.Now())); res.Err != context.Canceled {
+		t.Errorf("Track err: %v != %v", res.Err, context.Canceled)
+	}
+}
+
+// testBizIDHandler returns the sms of BizId if it's queried, or all sms of the phone number,
+// status is waiting for the first `waiting` calls, then delivered
+type testBizIDHandler struct {
+	testTrackHandler
+}
+
+func (h *testBizIDHandler) DoReq(opts Options) (*HTTPResponse, error) {
+	u, err := url.Parse(opts.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	h.queries = append(h.queries, u.Query())
+
+	status := SendStatusWaiting
+	if h.calls > h.waiting {
+		status = SendStatusDelivered
+	}
+	var dtos []string
+	for i, bizID := range []string{"1^0", "2^0"} {
+		if q := u.Query().Get("BizId"); q == "" || q == bizID {
+			dtos = append(dtos, fmt.Sprintf(`{"SendDate":"2018-04-27 14:19:3%d","SendStatus":%d,"Content":"c","PhoneNum":"15300000001"}`, i, status))
+		}
+	}
+	body := fmt.Sprintf(`{"TotalCount":%d,"Message":"OK","RequestId":"R","Code":"OK","SmsSendDetailDTOs":{"SmsSendDetailDTO":[%s]}}`,
+		len(dtos), strings.Join(dtos, ","))
+	return bodyResponse([]byte(body)), nil
+}
+
+func TestTracker_WatchBizIDBatched(t *testing.T) {
+	h := &testBizIDHandler{testTrackHandler{waiting: 2}}
+	tr := NewTracker(c, TrackerConfig{Interval: 10 * time.Millisecond}, ReqHandlerOption(h))
+	defer tr.Stop()
+
+	var wg sync.WaitGroup
+	results := make([]TrackResult, 2)
+	for i, bizID := range []string{"1^0", "2^0"} {
+		wg.Add(1)
+		i := i
+		tr.Watch(context.Background(), TrackRequest{BizID: bizID, PhoneNumber: "15300000001", SendDate: Date(time.Now())}, func(res TrackResult) {
+			results[i] = res
+			wg.Done()
+		})
+	}
+	wg.Wait()
+
+	for i, res := range results {
+		if res.Err != nil || res.Detail == nil || res.Detail.SendStatus != SendStatusDelivered || res.Detail.SendDate != fmt.Sprintf("2018-04-27 14:19:3%d", i) {
+			t.Errorf("Watch result %d: %+v", i, res)
+		}
+	}
+	// sms are found by BizID, then they share one query per poll
+	if h.calls != 3 || h.queries[2].Get("BizId") != "" {
+		t.Errorf("Watch calls: %d, queries: %v", h.calls, h.queries)
+	}
+}
+
+func TestTracker_WatchKeepsInterval(t *testing.T) {
+	h := &testTrackHandler{waiting: 1 << 30}
+	tr := NewTracker(c, TrackerConfig{Interval: 20 * time.Millisecond, MaxInterval: 20 * time.Millisecond}, ReqHandlerOption(h))
+	defer tr.Stop()
+
+	first := tr.Track(context.Background(), "1^0", "15300000001", Date(time.Now()))
+	// new sms of the same phone number keep joining before the first one is polled
+	for i := 0; i < 4; i++ {
+		time.Sleep(8 * time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		tr.Track(ctx, "2^0", "15300000001", Date(time.Now()))
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	<-tr.Track(ctx, "3^0", "15300000001", Date(time.Now()))
+	tr.Stop()
+	if res := <-first; res.Detail == nil {
+		t.Errorf("the first sms is not polled: %+v", res)
+	}
+}
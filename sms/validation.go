@@ -0,0 +1,317 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+)
+
+// QueryMaxDays is the number of recent days, including today, which action "QuerySendDetails" supports
+const QueryMaxDays = 30
+
+// ErrRequired is the Err of FieldError if a required field is empty
+var ErrRequired = errors.New("required")
+
+var templateCodeRegexp = regexp.MustCompile(`^SMS_[0-9]+$`)
+
+// FieldError is a problem of a single field of params
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// ValidationError lists every problem of params
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "sms: invalid params: " + strings.Join(msgs, "; ")
+}
+
+// Fields returns distinct names of fields with problems in order
+func (e ValidationError) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, err := range e {
+		if !seen[err.Field] {
+			seen[err.Field] = true
+			fields = append(fields, err.Field)
+		}
+	}
+	return fields
+}
+
+func (e ValidationError) has(field string) bool {
+	for _, err := range e {
+		if err.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// add err of field, phone.ListError is split into one FieldError per phone number
+func (e ValidationError) add(field string, err error) ValidationError {
+	switch err := err.(type) {
+	case phone.ListError:
+		for _, pe := range err {
+			e = append(e, &FieldError{Field: field, Err: pe})
+		}
+	case ValidationError:
+		e = append(e, err...)
+	default:
+		e = append(e, &FieldError{Field: field, Err: err})
+	}
+	return e
+}
+
+// Validate params before sending, all problems are returned at once as ValidationError
+func (p SendSmsParams) Validate() error {
+	return p.validate(nil)
+}
+
+// Validate params against the registered template of TemplateCode,
+// placeholders of the template and keys of TemplateParam must match
+func (r *TemplateRegistry) Validate(params SendSmsParams) error {
+	return params.validate(r)
+}
+
+func (p *SendSmsParams) validate(templates *TemplateRegistry) error {
+	var errs ValidationError
+	if err := p.cleanParams(); err != nil {
+		errs = errs.add("", err)
+	}
+
+	if p.PhoneNumbers == "" {
+		if !errs.has("PhoneNumbers") {
+			errs = errs.add("PhoneNumbers", ErrRequired)
+		}
+	} else if n := strings.Count(p.PhoneNumbers, ",") + 1; n > MaxPhoneNumbersPerSend {
+		errs = errs.add("PhoneNumbers", fmt.Errorf("%d phone numbers, upper limit is %d", n, MaxPhoneNumbersPerSend))
+	}
+
+	if p.SignName == "" {
+		errs = errs.add("SignName", ErrRequired)
+	}
+
+	switch {
+	case p.TemplateCode == "":
+		errs = errs.add("TemplateCode", ErrRequired)
+	case !templateCodeRegexp.MatchString(p.TemplateCode):
+		errs = errs.add("TemplateCode", fmt.Errorf("malformed %q, expected format is SMS_123456", p.TemplateCode))
+	case templates != nil:
+		if t, ok := templates.Lookup(p.TemplateCode); ok {
+			errs = append(errs, t.checkParam(p.TemplateParam)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkParam returns problems of missing and unknown keys of tp
+func (t Template) checkParam(tp TemplateParam) ValidationError {
+	var errs ValidationError
+	placeholders := make(map[string]bool)
+	for _, name := range t.Placeholders() {
+		placeholders[name] = true
+		if _, ok := tp[name]; !ok {
+			errs = errs.add("TemplateParam", &TemplateParamError{Key: name, Reason: "missing value of template " + t.Code})
+		}
+	}
+
+	var unknown []string
+	for k := range tp {
+		if !placeholders[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		errs = errs.add("TemplateParam", &TemplateParamError{Key: k, Reason: "unknown key of template " + t.Code})
+	}
+	return errs
+}
+
+// Validate params before sending, all problems are returned at once as ValidationError
+func (p SendBatchSmsParams) Validate() error {
+	p.Messages = append([]BatchMessage(nil), p.Messages...)
+	return p.validate(nil)
+}
+
+// validate normalizes phone numbers of Messages in place,
+// fields of messages are named like "Messages[0].PhoneNumber"
+func (p *SendBatchSmsParams) validate(templates *TemplateRegistry) error {
+	var errs ValidationError
+
+	switch n := len(p.Messages); {
+	case n == 0:
+		errs = errs.add("Messages", ErrRequired)
+	case n > MaxMessagesPerBatch:
+		errs = errs.add("Messages", fmt.Errorf("%d messages, upper limit is %d", n, MaxMessagesPerBatch))
+	}
+
+	var t Template
+	var registered bool
+	switch {
+	case p.TemplateCode == "":
+		errs = errs.add("TemplateCode", ErrRequired)
+	case !templateCodeRegexp.MatchString(p.TemplateCode):
+		errs = errs.add("TemplateCode", fmt.Errorf("malformed %q, expected format is SMS_123456", p.TemplateCode))
+	case templates != nil:
+		t, registered = templates.Lookup(p.TemplateCode)
+	}
+
+	for i := range p.Messages {
+		m := &p.Messages[i]
+		field := fmt.Sprintf("Messages[%d].", i)
+		if m.PhoneNumber == "" {
+			errs = errs.add(field+"PhoneNumber", ErrRequired)
+		} else if n, err := phone.Parse(m.PhoneNumber); err != nil {
+			errs = errs.add(field+"PhoneNumber", err)
+		} else {
+			m.PhoneNumber = n.String()
+		}
+		if m.SignName == "" {
+			errs = errs.add(field+"SignName", ErrRequired)
+		}
+		for _, err := range m.TemplateParam.check() {
+			errs = errs.add(field+err.Field, err.Err)
+		}
+		if registered {
+			for _, err := range t.checkParam(m.TemplateParam) {
+				errs = errs.add(field+err.Field, err.Err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate params before querying, SendDate is validated against the current time,
+// all problems are returned at once as ValidationError
+func (p QuerySendDetailsParams) Validate() error {
+	p.cleanParams()
+	return p.validate(time.Now())
+}
+
+func (p *QuerySendDetailsParams) validate(now time.Time) error {
+	var errs ValidationError
+
+	if p.PhoneNumber == "" {
+		errs = errs.add("PhoneNumber", ErrRequired)
+	} else if _, err := phone.Parse(p.PhoneNumber); err != nil {
+		errs = errs.add("PhoneNumber", err)
+	}
+
+	if time.Time(p.SendDate).IsZero() {
+		errs = errs.add("SendDate", ErrRequired)
+	} else {
+		// both dates are compared in calendar days of ChinaStandardTime
+		sendDate, _ := time.Parse("20060102", p.SendDate.String())
+		today, _ := time.Parse("20060102", now.In(ChinaStandardTime).Format("20060102"))
+		switch days := int(today.Sub(sendDate).Hours() / 24); {
+		case days < -1:
+			// one day of tolerance for callers in time zones ahead of ChinaStandardTime
+			errs = errs.add("SendDate", fmt.Errorf("%s is in the future", p.SendDate))
+		case days >= QueryMaxDays:
+			errs = errs.add("SendDate", fmt.Errorf("%s is older than %d days", p.SendDate, QueryMaxDays))
+		}
+	}
+
+	if p.PageSize < QueryMinPageSize || p.PageSize > QueryMaxPageSize {
+		errs = errs.add("PageSize", fmt.Errorf("%d is out of range [%d, %d]", p.PageSize, QueryMinPageSize, QueryMaxPageSize))
+	}
+	if p.CurrentPage < 1 {
+		errs = errs.add("CurrentPage", fmt.Errorf("%d is less than 1", p.CurrentPage))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate params before querying, all problems are returned at once as ValidationError
+func (p QuerySendStatisticsParams) Validate() error {
+	p.cleanParams()
+	return p.validate()
+}
+
+func (p *QuerySendStatisticsParams) validate() error {
+	var errs ValidationError
+
+	if p.IsGlobe != Domestic && p.IsGlobe != International {
+		errs = errs.add("IsGlobe", fmt.Errorf("%d is neither Domestic nor International", p.IsGlobe))
+	}
+	start, end := time.Time(p.StartDate), time.Time(p.EndDate)
+	if start.IsZero() {
+		errs = errs.add("StartDate", ErrRequired)
+	}
+	if end.IsZero() {
+		errs = errs.add("EndDate", ErrRequired)
+	}
+	if !start.IsZero() && !end.IsZero() && p.EndDate.String() < p.StartDate.String() {
+		errs = errs.add("EndDate", fmt.Errorf("%s is before StartDate %s", p.EndDate, p.StartDate))
+	}
+	errs = append(errs, validatePage(p.PageIndex, p.PageSize)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate params before querying, all problems are returned at once as ValidationError
+func (p QuerySmsTemplateListParams) Validate() error {
+	p.cleanParams()
+	return p.validate()
+}
+
+func (p *QuerySmsTemplateListParams) validate() error {
+	if errs := validatePage(p.PageIndex, p.PageSize); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate params before querying, all problems are returned at once as ValidationError
+func (p QuerySmsSignListParams) Validate() error {
+	p.cleanParams()
+	return p.validate()
+}
+
+func (p *QuerySmsSignListParams) validate() error {
+	if errs := validatePage(p.PageIndex, p.PageSize); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validatePage(index, size int) ValidationError {
+	var errs ValidationError
+	if size < QueryMinPageSize || size > QueryMaxPageSize {
+		errs = errs.add("PageSize", fmt.Errorf("%d is out of range [%d, %d]", size, QueryMinPageSize, QueryMaxPageSize))
+	}
+	if index < 1 {
+		errs = errs.add("PageIndex", fmt.Errorf("%d is less than 1", index))
+	}
+	return errs
+}
@@ -0,0 +1,135 @@
+package sms
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendSmsParams_Validate(t *testing.T) {
+	valid := SendSmsParams{PhoneNumbers: "15300000001", SignName: "阿里云短信测试专用", TemplateCode: "SMS_71390007"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	err := SendSmsParams{
+		PhoneNumbers:  "15300000001,123,15300000001",
+		TemplateCode:  "71390007",
+		TemplateParam: TemplateParam{"a": strings.Repeat("a", MaxTemplateParamValueLength+1)},
+	}.Validate()
+	e, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("Validate err: %v", err)
+	}
+	if len(e) != 5 {
+		t.Errorf("Validate problems: %d != %d: %v", len(e), 5, e)
+	}
+	if fields := e.Fields(); !reflect.DeepEqual(fields, []string{"PhoneNumbers", "TemplateParam", "SignName", "TemplateCode"}) {
+		t.Errorf("Validate fields: %v", fields)
+	}
+
+	err = SendSmsParams{}.Validate()
+	if e, ok := err.(ValidationError); !ok || e[0].Err != ErrRequired || len(e) != 3 {
+		t.Errorf("Validate err: %v", err)
+	}
+}
+
+func TestTemplateRegistry_Validate(t *testing.T) {
+	params := SendSmsParams{
+		PhoneNumbers:  "15300000001",
+		SignName:      "可乐贩售机",
+		TemplateCode:  "SMS_132940015",
+		TemplateParam: TemplateParam{"version": "v1.0"},
+	}
+	if err := testTemplates.Validate(params); err != nil {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	params.TemplateParam = TemplateParam{"ver": "v1.0"}
+	err := testTemplates.Validate(params)
+	e, ok := err.(ValidationError)
+	if !ok || len(e) != 2 {
+		t.Fatalf("Validate err: %v", err)
+	}
+	if e[0].Err.(*TemplateParamError).Key != "version" || e[1].Err.(*TemplateParamError).Key != "ver" {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	// Templates of Config are validated in Do
+	tc := NewClient(Config{AccessKeyID: "testId", AccessSecret: "testSecret", Templates: testTemplates})
+	if _, err := NewSendAction(tc, params).Do(ReqHandlerOption(testSendHandler{})); err == nil {
+		t.Error("Do should validate TemplateParam against Templates of Config")
+	}
+}
+
+func TestQuerySendDetailsParams_Validate(t *testing.T) {
+	now := time.Now()
+	if err := (QuerySendDetailsParams{PhoneNumber: "15300000001", SendDate: Date(now)}).Validate(); err != nil {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	err := QuerySendDetailsParams{PhoneNumber: "1530000000", SendDate: Date(now.AddDate(0, 0, -QueryMaxDays-1))}.Validate()
+	if e, ok := err.(ValidationError); !ok || !reflect.DeepEqual(e.Fields(), []string{"PhoneNumber", "SendDate"}) {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	err = QuerySendDetailsParams{}.Validate()
+	if e, ok := err.(ValidationError); !ok || !reflect.DeepEqual(e.Fields(), []string{"PhoneNumber", "SendDate"}) {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	// SendDate is validated against Timestamp of the request in Do
+	a := NewQuerySendDetailsAction(c, QuerySendDetailsParams{PhoneNumber: "15300000001", SendDate: DateStr("20180502")})
+	if _, err := a.Do(Timestamp(ts), ReqHandlerOption(testQuerySendDetailsHandler{})); err == nil {
+		t.Error("Do should validate SendDate in the future")
+	}
+	if _, err := a.Do(Timestamp(ts.AddDate(0, 0, 1)), ReqHandlerOption(testQuerySendDetailsHandler{})); err == nil {
+		t.Error("Do should validate SendDate in the future")
+	}
+	if _, err := a.Do(Timestamp(ts.AddDate(0, 0, 30)), ReqHandlerOption(testQuerySendDetailsHandler{})); err != nil {
+		t.Errorf("Do err: %v", err)
+	}
+}
+
+func TestSendBatchSmsParams_Validate(t *testing.T) {
+	params := SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages:     []BatchMessage{{PhoneNumber: "+86 15300000001", SignName: "sign"}},
+	}
+	if err := params.Validate(); err != nil {
+		t.Errorf("Validate err: %v", err)
+	}
+	if params.Messages[0].PhoneNumber != "+86 15300000001" {
+		t.Errorf("Validate modifies params: %+v", params.Messages)
+	}
+
+	err := SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages: []BatchMessage{
+			{PhoneNumber: "15300000001", SignName: "sign"},
+			{PhoneNumber: "123", TemplateParam: TemplateParam{"": "a"}},
+		},
+	}.Validate()
+	e, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("Validate err: %v", err)
+	}
+	if fields := e.Fields(); !reflect.DeepEqual(fields, []string{"Messages[1].PhoneNumber", "Messages[1].SignName", "Messages[1].TemplateParam"}) {
+		t.Errorf("Validate fields: %v", fields)
+	}
+
+	err = SendBatchSmsParams{Messages: make([]BatchMessage, MaxMessagesPerBatch+1)}.Validate()
+	if e, ok := err.(ValidationError); !ok || e.Fields()[0] != "Messages" || e.Fields()[1] != "TemplateCode" {
+		t.Errorf("Validate err: %v", err)
+	}
+
+	registry := NewTemplateRegistry(PriceTable{}, Template{Code: "SMS_71390007", Content: "${code}"})
+	_, err = NewSendBatchSmsAction(NewClient(Config{Templates: registry}), SendBatchSmsParams{
+		TemplateCode: "SMS_71390007",
+		Messages:     []BatchMessage{{PhoneNumber: "15300000001", SignName: "sign"}},
+	}).Presign()
+	if e, ok := err.(ValidationError); !ok || e.Fields()[0] != "Messages[0].TemplateParam" {
+		t.Errorf("Presign err: %v", err)
+	}
+}
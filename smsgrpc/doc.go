@@ -0,0 +1,15 @@
+// Package smsgrpc is a grpc service in front of aliyun sms api,
+// service "aliyun.sms.v1.Sms" is defined in smspb/sms.proto:
+//
+//	SendSms                send a template to phone numbers
+//	SendBatchSms           send a template with a sign name and param per phone number
+//	QuerySendDetails       send details of a phone number of a day
+//	StreamDeliveryReports  stream reports of sent sms once they reach a final state
+//
+// the package is built with tag "grpc", google.golang.org/grpc,
+// google.golang.org/protobuf and google.golang.org/genproto/googleapis/rpc
+// are required to build it, they are declared in Gopkg.toml
+//
+//	dep ensure
+//	go build -tags grpc ./smsgrpc/...
+package smsgrpc
@@ -0,0 +1,249 @@
+//go:build grpc
+// +build grpc
+
+package smsgrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smsgrpc/smspb"
+)
+
+// DefaultMaxTracked is default upper limit of sms in one StreamDeliveryReportsRequest
+const DefaultMaxTracked = 1000
+
+// Config of Server
+type Config struct {
+	Client sms.Client
+
+	// Options are applied to every action
+	Options []sms.Option
+
+	// Tracker polls reports of StreamDeliveryReports,
+	// default a Tracker of Client with default TrackerConfig, which is stopped by Stop
+	Tracker *sms.Tracker
+
+	// MaxTracked is upper limit of sms in one StreamDeliveryReportsRequest, default DefaultMaxTracked
+	MaxTracked int
+}
+
+// Server implements smspb.SmsServer by actions of Client
+// it's concurrent safe
+type Server struct {
+	smspb.UnimplementedSmsServer
+
+	conf       Config
+	ownTracker bool
+}
+
+// NewServer init a Server, it's registered by smspb.RegisterSmsServer
+func NewServer(conf Config) *Server {
+	s := &Server{conf: conf}
+	if s.conf.Tracker == nil {
+		s.conf.Tracker = sms.NewTracker(conf.Client, sms.TrackerConfig{}, conf.Options...)
+		s.ownTracker = true
+	}
+	if s.conf.MaxTracked <= 0 {
+		s.conf.MaxTracked = DefaultMaxTracked
+	}
+	return s
+}
+
+// Stop the default Tracker, running StreamDeliveryReports end with
+// reports of sms.ErrTrackTimeout
+func (s *Server) Stop() {
+	if s.ownTracker {
+		s.conf.Tracker.Stop()
+	}
+}
+
+func (s *Server) options(ctx context.Context) []sms.Option {
+	return append(append([]sms.Option(nil), s.conf.Options...), sms.ContextOption(ctx))
+}
+
+// SendSms implements smspb.SmsServer
+func (s *Server) SendSms(ctx context.Context, req *smspb.SendSmsRequest) (*smspb.SendSmsResponse, error) {
+	opts, err := sms.NewSendAction(s.conf.Client, sms.SendSmsParams{
+		RegionID:      req.RegionId,
+		PhoneNumbers:  strings.Join(req.PhoneNumbers, ","),
+		SignName:      req.SignName,
+		TemplateCode:  req.TemplateCode,
+		TemplateParam: sms.TemplateParam(req.TemplateParam),
+		OutID:         req.OutId,
+	}).Do(s.options(ctx)...)
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+	res := opts.Response()
+	if err := res.Err(); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	out := &smspb.SendSmsResponse{BizId: res.BizID, RequestId: res.RequestID}
+	for _, n := range opts.Suppressed() {
+		out.Suppressed = append(out.Suppressed, n.String())
+	}
+	return out, nil
+}
+
+// SendBatchSms implements smspb.SmsServer
+func (s *Server) SendBatchSms(ctx context.Context, req *smspb.SendBatchSmsRequest) (*smspb.SendBatchSmsResponse, error) {
+	params := sms.SendBatchSmsParams{
+		RegionID:     req.RegionId,
+		TemplateCode: req.TemplateCode,
+		Messages:     make([]sms.BatchMessage, len(req.Messages)),
+	}
+	for i, m := range req.Messages {
+		params.Messages[i] = sms.BatchMessage{
+			PhoneNumber:   m.PhoneNumber,
+			SignName:      m.SignName,
+			TemplateParam: sms.TemplateParam(m.TemplateParam),
+		}
+	}
+	opts, err := sms.NewSendBatchSmsAction(s.conf.Client, params).Do(s.options(ctx)...)
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+	res := opts.Response()
+	if err := res.Err(); err != nil {
+		return nil, Status(err).Err()
+	}
+	out := &smspb.SendBatchSmsResponse{BizId: res.BizID, RequestId: res.RequestID}
+	for _, n := range opts.Suppressed() {
+		out.Suppressed = append(out.Suppressed, n.String())
+	}
+	return out, nil
+}
+
+// QuerySendDetails implements smspb.SmsServer
+func (s *Server) QuerySendDetails(ctx context.Context, req *smspb.QuerySendDetailsRequest) (*smspb.QuerySendDetailsResponse, error) {
+	sendDate, err := parseDate("SendDate", req.SendDate)
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+	opts, err := sms.NewQuerySendDetailsAction(s.conf.Client, sms.QuerySendDetailsParams{
+		PhoneNumber: req.PhoneNumber,
+		BizID:       req.BizId,
+		SendDate:    sendDate,
+		PageSize:    int(req.PageSize),
+		CurrentPage: int(req.CurrentPage),
+		RegionID:    req.RegionId,
+	}).Do(s.options(ctx)...)
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+	res := opts.Response()
+	if err := res.Err(); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	out := &smspb.QuerySendDetailsResponse{TotalCount: int32(res.TotalCount), RequestId: res.RequestID}
+	for _, d := range res.SmsSendDetailDTOs.SmsSendDetailDTO {
+		out.Details = append(out.Details, sendDetail(d))
+	}
+	return out, nil
+}
+
+// StreamDeliveryReports implements smspb.SmsServer
+// reports are sent in the order sms reach a final state
+func (s *Server) StreamDeliveryReports(req *smspb.StreamDeliveryReportsRequest, stream smspb.Sms_StreamDeliveryReportsServer) error {
+	var errs sms.ValidationError
+	if len(req.Sms) == 0 {
+		errs = append(errs, &sms.FieldError{Field: "Sms", Err: sms.ErrRequired})
+	} else if len(req.Sms) > s.conf.MaxTracked {
+		errs = append(errs, &sms.FieldError{Field: "Sms", Err: fmt.Errorf("%d sms, upper limit is %d", len(req.Sms), s.conf.MaxTracked)})
+	}
+	tracked := make([]sms.TrackRequest, len(req.Sms))
+	for i, t := range req.Sms {
+		field := fmt.Sprintf("Sms[%d].", i)
+		if t.PhoneNumber == "" {
+			errs = append(errs, &sms.FieldError{Field: field + "PhoneNumber", Err: sms.ErrRequired})
+		}
+		if t.BizId == "" && t.OutId == "" {
+			errs = append(errs, &sms.FieldError{Field: field + "BizID", Err: fmt.Errorf("either BizID or OutID is required")})
+		}
+		sendDate, err := parseDate(field+"SendDate", t.SendDate)
+		if t.SendDate == "" {
+			errs = append(errs, &sms.FieldError{Field: field + "SendDate", Err: sms.ErrRequired})
+		} else if err != nil {
+			errs = append(errs, err.(sms.ValidationError)...)
+		}
+		tracked[i] = sms.TrackRequest{
+			BizID:        t.BizId,
+			PhoneNumber:  t.PhoneNumber,
+			SendDate:     sendDate,
+			OutID:        t.OutId,
+			TemplateCode: t.TemplateCode,
+		}
+	}
+	if len(errs) > 0 {
+		return Status(errs).Err()
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	results := make(chan *smspb.DeliveryReport, len(tracked))
+	for i, t := range tracked {
+		t, sent := t, req.Sms[i]
+		s.conf.Tracker.Watch(ctx, t, func(res sms.TrackResult) {
+			report := &smspb.DeliveryReport{Sms: sent}
+			if res.Detail != nil {
+				report.Detail = sendDetail(*res.Detail)
+			}
+			if res.Err != nil {
+				report.Error = res.Err.Error()
+			}
+			results <- report
+		})
+	}
+
+	for range tracked {
+		select {
+		case report := <-results:
+			if err := stream.Send(report); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return nil
+}
+
+// parseDate parses a date of format "20060102", empty value is zero Date
+func parseDate(field, value string) (sms.Date, error) {
+	if value == "" {
+		return sms.Date{}, nil
+	}
+	d, err := time.Parse("20060102", value)
+	if err != nil {
+		return sms.Date{}, sms.ValidationError{{Field: field, Err: fmt.Errorf("malformed %q, expected format is 20060102", value)}}
+	}
+	return sms.Date(d), nil
+}
+
+func sendDetail(d sms.SendDetailDTO) *smspb.SendDetail {
+	detail := &smspb.SendDetail{
+		PhoneNumber:  d.PhoneNum,
+		Status:       smspb.SendStatus(d.SendStatus),
+		ErrCode:      d.ErrCode,
+		TemplateCode: d.TemplateCode,
+		Content:      d.Content,
+		OutId:        d.OutID,
+	}
+	if t, err := d.SendTime(); err == nil && !t.IsZero() {
+		detail.SendTime = timestamppb.New(t)
+	}
+	if t, err := d.ReceiveTime(); err == nil && !t.IsZero() {
+		detail.ReceiveTime = timestamppb.New(t)
+	}
+	return detail
+}
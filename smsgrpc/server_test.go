@@ -0,0 +1,319 @@
+//go:build grpc
+// +build grpc
+
+package smsgrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/scistack/aliyun-sms-go/phone"
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smsgrpc/smspb"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+// testServer serves a Server backed by a fake of aliyun sms api over bufconn
+type testServer struct {
+	fake   *smstest.Server
+	client smspb.SmsClient
+	close  func()
+}
+
+func newTestServer(t *testing.T, fakeConf smstest.Config) *testServer {
+	fake := smstest.NewServer(fakeConf)
+	c := sms.NewClient(sms.Config{
+		AccessKeyID:  smstest.DefaultAccessKeyID,
+		AccessSecret: smstest.DefaultAccessSecret,
+		Endpoint:     fake.Endpoint(),
+	})
+	tracker := sms.NewTracker(c, sms.TrackerConfig{
+		Interval:    20 * time.Millisecond,
+		MaxInterval: 20 * time.Millisecond,
+		Timeout:     time.Second,
+	})
+	s := NewServer(Config{Client: c, Tracker: tracker})
+
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	smspb.RegisterSmsServer(gs, s)
+	go gs.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testServer{
+		fake:   fake,
+		client: smspb.NewSmsClient(conn),
+		close: func() {
+			conn.Close()
+			gs.Stop()
+			tracker.Stop()
+			fake.Close()
+		},
+	}
+}
+
+func today() string {
+	return time.Now().In(sms.ChinaStandardTime).Format("20060102")
+}
+
+func TestServer_SendSms(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{})
+	defer ts.close()
+
+	res, err := ts.client.SendSms(context.Background(), &smspb.SendSmsRequest{
+		PhoneNumbers:  []string{"15300000001", "+86 15300000002"},
+		SignName:      "阿里云短信测试专用",
+		TemplateCode:  "SMS_71390007",
+		TemplateParam: map[string]string{"code": "1234"},
+		OutId:         "order-1",
+	})
+	if err != nil {
+		t.Fatalf("SendSms err: %v", err)
+	}
+	messages := ts.fake.Messages()
+	if len(messages) != 2 || messages[1].PhoneNumber != "15300000002" || messages[0].BizID != res.BizId ||
+		messages[0].TemplateParam["code"] != "1234" || messages[0].OutID != "order-1" {
+		t.Errorf("Messages: %+v %+v", messages, res)
+	}
+}
+
+func TestServer_SendSmsInvalid(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{})
+	defer ts.close()
+
+	_, err := ts.client.SendSms(context.Background(), &smspb.SendSmsRequest{
+		PhoneNumbers: []string{"123"},
+		TemplateCode: "SMS_71390007",
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("SendSms err: %v", err)
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	if len(fields) != 2 || fields[0] != "PhoneNumbers" || fields[1] != "SignName" {
+		t.Errorf("BadRequest fields: %v", fields)
+	}
+	if len(ts.fake.Messages()) != 0 {
+		t.Errorf("invalid params are sent")
+	}
+}
+
+func TestServer_SendSmsThrottled(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{})
+	defer ts.close()
+
+	req := &smspb.SendSmsRequest{
+		PhoneNumbers: []string{"15300000001"},
+		SignName:     "阿里云短信测试专用",
+		TemplateCode: "SMS_71390007",
+	}
+	if _, err := ts.client.SendSms(context.Background(), req); err != nil {
+		t.Fatalf("SendSms err: %v", err)
+	}
+	_, err := ts.client.SendSms(context.Background(), req)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("SendSms err: %v", err)
+	}
+
+	var info *errdetails.ErrorInfo
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	if info == nil || info.Reason != "isv.BUSINESS_LIMIT_CONTROL" || info.Domain != ErrorDomain ||
+		info.Metadata["request_id"] == "" || info.Metadata["kind"] != "throttling" {
+		t.Errorf("ErrorInfo: %v", info)
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() != RetryDelay {
+		t.Errorf("RetryInfo: %v", retry)
+	}
+}
+
+func TestServer_SendBatchSms(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{})
+	defer ts.close()
+
+	res, err := ts.client.SendBatchSms(context.Background(), &smspb.SendBatchSmsRequest{
+		TemplateCode: "SMS_71390007",
+		Messages: []*smspb.BatchMessage{
+			{PhoneNumber: "15300000001", SignName: "sign1", TemplateParam: map[string]string{"code": "1"}},
+			{PhoneNumber: "15300000002", SignName: "sign2", TemplateParam: map[string]string{"code": "2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendBatchSms err: %v", err)
+	}
+	messages := ts.fake.Messages()
+	if len(messages) != 2 || messages[1].SignName != "sign2" || messages[1].TemplateParam["code"] != "2" ||
+		messages[1].BizID != res.BizId {
+		t.Errorf("Messages: %+v %+v", messages, res)
+	}
+}
+
+func TestServer_SendBatchSmsSuppressed(t *testing.T) {
+	fake := smstest.NewServer(smstest.Config{})
+	defer fake.Close()
+	l := sms.NewSuppressionList(nil)
+	l.Add(phone.MustParse("15300000002"), sms.SuppressionScope{}, "manual")
+	s := NewServer(Config{Client: sms.NewClient(sms.Config{
+		AccessKeyID:  smstest.DefaultAccessKeyID,
+		AccessSecret: smstest.DefaultAccessSecret,
+		Endpoint:     fake.Endpoint(),
+		Suppression:  l,
+	})})
+	defer s.Stop()
+
+	res, err := s.SendBatchSms(context.Background(), &smspb.SendBatchSmsRequest{
+		TemplateCode: "SMS_71390007",
+		Messages: []*smspb.BatchMessage{
+			{PhoneNumber: "15300000001", SignName: "sign1"},
+			{PhoneNumber: "15300000002", SignName: "sign2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendBatchSms err: %v", err)
+	}
+	if messages := fake.Messages(); len(messages) != 1 || messages[0].PhoneNumber != "15300000001" ||
+		len(res.Suppressed) != 1 || res.Suppressed[0] != "15300000002" {
+		t.Errorf("Messages: %+v %+v", messages, res)
+	}
+}
+
+func TestServer_QuerySendDetails(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{})
+	defer ts.close()
+
+	sent, err := ts.client.SendSms(context.Background(), &smspb.SendSmsRequest{
+		PhoneNumbers: []string{"15300000001"},
+		SignName:     "阿里云短信测试专用",
+		TemplateCode: "SMS_71390007",
+	})
+	if err != nil {
+		t.Fatalf("SendSms err: %v", err)
+	}
+	res, err := ts.client.QuerySendDetails(context.Background(), &smspb.QuerySendDetailsRequest{
+		PhoneNumber: "15300000001",
+		BizId:       sent.BizId,
+		SendDate:    today(),
+	})
+	if err != nil {
+		t.Fatalf("QuerySendDetails err: %v", err)
+	}
+	if res.TotalCount != 1 || len(res.Details) != 1 {
+		t.Fatalf("QuerySendDetails: %v", res)
+	}
+	if d := res.Details[0]; d.Status != smspb.SendStatus_SEND_STATUS_DELIVERED || d.SendTime == nil || d.ReceiveTime == nil {
+		t.Errorf("SendDetail: %v", d)
+	}
+
+	_, err = ts.client.QuerySendDetails(context.Background(), &smspb.QuerySendDetailsRequest{
+		PhoneNumber: "15300000001",
+		SendDate:    "2018-05-02",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("QuerySendDetails err: %v", err)
+	}
+}
+
+func TestServer_StreamDeliveryReports(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{
+		Delivery: func(m smstest.Message) smstest.Outcome {
+			if m.PhoneNumber == "15300000002" {
+				return smstest.Outcome{Status: smstest.Failed, ErrCode: "MOBILE_NOT_ON_SERVICE", Delay: 50 * time.Millisecond}
+			}
+			return smstest.Outcome{Delay: 200 * time.Millisecond}
+		},
+	})
+	defer ts.close()
+
+	sent, err := ts.client.SendSms(context.Background(), &smspb.SendSmsRequest{
+		PhoneNumbers: []string{"15300000001", "15300000002"},
+		SignName:     "阿里云短信测试专用",
+		TemplateCode: "SMS_71390007",
+	})
+	if err != nil {
+		t.Fatalf("SendSms err: %v", err)
+	}
+
+	stream, err := ts.client.StreamDeliveryReports(context.Background(), &smspb.StreamDeliveryReportsRequest{
+		Sms: []*smspb.TrackedSms{
+			{BizId: sent.BizId, PhoneNumber: "15300000001", SendDate: today()},
+			{BizId: sent.BizId, PhoneNumber: "15300000002", SendDate: today()},
+			{BizId: "0^0", PhoneNumber: "15300000003", SendDate: today()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StreamDeliveryReports err: %v", err)
+	}
+	var reports []*smspb.DeliveryReport
+	for {
+		report, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv err: %v", err)
+		}
+		reports = append(reports, report)
+	}
+
+	// reports are streamed in the order sms reach a final state, the unknown sms times out at last
+	if len(reports) != 3 {
+		t.Fatalf("reports: %v", reports)
+	}
+	if r := reports[0]; r.Sms.PhoneNumber != "15300000002" || r.Detail.GetStatus() != smspb.SendStatus_SEND_STATUS_FAILED ||
+		r.Detail.GetErrCode() != "MOBILE_NOT_ON_SERVICE" || r.Error != "" {
+		t.Errorf("report 0: %v", r)
+	}
+	if r := reports[1]; r.Sms.PhoneNumber != "15300000001" || r.Detail.GetStatus() != smspb.SendStatus_SEND_STATUS_DELIVERED {
+		t.Errorf("report 1: %v", r)
+	}
+	if r := reports[2]; r.Sms.PhoneNumber != "15300000003" || r.Detail != nil || r.Error != sms.ErrTrackTimeout.Error() {
+		t.Errorf("report 2: %v", r)
+	}
+}
+
+func TestServer_StreamDeliveryReportsInvalid(t *testing.T) {
+	ts := newTestServer(t, smstest.Config{})
+	defer ts.close()
+
+	stream, err := ts.client.StreamDeliveryReports(context.Background(), &smspb.StreamDeliveryReportsRequest{
+		Sms: []*smspb.TrackedSms{{PhoneNumber: "15300000001", SendDate: "2018-05-02"}},
+	})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StreamDeliveryReports err: %v", err)
+	}
+}
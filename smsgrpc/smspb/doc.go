@@ -0,0 +1,10 @@
+// Package smspb has the protobuf messages and grpc stubs of service "aliyun.sms.v1.Sms" of sms.proto
+//
+// the generated files are built with tag "grpc", google.golang.org/grpc
+// and google.golang.org/protobuf are required to build them
+//
+// they are generated from sms.proto by buf generate of buf.gen.yaml, don't edit them
+package smspb
+
+//go:generate buf generate
+//go:generate sed -i "1s;^;//go:build grpc\\n// +build grpc\\n\\n;" sms.pb.go sms_grpc.pb.go
@@ -0,0 +1,1008 @@
+//go:build grpc
+// +build grpc
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: sms.proto
+
+package smspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SendStatus int32
+
+const (
+	SendStatus_SEND_STATUS_UNSPECIFIED SendStatus = 0
+	SendStatus_SEND_STATUS_WAITING     SendStatus = 1
+	SendStatus_SEND_STATUS_FAILED      SendStatus = 2
+	SendStatus_SEND_STATUS_DELIVERED   SendStatus = 3
+)
+
+// Enum value maps for SendStatus.
+var (
+	SendStatus_name = map[int32]string{
+		0: "SEND_STATUS_UNSPECIFIED",
+		1: "SEND_STATUS_WAITING",
+		2: "SEND_STATUS_FAILED",
+		3: "SEND_STATUS_DELIVERED",
+	}
+	SendStatus_value = map[string]int32{
+		"SEND_STATUS_UNSPECIFIED": 0,
+		"SEND_STATUS_WAITING":     1,
+		"SEND_STATUS_FAILED":      2,
+		"SEND_STATUS_DELIVERED":   3,
+	}
+)
+
+func (x SendStatus) Enum() *SendStatus {
+	p := new(SendStatus)
+	*p = x
+	return p
+}
+
+func (x SendStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SendStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_sms_proto_enumTypes[0].Descriptor()
+}
+
+func (SendStatus) Type() protoreflect.EnumType {
+	return &file_sms_proto_enumTypes[0]
+}
+
+func (x SendStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SendStatus.Descriptor instead.
+func (SendStatus) EnumDescriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{0}
+}
+
+type SendSmsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PhoneNumbers  []string               `protobuf:"bytes,1,rep,name=phone_numbers,json=phoneNumbers,proto3" json:"phone_numbers,omitempty"`
+	SignName      string                 `protobuf:"bytes,2,opt,name=sign_name,json=signName,proto3" json:"sign_name,omitempty"`
+	TemplateCode  string                 `protobuf:"bytes,3,opt,name=template_code,json=templateCode,proto3" json:"template_code,omitempty"`
+	TemplateParam map[string]string      `protobuf:"bytes,4,rep,name=template_param,json=templateParam,proto3" json:"template_param,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	OutId         string                 `protobuf:"bytes,5,opt,name=out_id,json=outId,proto3" json:"out_id,omitempty"`
+	RegionId      string                 `protobuf:"bytes,6,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendSmsRequest) Reset() {
+	*x = SendSmsRequest{}
+	mi := &file_sms_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendSmsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendSmsRequest) ProtoMessage() {}
+
+func (x *SendSmsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendSmsRequest.ProtoReflect.Descriptor instead.
+func (*SendSmsRequest) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SendSmsRequest) GetPhoneNumbers() []string {
+	if x != nil {
+		return x.PhoneNumbers
+	}
+	return nil
+}
+
+func (x *SendSmsRequest) GetSignName() string {
+	if x != nil {
+		return x.SignName
+	}
+	return ""
+}
+
+func (x *SendSmsRequest) GetTemplateCode() string {
+	if x != nil {
+		return x.TemplateCode
+	}
+	return ""
+}
+
+func (x *SendSmsRequest) GetTemplateParam() map[string]string {
+	if x != nil {
+		return x.TemplateParam
+	}
+	return nil
+}
+
+func (x *SendSmsRequest) GetOutId() string {
+	if x != nil {
+		return x.OutId
+	}
+	return ""
+}
+
+func (x *SendSmsRequest) GetRegionId() string {
+	if x != nil {
+		return x.RegionId
+	}
+	return ""
+}
+
+type SendSmsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BizId     string                 `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	RequestId string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Phone numbers filtered out by the suppression list of the server.
+	Suppressed    []string `protobuf:"bytes,3,rep,name=suppressed,proto3" json:"suppressed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendSmsResponse) Reset() {
+	*x = SendSmsResponse{}
+	mi := &file_sms_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendSmsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendSmsResponse) ProtoMessage() {}
+
+func (x *SendSmsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendSmsResponse.ProtoReflect.Descriptor instead.
+func (*SendSmsResponse) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SendSmsResponse) GetBizId() string {
+	if x != nil {
+		return x.BizId
+	}
+	return ""
+}
+
+func (x *SendSmsResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SendSmsResponse) GetSuppressed() []string {
+	if x != nil {
+		return x.Suppressed
+	}
+	return nil
+}
+
+type BatchMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PhoneNumber   string                 `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber,proto3" json:"phone_number,omitempty"`
+	SignName      string                 `protobuf:"bytes,2,opt,name=sign_name,json=signName,proto3" json:"sign_name,omitempty"`
+	TemplateParam map[string]string      `protobuf:"bytes,3,rep,name=template_param,json=templateParam,proto3" json:"template_param,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchMessage) Reset() {
+	*x = BatchMessage{}
+	mi := &file_sms_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchMessage) ProtoMessage() {}
+
+func (x *BatchMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchMessage.ProtoReflect.Descriptor instead.
+func (*BatchMessage) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchMessage) GetPhoneNumber() string {
+	if x != nil {
+		return x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *BatchMessage) GetSignName() string {
+	if x != nil {
+		return x.SignName
+	}
+	return ""
+}
+
+func (x *BatchMessage) GetTemplateParam() map[string]string {
+	if x != nil {
+		return x.TemplateParam
+	}
+	return nil
+}
+
+type SendBatchSmsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TemplateCode  string                 `protobuf:"bytes,1,opt,name=template_code,json=templateCode,proto3" json:"template_code,omitempty"`
+	Messages      []*BatchMessage        `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
+	RegionId      string                 `protobuf:"bytes,3,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendBatchSmsRequest) Reset() {
+	*x = SendBatchSmsRequest{}
+	mi := &file_sms_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendBatchSmsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendBatchSmsRequest) ProtoMessage() {}
+
+func (x *SendBatchSmsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendBatchSmsRequest.ProtoReflect.Descriptor instead.
+func (*SendBatchSmsRequest) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SendBatchSmsRequest) GetTemplateCode() string {
+	if x != nil {
+		return x.TemplateCode
+	}
+	return ""
+}
+
+func (x *SendBatchSmsRequest) GetMessages() []*BatchMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *SendBatchSmsRequest) GetRegionId() string {
+	if x != nil {
+		return x.RegionId
+	}
+	return ""
+}
+
+type SendBatchSmsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BizId     string                 `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	RequestId string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Phone numbers whose messages are filtered out by the suppression list of the server.
+	Suppressed    []string `protobuf:"bytes,3,rep,name=suppressed,proto3" json:"suppressed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendBatchSmsResponse) Reset() {
+	*x = SendBatchSmsResponse{}
+	mi := &file_sms_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendBatchSmsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendBatchSmsResponse) ProtoMessage() {}
+
+func (x *SendBatchSmsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendBatchSmsResponse.ProtoReflect.Descriptor instead.
+func (*SendBatchSmsResponse) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SendBatchSmsResponse) GetBizId() string {
+	if x != nil {
+		return x.BizId
+	}
+	return ""
+}
+
+func (x *SendBatchSmsResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SendBatchSmsResponse) GetSuppressed() []string {
+	if x != nil {
+		return x.Suppressed
+	}
+	return nil
+}
+
+type QuerySendDetailsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	PhoneNumber string                 `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber,proto3" json:"phone_number,omitempty"`
+	BizId       string                 `protobuf:"bytes,2,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	// Date of sending in format yyyyMMdd of China Standard Time.
+	SendDate string `protobuf:"bytes,3,opt,name=send_date,json=sendDate,proto3" json:"send_date,omitempty"`
+	// Default and upper limit is 50.
+	PageSize int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Default is 1.
+	CurrentPage   int32  `protobuf:"varint,5,opt,name=current_page,json=currentPage,proto3" json:"current_page,omitempty"`
+	RegionId      string `protobuf:"bytes,6,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuerySendDetailsRequest) Reset() {
+	*x = QuerySendDetailsRequest{}
+	mi := &file_sms_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuerySendDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuerySendDetailsRequest) ProtoMessage() {}
+
+func (x *QuerySendDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuerySendDetailsRequest.ProtoReflect.Descriptor instead.
+func (*QuerySendDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *QuerySendDetailsRequest) GetPhoneNumber() string {
+	if x != nil {
+		return x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *QuerySendDetailsRequest) GetBizId() string {
+	if x != nil {
+		return x.BizId
+	}
+	return ""
+}
+
+func (x *QuerySendDetailsRequest) GetSendDate() string {
+	if x != nil {
+		return x.SendDate
+	}
+	return ""
+}
+
+func (x *QuerySendDetailsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *QuerySendDetailsRequest) GetCurrentPage() int32 {
+	if x != nil {
+		return x.CurrentPage
+	}
+	return 0
+}
+
+func (x *QuerySendDetailsRequest) GetRegionId() string {
+	if x != nil {
+		return x.RegionId
+	}
+	return ""
+}
+
+type SendDetail struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	PhoneNumber  string                 `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber,proto3" json:"phone_number,omitempty"`
+	Status       SendStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=aliyun.sms.v1.SendStatus" json:"status,omitempty"`
+	ErrCode      string                 `protobuf:"bytes,3,opt,name=err_code,json=errCode,proto3" json:"err_code,omitempty"`
+	TemplateCode string                 `protobuf:"bytes,4,opt,name=template_code,json=templateCode,proto3" json:"template_code,omitempty"`
+	Content      string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	SendTime     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=send_time,json=sendTime,proto3" json:"send_time,omitempty"`
+	// Unset if the receipt from the carrier has not arrived.
+	ReceiveTime   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=receive_time,json=receiveTime,proto3" json:"receive_time,omitempty"`
+	OutId         string                 `protobuf:"bytes,8,opt,name=out_id,json=outId,proto3" json:"out_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendDetail) Reset() {
+	*x = SendDetail{}
+	mi := &file_sms_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendDetail) ProtoMessage() {}
+
+func (x *SendDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendDetail.ProtoReflect.Descriptor instead.
+func (*SendDetail) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SendDetail) GetPhoneNumber() string {
+	if x != nil {
+		return x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *SendDetail) GetStatus() SendStatus {
+	if x != nil {
+		return x.Status
+	}
+	return SendStatus_SEND_STATUS_UNSPECIFIED
+}
+
+func (x *SendDetail) GetErrCode() string {
+	if x != nil {
+		return x.ErrCode
+	}
+	return ""
+}
+
+func (x *SendDetail) GetTemplateCode() string {
+	if x != nil {
+		return x.TemplateCode
+	}
+	return ""
+}
+
+func (x *SendDetail) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SendDetail) GetSendTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SendTime
+	}
+	return nil
+}
+
+func (x *SendDetail) GetReceiveTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReceiveTime
+	}
+	return nil
+}
+
+func (x *SendDetail) GetOutId() string {
+	if x != nil {
+		return x.OutId
+	}
+	return ""
+}
+
+type QuerySendDetailsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalCount    int32                  `protobuf:"varint,1,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Details       []*SendDetail          `protobuf:"bytes,2,rep,name=details,proto3" json:"details,omitempty"`
+	RequestId     string                 `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuerySendDetailsResponse) Reset() {
+	*x = QuerySendDetailsResponse{}
+	mi := &file_sms_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuerySendDetailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuerySendDetailsResponse) ProtoMessage() {}
+
+func (x *QuerySendDetailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuerySendDetailsResponse.ProtoReflect.Descriptor instead.
+func (*QuerySendDetailsResponse) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *QuerySendDetailsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *QuerySendDetailsResponse) GetDetails() []*SendDetail {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *QuerySendDetailsResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// TrackedSms identifies a sent sms.
+type TrackedSms struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BizId       string                 `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	PhoneNumber string                 `protobuf:"bytes,2,opt,name=phone_number,json=phoneNumber,proto3" json:"phone_number,omitempty"`
+	// Date of sending in format yyyyMMdd of China Standard Time.
+	SendDate string `protobuf:"bytes,3,opt,name=send_date,json=sendDate,proto3" json:"send_date,omitempty"`
+	// Optional, sms of the same phone number and date are matched in one query
+	// by out_id and template_code instead of biz_id.
+	OutId         string `protobuf:"bytes,4,opt,name=out_id,json=outId,proto3" json:"out_id,omitempty"`
+	TemplateCode  string `protobuf:"bytes,5,opt,name=template_code,json=templateCode,proto3" json:"template_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackedSms) Reset() {
+	*x = TrackedSms{}
+	mi := &file_sms_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackedSms) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackedSms) ProtoMessage() {}
+
+func (x *TrackedSms) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackedSms.ProtoReflect.Descriptor instead.
+func (*TrackedSms) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TrackedSms) GetBizId() string {
+	if x != nil {
+		return x.BizId
+	}
+	return ""
+}
+
+func (x *TrackedSms) GetPhoneNumber() string {
+	if x != nil {
+		return x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *TrackedSms) GetSendDate() string {
+	if x != nil {
+		return x.SendDate
+	}
+	return ""
+}
+
+func (x *TrackedSms) GetOutId() string {
+	if x != nil {
+		return x.OutId
+	}
+	return ""
+}
+
+func (x *TrackedSms) GetTemplateCode() string {
+	if x != nil {
+		return x.TemplateCode
+	}
+	return ""
+}
+
+type StreamDeliveryReportsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sms           []*TrackedSms          `protobuf:"bytes,1,rep,name=sms,proto3" json:"sms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamDeliveryReportsRequest) Reset() {
+	*x = StreamDeliveryReportsRequest{}
+	mi := &file_sms_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamDeliveryReportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamDeliveryReportsRequest) ProtoMessage() {}
+
+func (x *StreamDeliveryReportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamDeliveryReportsRequest.ProtoReflect.Descriptor instead.
+func (*StreamDeliveryReportsRequest) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamDeliveryReportsRequest) GetSms() []*TrackedSms {
+	if x != nil {
+		return x.Sms
+	}
+	return nil
+}
+
+type DeliveryReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Sms   *TrackedSms            `protobuf:"bytes,1,opt,name=sms,proto3" json:"sms,omitempty"`
+	// The last detail found, unset if the sms is not found.
+	Detail *SendDetail `protobuf:"bytes,2,opt,name=detail,proto3" json:"detail,omitempty"`
+	// Set if the sms does not reach a final state, e.g. the tracking times out.
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryReport) Reset() {
+	*x = DeliveryReport{}
+	mi := &file_sms_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryReport) ProtoMessage() {}
+
+func (x *DeliveryReport) ProtoReflect() protoreflect.Message {
+	mi := &file_sms_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryReport.ProtoReflect.Descriptor instead.
+func (*DeliveryReport) Descriptor() ([]byte, []int) {
+	return file_sms_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeliveryReport) GetSms() *TrackedSms {
+	if x != nil {
+		return x.Sms
+	}
+	return nil
+}
+
+func (x *DeliveryReport) GetDetail() *SendDetail {
+	if x != nil {
+		return x.Detail
+	}
+	return nil
+}
+
+func (x *DeliveryReport) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_sms_proto protoreflect.FileDescriptor
+
+const file_sms_proto_rawDesc = "" +
+	"\n" +
+	"\tsms.proto\x12\raliyun.sms.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc6\x02\n" +
+	"\x0eSendSmsRequest\x12#\n" +
+	"\rphone_numbers\x18\x01 \x03(\tR\fphoneNumbers\x12\x1b\n" +
+	"\tsign_name\x18\x02 \x01(\tR\bsignName\x12#\n" +
+	"\rtemplate_code\x18\x03 \x01(\tR\ftemplateCode\x12W\n" +
+	"\x0etemplate_param\x18\x04 \x03(\v20.aliyun.sms.v1.SendSmsRequest.TemplateParamEntryR\rtemplateParam\x12\x15\n" +
+	"\x06out_id\x18\x05 \x01(\tR\x05outId\x12\x1b\n" +
+	"\tregion_id\x18\x06 \x01(\tR\bregionId\x1a@\n" +
+	"\x12TemplateParamEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"g\n" +
+	"\x0fSendSmsResponse\x12\x15\n" +
+	"\x06biz_id\x18\x01 \x01(\tR\x05bizId\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12\x1e\n" +
+	"\n" +
+	"suppressed\x18\x03 \x03(\tR\n" +
+	"suppressed\"\xe7\x01\n" +
+	"\fBatchMessage\x12!\n" +
+	"\fphone_number\x18\x01 \x01(\tR\vphoneNumber\x12\x1b\n" +
+	"\tsign_name\x18\x02 \x01(\tR\bsignName\x12U\n" +
+	"\x0etemplate_param\x18\x03 \x03(\v2..aliyun.sms.v1.BatchMessage.TemplateParamEntryR\rtemplateParam\x1a@\n" +
+	"\x12TemplateParamEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x90\x01\n" +
+	"\x13SendBatchSmsRequest\x12#\n" +
+	"\rtemplate_code\x18\x01 \x01(\tR\ftemplateCode\x127\n" +
+	"\bmessages\x18\x02 \x03(\v2\x1b.aliyun.sms.v1.BatchMessageR\bmessages\x12\x1b\n" +
+	"\tregion_id\x18\x03 \x01(\tR\bregionId\"l\n" +
+	"\x14SendBatchSmsResponse\x12\x15\n" +
+	"\x06biz_id\x18\x01 \x01(\tR\x05bizId\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12\x1e\n" +
+	"\n" +
+	"suppressed\x18\x03 \x03(\tR\n" +
+	"suppressed\"\xcd\x01\n" +
+	"\x17QuerySendDetailsRequest\x12!\n" +
+	"\fphone_number\x18\x01 \x01(\tR\vphoneNumber\x12\x15\n" +
+	"\x06biz_id\x18\x02 \x01(\tR\x05bizId\x12\x1b\n" +
+	"\tsend_date\x18\x03 \x01(\tR\bsendDate\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12!\n" +
+	"\fcurrent_page\x18\x05 \x01(\x05R\vcurrentPage\x12\x1b\n" +
+	"\tregion_id\x18\x06 \x01(\tR\bregionId\"\xcb\x02\n" +
+	"\n" +
+	"SendDetail\x12!\n" +
+	"\fphone_number\x18\x01 \x01(\tR\vphoneNumber\x121\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x19.aliyun.sms.v1.SendStatusR\x06status\x12\x19\n" +
+	"\berr_code\x18\x03 \x01(\tR\aerrCode\x12#\n" +
+	"\rtemplate_code\x18\x04 \x01(\tR\ftemplateCode\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x127\n" +
+	"\tsend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bsendTime\x12=\n" +
+	"\freceive_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vreceiveTime\x12\x15\n" +
+	"\x06out_id\x18\b \x01(\tR\x05outId\"\x8f\x01\n" +
+	"\x18QuerySendDetailsResponse\x12\x1f\n" +
+	"\vtotal_count\x18\x01 \x01(\x05R\n" +
+	"totalCount\x123\n" +
+	"\adetails\x18\x02 \x03(\v2\x19.aliyun.sms.v1.SendDetailR\adetails\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\"\x9f\x01\n" +
+	"\n" +
+	"TrackedSms\x12\x15\n" +
+	"\x06biz_id\x18\x01 \x01(\tR\x05bizId\x12!\n" +
+	"\fphone_number\x18\x02 \x01(\tR\vphoneNumber\x12\x1b\n" +
+	"\tsend_date\x18\x03 \x01(\tR\bsendDate\x12\x15\n" +
+	"\x06out_id\x18\x04 \x01(\tR\x05outId\x12#\n" +
+	"\rtemplate_code\x18\x05 \x01(\tR\ftemplateCode\"K\n" +
+	"\x1cStreamDeliveryReportsRequest\x12+\n" +
+	"\x03sms\x18\x01 \x03(\v2\x19.aliyun.sms.v1.TrackedSmsR\x03sms\"\x86\x01\n" +
+	"\x0eDeliveryReport\x12+\n" +
+	"\x03sms\x18\x01 \x01(\v2\x19.aliyun.sms.v1.TrackedSmsR\x03sms\x121\n" +
+	"\x06detail\x18\x02 \x01(\v2\x19.aliyun.sms.v1.SendDetailR\x06detail\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error*u\n" +
+	"\n" +
+	"SendStatus\x12\x1b\n" +
+	"\x17SEND_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13SEND_STATUS_WAITING\x10\x01\x12\x16\n" +
+	"\x12SEND_STATUS_FAILED\x10\x02\x12\x19\n" +
+	"\x15SEND_STATUS_DELIVERED\x10\x032\xf4\x02\n" +
+	"\x03Sms\x12H\n" +
+	"\aSendSms\x12\x1d.aliyun.sms.v1.SendSmsRequest\x1a\x1e.aliyun.sms.v1.SendSmsResponse\x12W\n" +
+	"\fSendBatchSms\x12\".aliyun.sms.v1.SendBatchSmsRequest\x1a#.aliyun.sms.v1.SendBatchSmsResponse\x12c\n" +
+	"\x10QuerySendDetails\x12&.aliyun.sms.v1.QuerySendDetailsRequest\x1a'.aliyun.sms.v1.QuerySendDetailsResponse\x12e\n" +
+	"\x15StreamDeliveryReports\x12+.aliyun.sms.v1.StreamDeliveryReportsRequest\x1a\x1d.aliyun.sms.v1.DeliveryReport0\x01B1Z/github.com/scistack/aliyun-sms-go/smsgrpc/smspbb\x06proto3"
+
+var (
+	file_sms_proto_rawDescOnce sync.Once
+	file_sms_proto_rawDescData []byte
+)
+
+func file_sms_proto_rawDescGZIP() []byte {
+	file_sms_proto_rawDescOnce.Do(func() {
+		file_sms_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sms_proto_rawDesc), len(file_sms_proto_rawDesc)))
+	})
+	return file_sms_proto_rawDescData
+}
+
+var file_sms_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sms_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_sms_proto_goTypes = []any{
+	(SendStatus)(0),                      // 0: aliyun.sms.v1.SendStatus
+	(*SendSmsRequest)(nil),               // 1: aliyun.sms.v1.SendSmsRequest
+	(*SendSmsResponse)(nil),              // 2: aliyun.sms.v1.SendSmsResponse
+	(*BatchMessage)(nil),                 // 3: aliyun.sms.v1.BatchMessage
+	(*SendBatchSmsRequest)(nil),          // 4: aliyun.sms.v1.SendBatchSmsRequest
+	(*SendBatchSmsResponse)(nil),         // 5: aliyun.sms.v1.SendBatchSmsResponse
+	(*QuerySendDetailsRequest)(nil),      // 6: aliyun.sms.v1.QuerySendDetailsRequest
+	(*SendDetail)(nil),                   // 7: aliyun.sms.v1.SendDetail
+	(*QuerySendDetailsResponse)(nil),     // 8: aliyun.sms.v1.QuerySendDetailsResponse
+	(*TrackedSms)(nil),                   // 9: aliyun.sms.v1.TrackedSms
+	(*StreamDeliveryReportsRequest)(nil), // 10: aliyun.sms.v1.StreamDeliveryReportsRequest
+	(*DeliveryReport)(nil),               // 11: aliyun.sms.v1.DeliveryReport
+	nil,                                  // 12: aliyun.sms.v1.SendSmsRequest.TemplateParamEntry
+	nil,                                  // 13: aliyun.sms.v1.BatchMessage.TemplateParamEntry
+	(*timestamppb.Timestamp)(nil),        // 14: google.protobuf.Timestamp
+}
+var file_sms_proto_depIdxs = []int32{
+	12, // 0: aliyun.sms.v1.SendSmsRequest.template_param:type_name -> aliyun.sms.v1.SendSmsRequest.TemplateParamEntry
+	13, // 1: aliyun.sms.v1.BatchMessage.template_param:type_name -> aliyun.sms.v1.BatchMessage.TemplateParamEntry
+	3,  // 2: aliyun.sms.v1.SendBatchSmsRequest.messages:type_name -> aliyun.sms.v1.BatchMessage
+	0,  // 3: aliyun.sms.v1.SendDetail.status:type_name -> aliyun.sms.v1.SendStatus
+	14, // 4: aliyun.sms.v1.SendDetail.send_time:type_name -> google.protobuf.Timestamp
+	14, // 5: aliyun.sms.v1.SendDetail.receive_time:type_name -> google.protobuf.Timestamp
+	7,  // 6: aliyun.sms.v1.QuerySendDetailsResponse.details:type_name -> aliyun.sms.v1.SendDetail
+	9,  // 7: aliyun.sms.v1.StreamDeliveryReportsRequest.sms:type_name -> aliyun.sms.v1.TrackedSms
+	9,  // 8: aliyun.sms.v1.DeliveryReport.sms:type_name -> aliyun.sms.v1.TrackedSms
+	7,  // 9: aliyun.sms.v1.DeliveryReport.detail:type_name -> aliyun.sms.v1.SendDetail
+	1,  // 10: aliyun.sms.v1.Sms.SendSms:input_type -> aliyun.sms.v1.SendSmsRequest
+	4,  // 11: aliyun.sms.v1.Sms.SendBatchSms:input_type -> aliyun.sms.v1.SendBatchSmsRequest
+	6,  // 12: aliyun.sms.v1.Sms.QuerySendDetails:input_type -> aliyun.sms.v1.QuerySendDetailsRequest
+	10, // 13: aliyun.sms.v1.Sms.StreamDeliveryReports:input_type -> aliyun.sms.v1.StreamDeliveryReportsRequest
+	2,  // 14: aliyun.sms.v1.Sms.SendSms:output_type -> aliyun.sms.v1.SendSmsResponse
+	5,  // 15: aliyun.sms.v1.Sms.SendBatchSms:output_type -> aliyun.sms.v1.SendBatchSmsResponse
+	8,  // 16: aliyun.sms.v1.Sms.QuerySendDetails:output_type -> aliyun.sms.v1.QuerySendDetailsResponse
+	11, // 17: aliyun.sms.v1.Sms.StreamDeliveryReports:output_type -> aliyun.sms.v1.DeliveryReport
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_sms_proto_init() }
+func file_sms_proto_init() {
+	if File_sms_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sms_proto_rawDesc), len(file_sms_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sms_proto_goTypes,
+		DependencyIndexes: file_sms_proto_depIdxs,
+		EnumInfos:         file_sms_proto_enumTypes,
+		MessageInfos:      file_sms_proto_msgTypes,
+	}.Build()
+	File_sms_proto = out.File
+	file_sms_proto_goTypes = nil
+	file_sms_proto_depIdxs = nil
+}
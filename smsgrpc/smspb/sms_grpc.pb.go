@@ -0,0 +1,270 @@
+//go:build grpc
+// +build grpc
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: sms.proto
+
+package smspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Sms_SendSms_FullMethodName               = "/aliyun.sms.v1.Sms/SendSms"
+	Sms_SendBatchSms_FullMethodName          = "/aliyun.sms.v1.Sms/SendBatchSms"
+	Sms_QuerySendDetails_FullMethodName      = "/aliyun.sms.v1.Sms/QuerySendDetails"
+	Sms_StreamDeliveryReports_FullMethodName = "/aliyun.sms.v1.Sms/StreamDeliveryReports"
+)
+
+// SmsClient is the client API for Sms service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Sms sends sms and tracks their delivery by aliyun sms api.
+//
+// Failed calls have a status of a canonical code, the code of aliyun sms api,
+// if any, is the reason of the google.rpc.ErrorInfo detail of domain
+// "dysmsapi.aliyuncs.com". Invalid params have a google.rpc.BadRequest detail,
+// throttled calls have a google.rpc.RetryInfo detail.
+type SmsClient interface {
+	// SendSms sends a template to phone numbers.
+	SendSms(ctx context.Context, in *SendSmsRequest, opts ...grpc.CallOption) (*SendSmsResponse, error)
+	// SendBatchSms sends a template to phone numbers, every message has its own
+	// sign name and template param.
+	SendBatchSms(ctx context.Context, in *SendBatchSmsRequest, opts ...grpc.CallOption) (*SendBatchSmsResponse, error)
+	// QuerySendDetails queries sms sent to a phone number in a day.
+	QuerySendDetails(ctx context.Context, in *QuerySendDetailsRequest, opts ...grpc.CallOption) (*QuerySendDetailsResponse, error)
+	// StreamDeliveryReports tracks sent sms, a report is streamed once a sms
+	// reaches a final state or its tracking times out. The stream ends after
+	// the report of the last sms.
+	StreamDeliveryReports(ctx context.Context, in *StreamDeliveryReportsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeliveryReport], error)
+}
+
+type smsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSmsClient(cc grpc.ClientConnInterface) SmsClient {
+	return &smsClient{cc}
+}
+
+func (c *smsClient) SendSms(ctx context.Context, in *SendSmsRequest, opts ...grpc.CallOption) (*SendSmsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendSmsResponse)
+	err := c.cc.Invoke(ctx, Sms_SendSms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *smsClient) SendBatchSms(ctx context.Context, in *SendBatchSmsRequest, opts ...grpc.CallOption) (*SendBatchSmsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendBatchSmsResponse)
+	err := c.cc.Invoke(ctx, Sms_SendBatchSms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *smsClient) QuerySendDetails(ctx context.Context, in *QuerySendDetailsRequest, opts ...grpc.CallOption) (*QuerySendDetailsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuerySendDetailsResponse)
+	err := c.cc.Invoke(ctx, Sms_QuerySendDetails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *smsClient) StreamDeliveryReports(ctx context.Context, in *StreamDeliveryReportsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeliveryReport], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sms_ServiceDesc.Streams[0], Sms_StreamDeliveryReports_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamDeliveryReportsRequest, DeliveryReport]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sms_StreamDeliveryReportsClient = grpc.ServerStreamingClient[DeliveryReport]
+
+// SmsServer is the server API for Sms service.
+// All implementations must embed UnimplementedSmsServer
+// for forward compatibility.
+//
+// Sms sends sms and tracks their delivery by aliyun sms api.
+//
+// Failed calls have a status of a canonical code, the code of aliyun sms api,
+// if any, is the reason of the google.rpc.ErrorInfo detail of domain
+// "dysmsapi.aliyuncs.com". Invalid params have a google.rpc.BadRequest detail,
+// throttled calls have a google.rpc.RetryInfo detail.
+type SmsServer interface {
+	// SendSms sends a template to phone numbers.
+	SendSms(context.Context, *SendSmsRequest) (*SendSmsResponse, error)
+	// SendBatchSms sends a template to phone numbers, every message has its own
+	// sign name and template param.
+	SendBatchSms(context.Context, *SendBatchSmsRequest) (*SendBatchSmsResponse, error)
+	// QuerySendDetails queries sms sent to a phone number in a day.
+	QuerySendDetails(context.Context, *QuerySendDetailsRequest) (*QuerySendDetailsResponse, error)
+	// StreamDeliveryReports tracks sent sms, a report is streamed once a sms
+	// reaches a final state or its tracking times out. The stream ends after
+	// the report of the last sms.
+	StreamDeliveryReports(*StreamDeliveryReportsRequest, grpc.ServerStreamingServer[DeliveryReport]) error
+	mustEmbedUnimplementedSmsServer()
+}
+
+// UnimplementedSmsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSmsServer struct{}
+
+func (UnimplementedSmsServer) SendSms(context.Context, *SendSmsRequest) (*SendSmsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendSms not implemented")
+}
+func (UnimplementedSmsServer) SendBatchSms(context.Context, *SendBatchSmsRequest) (*SendBatchSmsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendBatchSms not implemented")
+}
+func (UnimplementedSmsServer) QuerySendDetails(context.Context, *QuerySendDetailsRequest) (*QuerySendDetailsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QuerySendDetails not implemented")
+}
+func (UnimplementedSmsServer) StreamDeliveryReports(*StreamDeliveryReportsRequest, grpc.ServerStreamingServer[DeliveryReport]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDeliveryReports not implemented")
+}
+func (UnimplementedSmsServer) mustEmbedUnimplementedSmsServer() {}
+func (UnimplementedSmsServer) testEmbeddedByValue()             {}
+
+// UnsafeSmsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SmsServer will
+// result in compilation errors.
+type UnsafeSmsServer interface {
+	mustEmbedUnimplementedSmsServer()
+}
+
+func RegisterSmsServer(s grpc.ServiceRegistrar, srv SmsServer) {
+	// If the following call pancis, it indicates UnimplementedSmsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Sms_ServiceDesc, srv)
+}
+
+func _Sms_SendSms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendSmsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SmsServer).SendSms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sms_SendSms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SmsServer).SendSms(ctx, req.(*SendSmsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sms_SendBatchSms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendBatchSmsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SmsServer).SendBatchSms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sms_SendBatchSms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SmsServer).SendBatchSms(ctx, req.(*SendBatchSmsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sms_QuerySendDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuerySendDetailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SmsServer).QuerySendDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sms_QuerySendDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SmsServer).QuerySendDetails(ctx, req.(*QuerySendDetailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sms_StreamDeliveryReports_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamDeliveryReportsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SmsServer).StreamDeliveryReports(m, &grpc.GenericServerStream[StreamDeliveryReportsRequest, DeliveryReport]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sms_StreamDeliveryReportsServer = grpc.ServerStreamingServer[DeliveryReport]
+
+// Sms_ServiceDesc is the grpc.ServiceDesc for Sms service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sms_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aliyun.sms.v1.Sms",
+	HandlerType: (*SmsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendSms",
+			Handler:    _Sms_SendSms_Handler,
+		},
+		{
+			MethodName: "SendBatchSms",
+			Handler:    _Sms_SendBatchSms_Handler,
+		},
+		{
+			MethodName: "QuerySendDetails",
+			Handler:    _Sms_QuerySendDetails_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDeliveryReports",
+			Handler:       _Sms_StreamDeliveryReports_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sms.proto",
+}
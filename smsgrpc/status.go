@@ -0,0 +1,106 @@
+//go:build grpc
+// +build grpc
+
+package smsgrpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+// ErrorDomain is Domain of errdetails.ErrorInfo of errs of aliyun sms api
+const ErrorDomain = "dysmsapi.aliyuncs.com"
+
+// RetryDelay is RetryDelay of errdetails.RetryInfo of throttled calls
+const RetryDelay = time.Minute
+
+// Code returns the grpc code of err of an action,
+// errs of the caller are InvalidArgument or FailedPrecondition,
+// errs of the server or aliyun sms api are Internal or Unavailable
+func Code(err error) codes.Code {
+	switch err := err.(type) {
+	case nil:
+		return codes.OK
+	case sms.ValidationError:
+		return codes.InvalidArgument
+	case *sms.SuppressedError:
+		return codes.FailedPrecondition
+	case *sms.Error:
+		switch err.Kind() {
+		case sms.KindThrottling:
+			return codes.ResourceExhausted
+		case sms.KindInvalidParams:
+			return codes.InvalidArgument
+		case sms.KindQuota, sms.KindSystem:
+			// the account of the server is out of balance, it's not a problem of the caller
+			return codes.Unavailable
+		}
+		// auth and unknown errs are problems of the server or aliyun sms api
+		return codes.Internal
+	case net.Error:
+		if err.Timeout() {
+			return codes.DeadlineExceeded
+		}
+	}
+	switch err {
+	case context.DeadlineExceeded:
+		return codes.DeadlineExceeded
+	case context.Canceled:
+		return codes.Canceled
+	}
+	return codes.Unavailable
+}
+
+// Status returns the status of err of an action with details,
+// Code of *sms.Error is Reason of errdetails.ErrorInfo,
+// fields of sms.ValidationError are errdetails.BadRequest,
+// throttled calls have errdetails.RetryInfo
+func Status(err error) *status.Status {
+	st := status.New(Code(err), err.Error())
+
+	var details []protoadapt.MessageV1
+	switch e := err.(type) {
+	case sms.ValidationError:
+		br := &errdetails.BadRequest{}
+		for _, fe := range e {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fe.Field,
+				Description: fe.Err.Error(),
+			})
+		}
+		details = append(details, br)
+	case *sms.SuppressedError:
+		info := &errdetails.ErrorInfo{Reason: "Suppressed", Domain: ErrorDomain}
+		details = append(details, info)
+	case *sms.Error:
+		info := &errdetails.ErrorInfo{
+			Reason: e.Code,
+			Domain: ErrorDomain,
+			Metadata: map[string]string{
+				"request_id": e.RequestID,
+				"message":    e.Message,
+				"kind":       e.Kind().String(),
+			},
+		}
+		details = append(details, info)
+		if e.Kind() == sms.KindThrottling {
+			details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(RetryDelay)})
+		}
+	}
+	if len(details) == 0 {
+		return st
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
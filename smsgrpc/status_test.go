@@ -0,0 +1,56 @@
+//go:build grpc
+// +build grpc
+
+package smsgrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"github.com/scistack/aliyun-sms-go/sms"
+)
+
+func TestCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{nil, codes.OK},
+		{sms.ValidationError{{Field: "SignName", Err: sms.ErrRequired}}, codes.InvalidArgument},
+		{&sms.SuppressedError{}, codes.FailedPrecondition},
+		{&sms.Error{Code: "isv.BUSINESS_LIMIT_CONTROL"}, codes.ResourceExhausted},
+		{&sms.Error{Code: "isv.MOBILE_NUMBER_ILLEGAL"}, codes.InvalidArgument},
+		{&sms.Error{Code: "isv.AMOUNT_NOT_ENOUGH"}, codes.Unavailable},
+		{&sms.Error{Code: "isp.SYSTEM_ERROR"}, codes.Unavailable},
+		{&sms.Error{Code: "SignatureDoesNotMatch"}, codes.Internal},
+		{&sms.Error{Code: "Unclassified"}, codes.Internal},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{errors.New("connection refused"), codes.Unavailable},
+	}
+	for _, cs := range cases {
+		if code := Code(cs.err); code != cs.code {
+			t.Errorf("Code(%v): %s != %s", cs.err, code, cs.code)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	st := Status(&sms.Error{RequestID: "1", Code: "isv.MOBILE_NUMBER_ILLEGAL", Message: "illegal"})
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("Status: %v %v", st, st.Details())
+	}
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	if !ok || info.Reason != "isv.MOBILE_NUMBER_ILLEGAL" || info.Metadata["message"] != "illegal" ||
+		info.Metadata["kind"] != "invalid-params" {
+		t.Errorf("ErrorInfo: %v", st.Details()[0])
+	}
+
+	if st := Status(errors.New("connection refused")); len(st.Details()) != 0 || st.Message() != "connection refused" {
+		t.Errorf("Status: %v", st)
+	}
+}
@@ -0,0 +1,553 @@
+// Package smstest is a fake of aliyun sms api (dysmsapi) for hermetic tests
+//
+// it implements actions "SendSms", "SendBatchSms" and "QuerySendDetails",
+// verifies signatures, nonces and timestamps of requests, enforces flow control
+// of phone numbers, and moves messages through a configurable delivery state machine
+package smstest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scistack/aliyun-sms-go/signer"
+)
+
+// SendStatus of a message, same as SendStatus of SmsSendDetailDTO
+type SendStatus int
+
+const (
+	// Waiting for the receipt of the carrier
+	Waiting SendStatus = 1
+
+	// Failed to deliver
+	Failed SendStatus = 2
+
+	// Delivered to the phone
+	Delivered SendStatus = 3
+)
+
+// Outcome of the delivery of a message
+type Outcome struct {
+	// Status after Delay, Delivered if it's 0
+	Status SendStatus
+
+	// ErrCode of the receipt, default "DELIVRD" for Delivered messages, as aliyun sms returns
+	ErrCode string
+
+	// Delay after which the message leaves Waiting
+	Delay time.Duration
+}
+
+// FlowControl is upper limit of messages to a phone number of a sign
+// a zero limit is not checked
+type FlowControl struct {
+	PerMinute int
+	PerHour   int
+	PerDay    int
+}
+
+// DefaultFlowControl is the default flow control of aliyun sms
+var DefaultFlowControl = FlowControl{PerMinute: 1, PerHour: 5, PerDay: 10}
+
+const (
+	// DefaultAccessKeyID of Config.Keys
+	DefaultAccessKeyID = "testId"
+
+	// DefaultAccessSecret of Config.Keys
+	DefaultAccessSecret = "testSecret"
+
+	// DefaultMaxClockSkew is default upper limit of the difference of Timestamp and now
+	DefaultMaxClockSkew = signer.DefaultMaxClockSkew
+)
+
+// Config of Fake
+type Config struct {
+	// Keys maps AccessKeyId to AccessSecret, default DefaultAccessKeyID to DefaultAccessSecret
+	Keys map[string]string
+
+	// MaxClockSkew is upper limit of the difference of Timestamp and now, default DefaultMaxClockSkew
+	MaxClockSkew time.Duration
+
+	// FlowControl of phone numbers, nil is DefaultFlowControl, use &FlowControl{} to disable it
+	FlowControl *FlowControl
+
+	// Templates maps TemplateCode to the content, "${name}" are replaced by TemplateParam,
+	// any template is accepted if it's nil
+	Templates map[string]string
+
+	// SignNames accepted, any sign is accepted if it's empty
+	SignNames []string
+
+	// Delivery decides the outcome of a message, all messages are delivered at once if it's nil
+	Delivery func(m Message) Outcome
+
+	// Now returns the current time, default time.Now
+	Now func() time.Time
+}
+
+// Message sent to a phone number
+type Message struct {
+	BizID         string
+	RequestID     string
+	AccessKeyID   string
+	PhoneNumber   string
+	SignName      string
+	TemplateCode  string
+	TemplateParam map[string]string
+	OutID         string
+	Content       string
+	SendTime      time.Time
+
+	outcome Outcome
+}
+
+// Status of m at the time
+func (m Message) Status(at time.Time) (SendStatus, string, time.Time) {
+	done := m.SendTime.Add(m.outcome.Delay)
+	if at.Before(done) {
+		return Waiting, "", time.Time{}
+	}
+	status, errCode := m.outcome.Status, m.outcome.ErrCode
+	if status == 0 {
+		status = Delivered
+	}
+	if status == Delivered && errCode == "" {
+		errCode = "DELIVRD"
+	}
+	return status, errCode, done
+}
+
+// Fake is an http.Handler of aliyun sms api
+// it's concurrent safe
+type Fake struct {
+	conf Config
+
+	mu       sync.Mutex
+	messages []Message
+	verifier *signer.Verifier
+	nonces   *signer.MemoryNonceStore
+	sent     map[string][]time.Time
+	seq      int64
+}
+
+// NewFake init a Fake
+func NewFake(conf Config) *Fake {
+	if conf.Keys == nil {
+		conf.Keys = map[string]string{DefaultAccessKeyID: DefaultAccessSecret}
+	}
+	if conf.MaxClockSkew <= 0 {
+		conf.MaxClockSkew = DefaultMaxClockSkew
+	}
+	if conf.FlowControl == nil {
+		fc := DefaultFlowControl
+		conf.FlowControl = &fc
+	}
+	if conf.Now == nil {
+		conf.Now = time.Now
+	}
+	nonces := signer.NewMemoryNonceStore()
+	nonces.Now = conf.Now
+	return &Fake{
+		conf:     conf,
+		verifier: &signer.Verifier{Secret: signer.Secrets(conf.Keys), MaxClockSkew: conf.MaxClockSkew, Nonces: nonces, Now: conf.Now},
+		nonces:   nonces,
+		sent:     make(map[string][]time.Time),
+	}
+}
+
+// Server is a Fake served by an httptest.Server
+type Server struct {
+	*httptest.Server
+	*Fake
+}
+
+// NewServer starts a Server of a Fake of conf, Close it after use
+func NewServer(conf Config) *Server {
+	f := NewFake(conf)
+	return &Server{Server: httptest.NewServer(f), Fake: f}
+}
+
+// Endpoint returns the endpoint of the Server for sms.Config
+func (s *Server) Endpoint() string {
+	return s.URL + "/"
+}
+
+// Messages returns all sent messages in order
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Message(nil), f.messages...)
+}
+
+// Reset deletes all messages, nonces and flow control counters
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = nil
+	f.nonces.Reset()
+	f.sent = make(map[string][]time.Time)
+}
+
+// apiError is a failed response
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func errorf(status int, code, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// response is the body of all actions, fields not of the action are omitted
+type response struct {
+	XMLName           xml.Name
+	RequestID         string      `json:"RequestId" xml:"RequestId"`
+	Code              string      `json:"Code" xml:"Code"`
+	Message           string      `json:"Message" xml:"Message"`
+	BizID             string      `json:"BizId,omitempty" xml:"BizId,omitempty"`
+	TotalCount        *int        `json:"TotalCount,omitempty" xml:"TotalCount,omitempty"`
+	SmsSendDetailDTOs *detailDTOs `json:"SmsSendDetailDTOs,omitempty" xml:"SmsSendDetailDTOs,omitempty"`
+}
+
+type detailDTOs struct {
+	SmsSendDetailDTO []detailDTO `json:"SmsSendDetailDTO" xml:"SmsSendDetailDTO"`
+}
+
+type detailDTO struct {
+	PhoneNum     string     `json:"PhoneNum" xml:"PhoneNum"`
+	SendStatus   SendStatus `json:"SendStatus" xml:"SendStatus"`
+	ErrCode      string     `json:"ErrCode" xml:"ErrCode"`
+	TemplateCode string     `json:"TemplateCode" xml:"TemplateCode"`
+	Content      string     `json:"Content" xml:"Content"`
+	SendDate     string     `json:"SendDate" xml:"SendDate"`
+	ReceiveDate  string     `json:"ReceiveDate" xml:"ReceiveDate"`
+	OutID        string     `json:"OutId" xml:"OutId"`
+}
+
+// ServeHTTP implements http.Handler
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params := r.Form
+	action := params.Get("Action")
+	requestID := f.requestID()
+
+	res := &response{XMLName: xml.Name{Local: action + "Response"}, RequestID: requestID, Code: "OK", Message: "OK"}
+	status := http.StatusOK
+	err := f.verify(r.Method, params)
+	if err == nil {
+		switch action {
+		case "SendSms":
+			err = f.sendSms(params, res)
+		case "SendBatchSms":
+			err = f.sendBatchSms(params, res)
+		case "QuerySendDetails":
+			err = f.querySendDetails(params, res)
+		default:
+			err = errorf(http.StatusBadRequest, "InvalidAction.NotFound", "Specified api is not found, please check your url and method.")
+		}
+	}
+	if err != nil {
+		res = &response{XMLName: xml.Name{Local: "Error"}, RequestID: requestID, Code: err.code, Message: err.message}
+		status = err.status
+	}
+
+	var body []byte
+	if params.Get("Format") == "XML" {
+		w.Header().Set("Content-Type", "text/xml;charset=utf-8")
+		body, _ = xml.Marshal(res)
+		body = append([]byte(xml.Header), body...)
+	} else {
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		body, _ = json.Marshal(res)
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (f *Fake) requestID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", f.seq)
+}
+
+// verify the signature, nonce and timestamp of the request
+func (f *Fake) verify(method string, params url.Values) *apiError {
+	if err := f.verifier.VerifyParams(method, params); err != nil {
+		if e, ok := err.(*signer.Error); ok {
+			return &apiError{status: e.StatusCode(), code: e.Code, message: e.Message}
+		}
+		return errorf(http.StatusInternalServerError, "InternalError", "%s", err.Error())
+	}
+	if params.Get("Action") == "" {
+		return errorf(http.StatusBadRequest, "MissingAction", "Action is mandatory for this action.")
+	}
+	return nil
+}
+
+var phoneNumberRegexp = regexp.MustCompile(`^(1[0-9]{10}|[2-9][0-9]{6,14})$`)
+
+// maxPhoneNumbers is upper limit of phone numbers of "SendSms" and "SendBatchSms"
+const maxPhoneNumbers = 1000
+
+// send is a message of the request before it's accepted
+type send struct {
+	phoneNumber   string
+	signName      string
+	templateParam map[string]string
+}
+
+func (f *Fake) sendSms(params url.Values, res *response) *apiError {
+	for _, name := range []string{"PhoneNumbers", "SignName", "TemplateCode"} {
+		if params.Get(name) == "" {
+			return errorf(http.StatusBadRequest, "Missing"+name, "%s is mandatory for this action.", name)
+		}
+	}
+	var templateParam map[string]string
+	if tp := params.Get("TemplateParam"); tp != "" {
+		if err := json.Unmarshal([]byte(tp), &templateParam); err != nil {
+			return errorf(http.StatusOK, "isv.INVALID_JSON_PARAM", "JSON参数不合法，只接受字符串值")
+		}
+	}
+
+	var sends []send
+	for _, n := range strings.Split(params.Get("PhoneNumbers"), ",") {
+		sends = append(sends, send{phoneNumber: n, signName: params.Get("SignName"), templateParam: templateParam})
+	}
+	return f.send(params, sends, res)
+}
+
+func (f *Fake) sendBatchSms(params url.Values, res *response) *apiError {
+	for _, name := range []string{"PhoneNumberJson", "SignNameJson", "TemplateCode"} {
+		if params.Get(name) == "" {
+			return errorf(http.StatusBadRequest, "Missing"+name, "%s is mandatory for this action.", name)
+		}
+	}
+	var numbers, signNames []string
+	var templateParams []map[string]string
+	if json.Unmarshal([]byte(params.Get("PhoneNumberJson")), &numbers) != nil ||
+		json.Unmarshal([]byte(params.Get("SignNameJson")), &signNames) != nil {
+		return errorf(http.StatusOK, "isv.INVALID_JSON_PARAM", "JSON参数不合法")
+	}
+	if tp := params.Get("TemplateParamJson"); tp != "" {
+		if err := json.Unmarshal([]byte(tp), &templateParams); err != nil {
+			return errorf(http.StatusOK, "isv.INVALID_JSON_PARAM", "JSON参数不合法，只接受字符串值")
+		}
+	}
+	if len(signNames) != len(numbers) || templateParams != nil && len(templateParams) != len(numbers) {
+		return errorf(http.StatusOK, "isv.INVALID_PARAMETERS", "参数异常: 号码、签名和模板参数数量不一致")
+	}
+
+	sends := make([]send, len(numbers))
+	for i, n := range numbers {
+		sends[i] = send{phoneNumber: n, signName: signNames[i]}
+		if templateParams != nil {
+			sends[i].templateParam = templateParams[i]
+		}
+	}
+	return f.send(params, sends, res)
+}
+
+func (f *Fake) send(params url.Values, sends []send, res *response) *apiError {
+	if len(sends) > maxPhoneNumbers {
+		return errorf(http.StatusOK, "isv.MOBILE_COUNT_OVER_LIMIT", "手机号码数量超过限制")
+	}
+	code := params.Get("TemplateCode")
+	content, ok := f.conf.Templates[code]
+	if f.conf.Templates != nil && !ok {
+		return errorf(http.StatusOK, "isv.SMS_TEMPLATE_ILLEGAL", "模板不合法(不存在或被拉黑)")
+	}
+	for _, s := range sends {
+		if !phoneNumberRegexp.MatchString(s.phoneNumber) {
+			return errorf(http.StatusOK, "isv.MOBILE_NUMBER_ILLEGAL", "非法手机号")
+		}
+		if !f.signNameAllowed(s.signName) {
+			return errorf(http.StatusOK, "isv.SMS_SIGNATURE_ILLEGAL", "签名不合法(不存在或被拉黑)")
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.conf.Now()
+	for _, s := range sends {
+		if msg := f.limit(s.phoneNumber+"/"+s.signName, now); msg != "" {
+			return errorf(http.StatusOK, "isv.BUSINESS_LIMIT_CONTROL", "%s", msg)
+		}
+	}
+
+	f.seq++
+	bizID := fmt.Sprintf("%d^0", 100000000000000000+f.seq)
+	for _, s := range sends {
+		key := s.phoneNumber + "/" + s.signName
+		f.sent[key] = append(f.sent[key], now)
+		m := Message{
+			BizID:         bizID,
+			RequestID:     res.RequestID,
+			AccessKeyID:   params.Get("AccessKeyId"),
+			PhoneNumber:   s.phoneNumber,
+			SignName:      s.signName,
+			TemplateCode:  code,
+			TemplateParam: s.templateParam,
+			OutID:         params.Get("OutId"),
+			Content:       render(s.signName, content, code, s.templateParam),
+			SendTime:      now,
+		}
+		if f.conf.Delivery != nil {
+			m.outcome = f.conf.Delivery(m)
+		}
+		f.messages = append(f.messages, m)
+	}
+	res.BizID = bizID
+	return nil
+}
+
+func (f *Fake) signNameAllowed(signName string) bool {
+	if len(f.conf.SignNames) == 0 {
+		return true
+	}
+	for _, s := range f.conf.SignNames {
+		if s == signName {
+			return true
+		}
+	}
+	return false
+}
+
+// limit returns the message of the flow control a message to key exceeds at now,
+// "" if it's allowed
+func (f *Fake) limit(key string, now time.Time) string {
+	fc := f.conf.FlowControl
+	var minute, hour, day int
+	var kept []time.Time
+	for _, t := range f.sent[key] {
+		d := now.Sub(t)
+		if d >= 24*time.Hour {
+			continue
+		}
+		kept = append(kept, t)
+		day++
+		if d < time.Hour {
+			hour++
+		}
+		if d < time.Minute {
+			minute++
+		}
+	}
+	f.sent[key] = kept
+	switch {
+	case fc.PerMinute > 0 && minute >= fc.PerMinute:
+		return "触发分钟级流控Permits:" + strconv.Itoa(fc.PerMinute)
+	case fc.PerHour > 0 && hour >= fc.PerHour:
+		return "触发小时级流控Permits:" + strconv.Itoa(fc.PerHour)
+	case fc.PerDay > 0 && day >= fc.PerDay:
+		return "触发天级流控Permits:" + strconv.Itoa(fc.PerDay)
+	}
+	return ""
+}
+
+// render the content of a message, template code and params are used if content is ""
+func render(signName, content, code string, params map[string]string) string {
+	if content == "" {
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := []string{code}
+		for _, k := range keys {
+			parts = append(parts, k+"="+params[k])
+		}
+		content = strings.Join(parts, " ")
+	}
+	for k, v := range params {
+		content = strings.Replace(content, "${"+k+"}", v, -1)
+	}
+	return "【" + signName + "】" + content
+}
+
+// China Standard Time, SendDate of "QuerySendDetails" is in it
+var cst = time.FixedZone("CST", 8*60*60)
+
+const detailTimeLayout = "2006-01-02 15:04:05"
+
+func (f *Fake) querySendDetails(params url.Values, res *response) *apiError {
+	for _, name := range []string{"PhoneNumber", "SendDate", "PageSize", "CurrentPage"} {
+		if params.Get(name) == "" {
+			return errorf(http.StatusBadRequest, "Missing"+name, "%s is mandatory for this action.", name)
+		}
+	}
+	date, err := time.ParseInLocation("20060102", params.Get("SendDate"), cst)
+	if err != nil {
+		return errorf(http.StatusOK, "isv.INVALID_PARAMETERS", "参数异常: SendDate")
+	}
+	pageSize, err := strconv.Atoi(params.Get("PageSize"))
+	if err != nil || pageSize < 1 || pageSize > 50 {
+		return errorf(http.StatusOK, "isv.INVALID_PARAMETERS", "参数异常: PageSize")
+	}
+	page, err := strconv.Atoi(params.Get("CurrentPage"))
+	if err != nil || page < 1 {
+		return errorf(http.StatusOK, "isv.INVALID_PARAMETERS", "参数异常: CurrentPage")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.conf.Now()
+	var details []detailDTO
+	for _, m := range f.messages {
+		if m.PhoneNumber != params.Get("PhoneNumber") || m.AccessKeyID != params.Get("AccessKeyId") {
+			continue
+		}
+		if bizID := params.Get("BizId"); bizID != "" && m.BizID != bizID {
+			continue
+		}
+		if y, mo, d := m.SendTime.In(cst).Date(); y != date.Year() || mo != date.Month() || d != date.Day() {
+			continue
+		}
+		status, errCode, receiveTime := m.Status(now)
+		dto := detailDTO{
+			PhoneNum:     m.PhoneNumber,
+			SendStatus:   status,
+			ErrCode:      errCode,
+			TemplateCode: m.TemplateCode,
+			Content:      m.Content,
+			SendDate:     m.SendTime.In(cst).Format(detailTimeLayout),
+			OutID:        m.OutID,
+		}
+		if !receiveTime.IsZero() {
+			dto.ReceiveDate = receiveTime.In(cst).Format(detailTimeLayout)
+		}
+		details = append(details, dto)
+	}
+
+	total := len(details)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	res.TotalCount = &total
+	res.SmsSendDetailDTOs = &detailDTOs{SmsSendDetailDTO: append([]detailDTO{}, details[start:end]...)}
+	return nil
+}
@@ -0,0 +1,163 @@
+package smstest_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/scistack/aliyun-sms-go/signer"
+	"github.com/scistack/aliyun-sms-go/sms"
+	"github.com/scistack/aliyun-sms-go/smstest"
+)
+
+var params = sms.SendSmsParams{
+	PhoneNumbers:  "15300000001,15300000002",
+	SignName:      "阿里云短信测试专用",
+	TemplateCode:  "SMS_71390007",
+	TemplateParam: sms.TemplateParam{"code": "1234"},
+	OutID:         "1",
+}
+
+// clock is a Now of Config can be moved forward
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestServer_SendSms(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	srv := smstest.NewServer(smstest.Config{
+		Templates: map[string]string{"SMS_71390007": "您的验证码为${code}"},
+		Delivery: func(m smstest.Message) smstest.Outcome {
+			if m.PhoneNumber == "15300000002" {
+				return smstest.Outcome{Status: smstest.Failed, ErrCode: "MOBILE_NOT_ON_SERVICE", Delay: time.Second}
+			}
+			return smstest.Outcome{Delay: time.Second}
+		},
+		Now: clk.Now,
+	})
+	defer srv.Close()
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+
+	for _, format := range []sms.FormatType{sms.JSON, sms.XML} {
+		srv.Reset()
+		opts, err := sms.NewSendAction(c, params).Do(format, sms.Timestamp(clk.Now()))
+		if err != nil {
+			t.Fatalf("Do err: %v", err)
+		}
+		res := opts.Response()
+		if res.Err() != nil || res.BizID == "" {
+			t.Fatalf("Response(%s): %+v", format, res)
+		}
+		messages := srv.Messages()
+		if len(messages) != 2 || messages[0].Content != "【阿里云短信测试专用】您的验证码为1234" || messages[1].BizID != res.BizID {
+			t.Errorf("Messages: %+v", messages)
+		}
+
+		query := sms.QuerySendDetailsParams{PhoneNumber: "15300000002", BizID: res.BizID, SendDate: sms.Date(clk.Now()), PageSize: 10, CurrentPage: 1}
+		q, err := sms.NewQuerySendDetailsAction(c, query).Do(format, sms.Timestamp(clk.Now()))
+		if err != nil {
+			t.Fatalf("Do err: %v", err)
+		}
+		details := q.Response().SmsSendDetailDTOs.SmsSendDetailDTO
+		if q.Response().TotalCount != 1 || len(details) != 1 || details[0].SendStatus != sms.SendStatusWaiting || details[0].OutID != "1" {
+			t.Errorf("Response(%s): %+v", format, q.Response())
+		}
+
+		clk.Add(time.Second)
+		q, _ = sms.NewQuerySendDetailsAction(c, query).Do(format, sms.Timestamp(clk.Now()))
+		details = q.Response().SmsSendDetailDTOs.SmsSendDetailDTO
+		if len(details) != 1 || details[0].SendStatus != sms.SendStatusFailed || details[0].ErrCode != "MOBILE_NOT_ON_SERVICE" {
+			t.Errorf("Response(%s): %+v", format, q.Response())
+		}
+
+		query.PhoneNumber = "15300000001"
+		q, _ = sms.NewQuerySendDetailsAction(c, query).Do(format, sms.Timestamp(clk.Now()))
+		details = q.Response().SmsSendDetailDTOs.SmsSendDetailDTO
+		if len(details) != 1 || details[0].SendStatus != sms.SendStatusDelivered || details[0].ErrCodeFamily() != sms.ErrCodeDelivered {
+			t.Errorf("Response(%s): %+v", format, q.Response())
+		}
+	}
+}
+
+func TestServer_Verify(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+	c := sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: smstest.DefaultAccessSecret, Endpoint: srv.Endpoint()})
+	u4, _ := uuid.NewV4()
+
+	cases := []struct {
+		c       sms.Client
+		extOpts []sms.Option
+		code    string
+	}{
+		{sms.NewClient(sms.Config{AccessKeyID: "unknown", AccessSecret: "testSecret", Endpoint: srv.Endpoint()}), nil, "InvalidAccessKeyId.NotFound"},
+		{sms.NewClient(sms.Config{AccessKeyID: smstest.DefaultAccessKeyID, AccessSecret: "wrong", Endpoint: srv.Endpoint()}), nil, "SignatureDoesNotMatch"},
+		{c, []sms.Option{sms.Timestamp(time.Now().Add(-time.Hour))}, "InvalidTimeStamp.Expired"},
+		{c, []sms.Option{sms.SignatureNonce(u4)}, "OK"},
+		{c, []sms.Option{sms.SignatureNonce(u4)}, "SignatureNonceUsed"},
+		{c, nil, "isv.BUSINESS_LIMIT_CONTROL"},
+	}
+	for _, cs := range cases {
+		opts, err := sms.NewSendAction(cs.c, params).Do(cs.extOpts...)
+		if err != nil {
+			t.Fatalf("Do err: %v", err)
+		}
+		if code := opts.Response().Code; code != cs.code {
+			t.Errorf("Code: %s != %s", code, cs.code)
+		}
+	}
+}
+
+func TestServer_SendBatchSms(t *testing.T) {
+	srv := smstest.NewServer(smstest.Config{})
+	defer srv.Close()
+
+	values := url.Values{
+		"AccessKeyId":       {smstest.DefaultAccessKeyID},
+		"Action":            {"SendBatchSms"},
+		"Format":            {"JSON"},
+		"SignatureMethod":   {"HMAC-SHA1"},
+		"SignatureNonce":    {"1"},
+		"SignatureVersion":  {"1.0"},
+		"Timestamp":         {time.Now().UTC().Format(time.RFC3339)},
+		"PhoneNumberJson":   {`["15300000001","15300000002"]`},
+		"SignNameJson":      {`["阿里云短信测试专用","阿里云短信测试专用"]`},
+		"TemplateCode":      {"SMS_71390007"},
+		"TemplateParamJson": {`[{"code":"1"},{"code":"2"}]`},
+	}
+	values.Set("Signature", signer.Sign("GET", values, smstest.DefaultAccessSecret))
+
+	resp, err := http.Get(srv.Endpoint() + "?" + values.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	var res sms.SendSmsResponse
+	if err := json.Unmarshal(body, &res); err != nil || res.Code != sms.CodeOK {
+		t.Fatalf("response: %s", body)
+	}
+
+	messages := srv.Messages()
+	if len(messages) != 2 || messages[1].TemplateParam["code"] != "2" || messages[1].BizID != res.BizID {
+		t.Errorf("Messages: %+v", messages)
+	}
+}